@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// changelog compares two schema files and writes the changes between them
+// as JSON and Markdown, for release notes. Without -json nor -markdown, the
+// Markdown is printed.
+func changelog(args []string) int {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	asJSON := fs.String("json", "", "write the changelog as JSON to this file")
+	markdown := fs.String("markdown", "", "write the changelog as Markdown to this file")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "changelog requires the old and the new schema files")
+		return 2
+	}
+	from, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	to, err := ioutil.ReadFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	c, err := schemagen.CompareSchemas(from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+
+	if len(*asJSON) > 0 {
+		b, _ := json.MarshalIndent(c, "", "  ")
+		if err := writeChanged(*asJSON, append(b, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+	if len(*markdown) > 0 || len(*asJSON) == 0 {
+		var b bytes.Buffer
+		c.WriteMarkdown(&b)
+		if len(*markdown) == 0 {
+			os.Stdout.Write(b.Bytes())
+		} else if err := writeChanged(*markdown, b.Bytes()); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+	return 0
+}
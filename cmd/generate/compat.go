@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// compat compares two schema files and lists the changes between them,
+// flagging those breaking clients of the old schema. It exits with 1 if
+// there are breaking changes, so that it can gate upstream syncs.
+func compat(args []string) int {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the changes as JSON")
+	breakingOnly := fs.Bool("breaking", false, "only list the breaking changes")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "compat requires the old and the new schema files")
+		return 2
+	}
+	from, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	to, err := ioutil.ReadFile(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	c, err := schemagen.DiffSchemas(from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+
+	breaking := c.Breaking()
+	changes := c.Changes
+	if *breakingOnly {
+		changes = breaking
+	}
+	if *asJSON {
+		if changes == nil {
+			changes = []schemagen.Change{}
+		}
+		b, _ := json.MarshalIndent(changes, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		for _, change := range changes {
+			name := change.Definition
+			if len(change.Property) > 0 {
+				name += "." + change.Property
+			}
+			fmt.Printf("%s %s", change.Kind, name)
+			if len(change.Breaking) > 0 {
+				fmt.Printf(" (breaking: %s)", change.Breaking)
+			}
+			fmt.Println()
+		}
+	}
+	if len(breaking) > 0 {
+		if !*asJSON {
+			fmt.Fprintf(os.Stderr, "%d breaking changes.\n", len(breaking))
+		}
+		return 1
+	}
+	return 0
+}
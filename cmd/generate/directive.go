@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// generateDirective renders the schema of the type named by the
+// go:generate directive at $GOLINE of the given file. Types are resolved
+// among the types reachable from Schema, the only ones compiled into the
+// generator.
+func generateDirective(cfg *schemagen.Config, opts schemagen.Options, file, typeName string) (map[string]string, error) {
+	line, err := strconv.Atoi(os.Getenv("GOLINE"))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid GOLINE %q.", os.Getenv("GOLINE"))
+	}
+	d, err := schemagen.ParseDirective(file, line, typeName)
+	if err != nil {
+		return nil, err
+	}
+	var types []reflect.Type
+	for _, info := range schemagen.ListReachableTypes(reflect.TypeOf(Schema{}), opts) {
+		types = append(types, info.Type)
+	}
+	opts.Resolver = schemagen.TypeList(types...)
+	schema, err := schemagen.GenerateDirectiveSchema(d, opts)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{cfg.Output: render(schema)}, nil
+}
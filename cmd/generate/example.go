@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/v1/yaml"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// example prints a ready-to-edit sample document of an API type, or a
+// random valid instance of it, as JSON or YAML, built from its definition
+// in the generated schema.
+func example(args []string) int {
+	fs := flag.NewFlagSet("example", flag.ExitOnError)
+	config := fs.String("config", "", "read the generation configuration from this YAML file")
+	typeName := fs.String("type", "", "the type to build a sample of, e.g. Route, or its definition name")
+	asYAML := fs.Bool("yaml", false, "print the sample as YAML instead of JSON")
+	random := fs.Bool("random", false, "print a random valid instance instead of a sample filling in every property")
+	seed := fs.Int64("seed", 1, "the seed of the random instance")
+	fs.Parse(args)
+
+	if len(*typeName) == 0 {
+		fmt.Fprintln(os.Stderr, "example requires -type")
+		return 2
+	}
+	cfg := &schemagen.Config{}
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+	opts, err := options(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	names := schemagen.FindDefinitions(schema, *typeName)
+	if len(names) != 1 {
+		if len(names) == 0 {
+			fmt.Fprintf(os.Stderr, "No definition of type %s.\n", *typeName)
+		} else {
+			fmt.Fprintf(os.Stderr, "Type %s is ambiguous, pass one of: %s.\n", *typeName, strings.Join(names, ", "))
+		}
+		return 2
+	}
+	var sample interface{}
+	if *random {
+		sample, err = schemagen.GenerateRandomInstance(schema, names[0], *seed)
+	} else {
+		sample, err = schemagen.Example(schema, names[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+
+	var b []byte
+	if *asYAML {
+		b, err = yaml.Marshal(sample)
+	} else {
+		b, err = json.MarshalIndent(sample, "", "  ")
+		b = append(b, '\n')
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	os.Stdout.Write(b)
+	return 0
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -42,20 +48,430 @@ type Schema struct {
 	Template                  templateapi.Template
 }
 
+// init registers the types of the schema by field name, so their schemas
+// can also be generated alone.
+func init() {
+	t := reflect.TypeOf(Schema{})
+	for i := 0; i < t.NumField(); i++ {
+		schemagen.Register(t.Field(i).Name, t.Field(i).Type)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "verify":
+			os.Exit(verify(os.Args[2:]))
+		case "lint":
+			os.Exit(lint(os.Args[2:]))
+		case "example":
+			os.Exit(example(os.Args[2:]))
+		case "watch":
+			os.Exit(watch(os.Args[2:]))
+		case "changelog":
+			os.Exit(changelog(os.Args[2:]))
+		case "compat":
+			os.Exit(compat(os.Args[2:]))
+		case "gotypes":
+			os.Exit(gotypes(os.Args[2:]))
+		}
+	}
+
+	config := flag.String("config", "", "read the generation configuration from this YAML file")
+	output := flag.String("output", "", "write the schema to this file instead of stdout")
+	flag.StringVar(output, "out", "", "shorthand for -output")
+	format := flag.String("format", "", "write the schema, CRD schema or OpenAPI document as json (default) or yaml, keeping the order of the keys")
+	typeName := flag.String("type", "", "under go generate, the type to generate the schema of, by default the one following the directive")
+	debug := flag.Bool("debug", false, "annotate each definition with the field chain that first reached it")
+	enumConstants := flag.Bool("enum-constants", false, "describe named string types as enums of the values of their exported constants")
+	tagKeys := flag.String("tag-keys", "", "the comma-separated struct tags naming fields, looked up in order, e.g. yaml,json (default json)")
+	labelFilter := flag.String("labels", "", "only emit the labelled definitions carrying one of these comma-separated labels, e.g. stable")
+	internTypes := flag.Bool("intern-types", false, "share a single definition between the vendored copies of a type")
+	disambiguateNames := flag.Bool("disambiguate-names", false, "name types colliding with another one after their package path")
+	byteArrays := flag.Bool("byte-arrays-as-strings", false, "emit fixed-size byte arrays such as [16]byte as strings instead of arrays of integers")
+	formatHeuristics := flag.Bool("format-heuristics", false, "give string properties the uri, hostname, ipv4 or email format their name suggests, reporting the guesses on stderr")
+	protoFields := flag.Bool("proto-fields", false, "annotate properties with the field number and name of their protobuf struct tag as x-proto-field-number and x-proto-name")
+	goPackages := flag.Bool("go-packages", false, "annotate each definition with the import path of the Go package declaring its type as x-go-package")
+	split := flag.String("split", "", "write one schema per API group/version plus index.json into this directory")
+	titles := flag.String("titles", "", "title properties and definitions after their Go names: asIs, sentence or words")
+	nullable := flag.String("nullable", "", "mark optional fields nullable: swagger2, openapi3, both, oneOf or typeArray")
+	progress := flag.Bool("progress", false, "report generation progress on stderr")
+	list := flag.Bool("list", false, "list the types that would be generated instead of generating the schema")
+	wrapRefs := flag.Bool("wrap-refs", false, "wrap $ref with sibling keywords in allOf for strict draft-04 tooling")
+	standard := flag.Bool("standard", false, "omit javaType and vendor extensions, producing pure JSON Schema")
+	javaTypeKeyword := flag.String("java-type-keyword", "", "emit the javaType hints under this keyword instead, e.g. x-go-type, or omit them with -")
+	nest := flag.Bool("nest", false, "nest definitions used by a single parent under that parent")
+	inlineRefs := flag.Bool("inline-refs", false, "expand every $ref in place, leaving no definitions, for consumers unable to resolve references")
+	contract := flag.String("contract", "", "write Java round-trip contract fixtures and their manifest into this directory instead of the schema")
+	shared := flag.String("shared", "", "regenerate only the root type given by -root, merging its definitions into this shared definitions file")
+	root := flag.String("root", "", "generate only the schema of this registered type, e.g. Template")
+	baseURI := flag.String("base-uri", "", "prefix the id of the schema with this URI instead of "+schemagen.DefaultBaseURI)
+	license := flag.String("license", "", "attribution emitted as the x-license extension of the schema")
+	draft := flag.String("draft", "", "serialize the schema in this JSON Schema draft: draft-04 (default), draft-07 or 2020-12")
+	draft2020 := flag.String("draft2020", "", "also write the schema in JSON Schema draft 2020-12 to this file")
+	javaImports := flag.String("java-imports", "", "also write the Java classes referenced by each definition to this file")
+	contentNames := flag.Bool("content-names", false, "name definitions after a hash of their content to deduplicate them across schemas")
+	catchAll := flag.Bool("catch-all-maps", false, "describe maps tagged json:\"-\" or json:\",inline\" as the additionalProperties of their struct")
+	compose := flag.Bool("compose-embedded", false, "describe structs embedding other structs as allOf their definitions instead of copying their properties")
+	docComments := flag.Bool("doc-comments", false, "describe definitions and properties with the doc comments of their Go types and fields")
+	required := flag.Bool("required", false, "list the fields that are neither pointers nor tagged omitempty in the required keyword of their object")
+	nonEmpty := flag.Bool("non-empty-required", false, "add minLength: 1 to required string fields so that empty strings fail validation")
+	patternProperties := flag.Bool("pattern-properties", false, "restrict map keys through patternProperties, which draft-04 validators understand, instead of propertyNames")
+	stamped := flag.Bool("stamped", false, "record the generation time, host and Go version in the schema; leave unset for reproducible output")
+	audit := flag.Bool("audit", false, "generate the schema twice and fail if the outputs differ, to detect nondeterminism")
+	strict := flag.Bool("strict", false, "fail on problems such as fields sharing a JSON name instead of warning")
+	usages := flag.String("usages", "", "also write the JSON pointers referring to each definition to this file")
+	uiSchema := flag.String("ui-schema", "", "also write the JSON Forms UI schema of each definition to this file")
+	proto := flag.String("proto", "", "also write proto3 messages of the root and of each definition to this file, keeping their field numbers in a .numbers.json file next to it")
+	protoPackage := flag.String("proto-package", "", "the package of the -proto file")
+	typeScript := flag.String("typescript", "", "also write TypeScript declarations of the root and of each definition to this file")
+	docs := flag.String("docs", "", "also write the reference documentation of the root and of each definition to this file, in HTML for .html files and in Markdown otherwise")
+	defaultProperties := flag.String("default-properties", "", "whether objects without a policy of their own accept undeclared properties: open (default), closed or unevaluated")
+	rootProperties := flag.String("root-properties", "", "whether the root accepts undeclared properties: open (default), closed with additionalProperties or unevaluated with unevaluatedProperties")
+	components := flag.String("components", "", "also mirror the definitions into components.schemas, references pointing at the definitions or the components: definitions or components")
+	propertyNaming := flag.String("property-naming", "", "name the properties of fields without a name in their json tag after their Go name (default) or convert it: go, camelCase, snake_case or kebab-case")
+	naming := flag.String("naming", "", "name the definitions of types of undescribed packages after their full package path (default), their last two segments or their type alone: path, lastSegments or short")
+	anyItems := flag.String("any-items", "", "describe the items of slices of empty interfaces as the empty schema (default) or, from draft-07, the boolean schema true: {} or true")
+	retention := flag.String("retention", "", "keep all definitions (default) or only the ones reachable from the root, reporting dropped ones: all or reachable")
+	profile := flag.String("profile", "", "tailor the schema to its consumer: java (default) or helm for a chart values.schema.json, or produce the outputs of these comma-separated profiles of the configuration")
+	emit := flag.String("emit", "", "render the custom emitter with this name, registered in the configuration, instead of the schema")
+	openAPI := flag.String("openapi", "", "write an OpenAPI document of this version, 3.0 or 2.0, holding the schemas instead of the schema")
+	crd := flag.Bool("crd", false, "write the structural schema of the root, without references, to embed as the openAPIV3Schema of a CustomResourceDefinition, instead of the schema")
+	report := flag.Bool("report", false, "print a size report of the schema instead of the schema")
+	updateLock := flag.Bool("update-freeze-lock", false, "record the frozen definitions of the configuration in its freeze lock file instead of writing the schema")
+	provenanceLock := flag.String("provenance-lock", "", "record the generator version, the options and the hashes of the input types in this lock file")
+	frozen := flag.Bool("frozen", false, "refuse to generate if the inputs differ from the ones recorded in the provenance lock file, instead of updating it")
+	flag.Parse()
+
+	cfg := &schemagen.Config{}
+	var profiles []string
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fail(err)
+		}
+		if profiles, err = configProfiles(cfg, *profile); err != nil {
+			fail(err)
+		}
+		switch len(profiles) {
+		case 0:
+		case 1:
+			if cfg, err = schemagen.LoadConfigProfile(*config, profiles[0]); err != nil {
+				fail(err)
+			}
+		default:
+			os.Exit(runProfiles(profiles))
+		}
+	}
+	// Flags given explicitly take precedence over the configuration file.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "output", "out":
+			cfg.Output = *output
+		case "format":
+			cfg.Format = *format
+		case "debug":
+			cfg.Debug = *debug
+		case "go-packages":
+			cfg.GoPackages = *goPackages
+		case "enum-constants":
+			cfg.EnumConstants = *enumConstants
+		case "labels":
+			cfg.LabelFilter = strings.Split(*labelFilter, ",")
+		case "tag-keys":
+			cfg.TagKeys = strings.Split(*tagKeys, ",")
+		case "intern-types":
+			cfg.InternTypes = *internTypes
+		case "disambiguate-names":
+			cfg.DisambiguateNames = *disambiguateNames
+		case "byte-arrays-as-strings":
+			cfg.ByteArraysAsStrings = *byteArrays
+		case "proto-fields":
+			cfg.ProtoFields = *protoFields
+		case "format-heuristics":
+			cfg.FormatHeuristics = *formatHeuristics
+		case "split":
+			cfg.Split = *split
+		case "nullable":
+			cfg.Nullable = *nullable
+		case "titles":
+			cfg.Titles = *titles
+		case "wrap-refs":
+			cfg.WrapRefs = *wrapRefs
+		case "standard":
+			cfg.StandardOnly = *standard
+		case "java-type-keyword":
+			cfg.JavaTypeKeyword = *javaTypeKeyword
+		case "nest":
+			cfg.NestDefinitions = *nest
+		case "inline-refs":
+			cfg.InlineReferences = *inlineRefs
+		case "content-names":
+			cfg.ContentAddressed = *contentNames
+		case "catch-all-maps":
+			cfg.CatchAllMaps = *catchAll
+		case "compose-embedded":
+			cfg.ComposeEmbedded = *compose
+		case "doc-comments":
+			cfg.DocComments = *docComments
+		case "required":
+			cfg.Required = *required
+		case "non-empty-required":
+			cfg.NonEmptyRequiredStrings = *nonEmpty
+		case "pattern-properties":
+			cfg.PatternProperties = *patternProperties
+		case "stamped":
+			cfg.Stamped = *stamped
+		case "strict":
+			cfg.Strict = *strict
+		case "audit":
+			cfg.Audit = *audit
+		case "profile":
+			if len(profiles) == 0 {
+				cfg.Profile = *profile
+			}
+		case "crd":
+			cfg.CRD = *crd
+		case "openapi":
+			cfg.OpenAPI = *openAPI
+		case "root-properties":
+			cfg.RootProperties = *rootProperties
+		case "default-properties":
+			cfg.DefaultProperties = *defaultProperties
+		case "components":
+			cfg.Components = *components
+		case "retention":
+			cfg.Retention = *retention
+		case "naming":
+			cfg.Naming = *naming
+		case "property-naming":
+			cfg.PropertyNaming = *propertyNaming
+		case "any-items":
+			cfg.AnyItems = *anyItems
+		case "license":
+			cfg.License = *license
+		case "base-uri":
+			cfg.BaseURI = *baseURI
+		case "draft":
+			cfg.Draft = *draft
+		case "draft2020":
+			cfg.Draft2020 = *draft2020
+		case "java-imports":
+			cfg.JavaImports = *javaImports
+		case "usages":
+			cfg.Usages = *usages
+		case "ui-schema":
+			cfg.UISchema = *uiSchema
+		case "typescript":
+			cfg.TypeScript = *typeScript
+		case "docs":
+			cfg.Docs = *docs
+		case "proto":
+			cfg.Proto = *proto
+		case "proto-package":
+			cfg.ProtoPackage = *protoPackage
+		case "provenance-lock":
+			cfg.ProvenanceLock = *provenanceLock
+		}
+	})
+
+	opts, err := options(cfg)
+	if err != nil {
+		fail(err)
+	}
+	if *frozen && len(cfg.ProvenanceLock) == 0 {
+		fail(fmt.Errorf("-frozen requires a provenance lock file."))
+	}
+	if len(cfg.ProvenanceLock) > 0 {
+		if err := checkProvenance(cfg, *frozen); err != nil {
+			fail(err)
+		}
+	}
+	if opts.Retention == schemagen.RetainReachable {
+		opts.RetentionReport = func(name string, reachable, kept bool) {
+			if !kept {
+				fmt.Fprintf(os.Stderr, "Dropped unreachable definition %s\n", name)
+			}
+		}
+	}
+	if *progress {
+		opts.Progress = func(done, total int, current string) {
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", done, total, current)
+		}
+	}
+	if *list {
+		for _, info := range schemagen.ListReachableTypes(reflect.TypeOf(Schema{}), opts) {
+			if info.Abstract {
+				continue
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\n", info.Name, info.Package, info.Type.Name(), info.JavaType)
+		}
+		return
+	}
+	if *report {
+		schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+		if err != nil {
+			fail(err)
+		}
+		if err := (schemagen.ReportEmitter{}).Emit(schema, os.Stdout); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if *updateLock {
+		if len(cfg.FreezeLock) == 0 {
+			fail(fmt.Errorf("-update-freeze-lock requires freezeLock in the configuration."))
+		}
+		opts.Frozen = nil
+		schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+		if err != nil {
+			fail(err)
+		}
+		lock, err := schemagen.NewFreezeLock(schema, cfg.Frozen)
+		if err != nil {
+			fail(err)
+		}
+		b, _ := json.MarshalIndent(lock, "", "  ")
+		if err := writeChanged(cfg.FreezeLock, append(b, '\n')); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if cfg.CRD {
+		schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+		if err != nil {
+			fail(err)
+		}
+		content, err := renderOutput(cfg, schemagen.StructuralSchema(schema))
+		if err != nil {
+			fail(err)
+		}
+		if len(cfg.Output) == 0 {
+			fmt.Println(content)
+		} else if err := writeChanged(cfg.Output, []byte(content+"\n")); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if len(cfg.OpenAPI) > 0 {
+		version, err := schemagen.ParseOpenAPIVersion(cfg.OpenAPI)
+		if err != nil {
+			fail(err)
+		}
+		doc, err := schemagen.GenerateOpenAPI(reflect.TypeOf(Schema{}), opts, version)
+		if err != nil {
+			fail(err)
+		}
+		content, err := renderOutput(cfg, doc)
+		if err != nil {
+			fail(err)
+		}
+		if len(cfg.Output) == 0 {
+			fmt.Println(content)
+		} else if err := writeChanged(cfg.Output, []byte(content+"\n")); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	if len(*emit) > 0 {
+		emitter, ok := opts.Emitters[*emit]
+		if !ok {
+			fail(fmt.Errorf("Unknown emitter %s.", *emit))
+		}
+		w := os.Stdout
+		if len(cfg.Output) > 0 {
+			f, err := os.Create(cfg.Output)
+			if err != nil {
+				fail(err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := schemagen.Emit(reflect.TypeOf(Schema{}), opts, schemagen.Output{Emitter: emitter, Writer: w}); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if len(*shared) > 0 {
+		if len(*root) == 0 {
+			fail(fmt.Errorf("-shared requires -root."))
+		}
+		if err := generateShared(cfg, opts, *shared, *root); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if len(*contract) > 0 {
+		schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+		if err != nil {
+			fail(err)
+		}
+		fixtures, err := schemagen.ContractFixtures(schema)
+		if err != nil {
+			fail(err)
+		}
+		if err := os.MkdirAll(*contract, 0755); err != nil {
+			fail(err)
+		}
+		for name, content := range fixtures {
+			if err := ioutil.WriteFile(filepath.Join(*contract, name), append(content, '\n'), 0644); err != nil {
+				fail(err)
+			}
+		}
+		return
+	}
+
+	var files map[string]string
+	if file := os.Getenv("GOFILE"); len(file) > 0 {
+		files, err = generateDirective(cfg, opts, file, *typeName)
+	} else if len(*root) > 0 {
+		files, err = generateRegistered(cfg, opts, *root)
+	} else {
+		files, err = generate(cfg, opts)
+	}
+	if err != nil {
+		fail(err)
+	}
+	for name, content := range files {
+		if len(name) == 0 {
+			fmt.Println(content)
+			continue
+		}
+		if err := writeChanged(name, []byte(content+"\n")); err != nil {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	if e, ok := err.(*schemagen.GenerationError); ok {
+		fmt.Fprintf(os.Stderr, "%d problems occurred:\n", len(e.Problems))
+		for _, p := range e.Problems {
+			fmt.Fprintf(os.Stderr, "  %s\n", p.Message)
+		}
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+	os.Exit(1)
+}
+
+// options returns the generation options for the OpenShift and Kubernetes
+// API types, adjusted by the configuration.
+func options(cfg *schemagen.Config) (schemagen.Options, error) {
 	packages := []schemagen.PackageDescriptor{
-		{"github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta2", "io.fabric8.kubernetes.api.model", "kubernetes_"},
-		{"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime", "io.fabric8.kubernetes.api.model", "kubernetes_runtime_"},
-		{"github.com/GoogleCloudPlatform/kubernetes/pkg/api", "io.fabric8.kubernetes.api.model", "kubernetes_"},
-		{"github.com/GoogleCloudPlatform/kubernetes/pkg/util", "io.fabric8.kubernetes.api.model", "kubernetes_util_"},
-		{"github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors", "io.fabric8.kubernetes.api.model", "kubernetes_"},
-		{"github.com/fsouza/go-dockerclient", "io.fabric8.docker.api.model", "docker_"},
-		{"github.com/openshift/origin/pkg/build/api", "io.fabric8.openshift.api.model", "os_build_"},
-		{"github.com/openshift/origin/pkg/deploy/api", "io.fabric8.openshift.api.model", "os_deploy_"},
-		{"github.com/openshift/origin/pkg/image/api", "io.fabric8.openshift.api.model", "os_image_"},
-		{"github.com/openshift/origin/pkg/route/api", "io.fabric8.openshift.api.model", "os_route_"},
-		{"github.com/openshift/origin/pkg/config/api", "io.fabric8.openshift.api.model", "os_config_"},
-		{"github.com/openshift/origin/pkg/template/api", "io.fabric8.openshift.api.model", "os_template_"},
+		{GoPackage: "github.com/GoogleCloudPlatform/kubernetes/pkg/api/v1beta2", JavaPackage: "io.fabric8.kubernetes.api.model", Prefix: "kubernetes_", Version: "v1beta2"},
+		{GoPackage: "github.com/GoogleCloudPlatform/kubernetes/pkg/runtime", JavaPackage: "io.fabric8.kubernetes.api.model", Prefix: "kubernetes_runtime_"},
+		{GoPackage: "github.com/GoogleCloudPlatform/kubernetes/pkg/api", JavaPackage: "io.fabric8.kubernetes.api.model", Prefix: "kubernetes_"},
+		{GoPackage: "github.com/GoogleCloudPlatform/kubernetes/pkg/util", JavaPackage: "io.fabric8.kubernetes.api.model", Prefix: "kubernetes_util_"},
+		{GoPackage: "github.com/GoogleCloudPlatform/kubernetes/pkg/api/errors", JavaPackage: "io.fabric8.kubernetes.api.model", Prefix: "kubernetes_"},
+		{GoPackage: "github.com/fsouza/go-dockerclient", JavaPackage: "io.fabric8.docker.api.model", Prefix: "docker_"},
+		{GoPackage: "github.com/openshift/origin/pkg/build/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_build_", Group: "build", Version: "v1beta1"},
+		{GoPackage: "github.com/openshift/origin/pkg/deploy/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_deploy_", Group: "deploy", Version: "v1beta1"},
+		{GoPackage: "github.com/openshift/origin/pkg/image/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_image_", Group: "image", Version: "v1beta1"},
+		{GoPackage: "github.com/openshift/origin/pkg/route/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_route_", Group: "route", Version: "v1beta1"},
+		{GoPackage: "github.com/openshift/origin/pkg/config/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_config_", Group: "config", Version: "v1beta1"},
+		{GoPackage: "github.com/openshift/origin/pkg/template/api", JavaPackage: "io.fabric8.openshift.api.model", Prefix: "os_template_", Group: "template", Version: "v1beta1"},
 	}
 
 	typeMap := map[reflect.Type]reflect.Type{
@@ -63,16 +479,159 @@ func main() {
 		reflect.TypeOf(time.Time{}):  reflect.TypeOf(""),
 		reflect.TypeOf(struct{}{}):   reflect.TypeOf(""),
 	}
-	schema, err := schemagen.GenerateSchema(reflect.TypeOf(Schema{}), packages, typeMap)
+	opts := schemagen.Options{
+		Packages: packages,
+		TypeMap:  typeMap,
+		Warn: func(message string) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+		},
+		FormatHeuristicsReport: func(property, format string) {
+			fmt.Fprintf(os.Stderr, "Guessed format %s for %s\n", format, property)
+		},
+	}
+	err := cfg.Apply(&opts)
+	return opts, err
+}
+
+// generate renders the schema described by the configuration, returning
+// the content of each file to write keyed by path. An empty path stands for
+// stdout.
+func generate(cfg *schemagen.Config, opts schemagen.Options) (map[string]string, error) {
+	if len(cfg.Split) > 0 {
+		schemas, err := schemagen.GenerateSplitSchema(reflect.TypeOf(Schema{}), opts, cfg.IndexName())
+		if err != nil {
+			return nil, err
+		}
+		files := make(map[string]string)
+		imports := make(map[string][]string)
+		usedBy := make(map[string][]string)
+		for name, schema := range schemas {
+			files[filepath.Join(cfg.Split, name)] = render(schema)
+			for def, classes := range schemagen.JavaImports(schema) {
+				if schema.Definitions[def].JSONReferenceDescriptor == nil {
+					imports[def] = classes
+				}
+			}
+			for def, pointers := range schemagen.Usages(schema) {
+				for _, pointer := range pointers {
+					// Skip the definitions of the index referring to
+					// the document holding them.
+					if name == cfg.IndexName() && strings.Count(pointer, "/") == 2 {
+						continue
+					}
+					usedBy[def] = append(usedBy[def], name+"#"+pointer)
+				}
+			}
+		}
+		if len(cfg.Usages) > 0 {
+			for _, pointers := range usedBy {
+				sort.Strings(pointers)
+			}
+			files[cfg.Usages] = render(usedBy)
+		}
+		if len(cfg.JavaImports) > 0 {
+			files[cfg.JavaImports] = render(imports)
+		}
+		return files, nil
+	}
+	var schema *schemagen.JSONSchema
+	var ui map[string]schemagen.UIElement
+	var err error
+	if len(cfg.UISchema) > 0 {
+		schema, ui, err = schemagen.GenerateUISchemas(reflect.TypeOf(Schema{}), opts)
+	} else {
+		schema, err = schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+	}
 	if err != nil {
-		fmt.Errorf("An error occurred: %v", err)
-		return
+		return nil, err
+	}
+	content, err := renderOutput(cfg, schema)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{cfg.Output: content}
+	if len(ui) > 0 {
+		files[cfg.UISchema] = render(ui)
+	}
+	if len(cfg.Draft2020) > 0 {
+		var b bytes.Buffer
+		if err := (schemagen.DraftEmitter{Draft: schemagen.Draft2020}).Emit(schema, &b); err != nil {
+			return nil, err
+		}
+		files[cfg.Draft2020] = render(json.RawMessage(b.Bytes()))
+	}
+	if len(cfg.JavaImports) > 0 {
+		files[cfg.JavaImports] = render(schemagen.JavaImports(schema))
 	}
-	b, _ := json.Marshal(&schema)
+	if len(cfg.Usages) > 0 {
+		files[cfg.Usages] = render(schemagen.Usages(schema))
+	}
+	if len(cfg.TypeScript) > 0 {
+		var b bytes.Buffer
+		if err := (schemagen.TypeScriptEmitter{Root: "Schema"}).Emit(schema, &b); err != nil {
+			return nil, err
+		}
+		files[cfg.TypeScript] = strings.TrimSuffix(b.String(), "\n")
+	}
+	if len(cfg.Docs) > 0 {
+		var b bytes.Buffer
+		if err := (schemagen.DocsEmitter{Format: schemagen.DocsFormatFor(cfg.Docs), Root: "Schema"}).Emit(schema, &b); err != nil {
+			return nil, err
+		}
+		files[cfg.Docs] = strings.TrimSuffix(b.String(), "\n")
+	}
+	if len(cfg.Proto) > 0 {
+		numbers, err := schemagen.LoadProtoNumbers(cfg.ProtoNumbersName())
+		if err != nil {
+			return nil, err
+		}
+		var b bytes.Buffer
+		if err := (schemagen.ProtoEmitter{Package: cfg.ProtoPackage, Root: "Schema", Numbers: numbers}).Emit(schema, &b); err != nil {
+			return nil, err
+		}
+		files[cfg.Proto] = strings.TrimSuffix(b.String(), "\n")
+		encoded, _ := json.MarshalIndent(numbers, "", "  ")
+		files[cfg.ProtoNumbersName()] = string(encoded)
+	}
+	return files, nil
+}
+
+// generateRegistered renders the schema of the type registered under name
+// alone.
+func generateRegistered(cfg *schemagen.Config, opts schemagen.Options, name string) (map[string]string, error) {
+	t, ok := schemagen.Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("Unknown type %s, registered types are: %s.", name, strings.Join(schemagen.RegisteredNames(), ", "))
+	}
+	schema, err := schemagen.GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderOutput(cfg, schema)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{cfg.Output: content}, nil
+}
+
+// renderOutput renders v like render, in the output format of the
+// configuration.
+func renderOutput(cfg *schemagen.Config, v interface{}) (string, error) {
+	content := render(v)
+	format, err := schemagen.ParseOutputFormat(cfg.Format)
+	if err != nil || format == schemagen.JSONFormat {
+		return content, err
+	}
+	b, err := schemagen.JSONToYAML([]byte(content))
+	return strings.TrimSuffix(string(b), "\n"), err
+}
+
+// render serializes a schema, or one of its definitions, as expected by the
+// Java code generation.
+func render(v interface{}) string {
+	b, _ := json.Marshal(v)
 	result := string(b)
-	result = strings.Replace(result, "\"additionalProperty\":", "\"additionalProperties\":", -1)
 	result = strings.Replace(result, "\"apiVersion\":{\"type\":\"string\"}", "\"apiVersion\":{\"type\":\"string\",\"default\":\"v1beta2\"}", -1)
 	result = strings.Replace(result, "\"io.fabric8.kubernetes.api.model.List\"", "\"io.fabric8.kubernetes.api.model.KubernetesList\"", -1)
-
-	fmt.Println(result)
+	return result
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// gotypes writes the Go types of a schema file, or of the generated schema
+// if none is given, so that Go clients follow a schema kept as the source
+// of truth.
+func gotypes(args []string) int {
+	fs := flag.NewFlagSet("gotypes", flag.ExitOnError)
+	config := fs.String("config", "", "read the generation configuration from this YAML file, without a schema file")
+	pkg := fs.String("package", "model", "the package of the generated file")
+	root := fs.String("root", "Schema", "the name of the struct of the root object, left out if empty")
+	output := fs.String("output", "", "write the Go types to this file instead of stdout")
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "gotypes takes at most one schema file")
+		return 2
+	}
+	var schema *schemagen.JSONSchema
+	if fs.NArg() == 1 {
+		data, err := ioutil.ReadFile(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+		if schema, err = schemagen.ParseSchema(data); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	} else {
+		cfg := &schemagen.Config{}
+		if len(*config) > 0 {
+			var err error
+			if cfg, err = schemagen.LoadConfig(*config); err != nil {
+				fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+				return 2
+			}
+		}
+		opts, err := options(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+		if schema, err = schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+
+	var b bytes.Buffer
+	if err := (schemagen.GoEmitter{Package: *pkg, Root: *root}).Emit(schema, &b); err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	if len(*output) == 0 {
+		os.Stdout.Write(b.Bytes())
+		return 0
+	}
+	if err := ioutil.WriteFile(*output, b.Bytes(), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// lint generates the schema, optionally described by a configuration, and
+// prints the problems found in it. It returns a non-zero exit code if there
+// is any.
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	config := fs.String("config", "", "read the generation configuration from this YAML file")
+	asJSON := fs.Bool("json", false, "print the findings as a JSON array")
+	fs.Parse(args)
+
+	cfg := &schemagen.Config{}
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+	opts, err := options(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	schema, err := schemagen.GenerateSchemaWithOptions(reflect.TypeOf(Schema{}), opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+
+	findings := schemagen.Lint(schema)
+	if *asJSON {
+		if findings == nil {
+			findings = []schemagen.Finding{}
+		}
+		b, _ := json.MarshalIndent(findings, "", "  ")
+		fmt.Println(string(b))
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s: %s: %s\n", f.Pointer, f.Rule, f.Message)
+		}
+	}
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// configProfiles returns the profiles of the configuration named by the
+// -profile flag. A single name the configuration does not define, such as
+// java or helm, is a built-in profile, which yields none.
+func configProfiles(cfg *schemagen.Config, value string) ([]string, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	names := strings.Split(value, ",")
+	for _, name := range names {
+		if _, ok := cfg.Profiles[name]; !ok {
+			if len(names) == 1 {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("Unknown profile %q, the configuration defines: %s.", name, strings.Join(cfg.ProfileNames(), ", "))
+		}
+	}
+	return names, nil
+}
+
+// runProfiles runs the generator once per profile, with the same arguments
+// but for -profile, and returns the exit status of the first failing run.
+func runProfiles(profiles []string) int {
+	self, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 1
+	}
+	status := 0
+	for _, profile := range profiles {
+		cmd := exec.Command(self, profileArgs(os.Args[1:], profile)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Profile %s failed: %v\n", profile, err)
+			if status == 0 {
+				status = 1
+			}
+		}
+	}
+	return status
+}
+
+// profileArgs returns args with the value of the -profile flag replaced by
+// profile.
+func profileArgs(args []string, profile string) []string {
+	var result []string
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		switch {
+		case args[i] == "--":
+			return append(append(result, "-profile="+profile), args[i:]...)
+		case name == "profile" && strings.HasPrefix(args[i], "-"):
+			i++
+		case strings.HasPrefix(name, "profile=") && strings.HasPrefix(args[i], "-"):
+		default:
+			result = append(result, args[i])
+		}
+	}
+	return append(result, "-profile="+profile)
+}
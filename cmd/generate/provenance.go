@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// checkProvenance records the provenance of the schema in the lock file of
+// the configuration or, when frozen, fails if it differs from the recorded
+// one, listing the changes.
+func checkProvenance(cfg *schemagen.Config, frozen bool) error {
+	current, err := schemagen.NewProvenance(cfg, reflect.TypeOf(Schema{}))
+	if err != nil {
+		return err
+	}
+	if frozen {
+		recorded, err := schemagen.LoadProvenance(cfg.ProvenanceLock)
+		if err != nil {
+			return err
+		}
+		if changes := recorded.Changes(current); len(changes) > 0 {
+			return fmt.Errorf("The inputs differ from the ones recorded in %s: %s.", cfg.ProvenanceLock, strings.Join(changes, "; "))
+		}
+		return nil
+	}
+	b, _ := json.MarshalIndent(current, "", "  ")
+	return writeChanged(cfg.ProvenanceLock, append(b, '\n'))
+}
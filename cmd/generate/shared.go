@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// generateShared regenerates the schema of the type registered as root
+// alone, referring to the definitions of the shared definitions file, and
+// adds or updates in that file the definitions reachable from the root.
+// The file is left untouched if none of them changed.
+func generateShared(cfg *schemagen.Config, opts schemagen.Options, shared, root string) error {
+	t, ok := schemagen.Lookup(root)
+	if !ok {
+		return fmt.Errorf("Unknown root type %s.", root)
+	}
+	schema, err := schemagen.GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return err
+	}
+	existing, err := ioutil.ReadFile(shared)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	definitions := make(map[string][]byte)
+	for name, def := range schema.Definitions {
+		definitions[name] = []byte(render(def))
+	}
+	merged, err := schemagen.MergeDefinitions(existing, definitions)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(merged, existing) {
+		if err := ioutil.WriteFile(shared, append(merged, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	content := render(schemagen.SharedRoot(schema, filepath.Base(shared)))
+	if len(cfg.Output) == 0 {
+		fmt.Println(content)
+		return nil
+	}
+	return ioutil.WriteFile(cfg.Output, []byte(content+"\n"), 0644)
+}
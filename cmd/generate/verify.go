@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// verify regenerates the schema files described by a configuration in
+// memory and compares them with the files on disk. It prints the
+// differences and returns a non-zero exit code if any file is out of date.
+func verify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	config := fs.String("config", "", "the generation configuration describing the committed schema files")
+	fs.Parse(args)
+
+	if len(*config) == 0 {
+		fmt.Fprintln(os.Stderr, "verify requires -config")
+		return 2
+	}
+	cfg, err := schemagen.LoadConfig(*config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	if len(cfg.Output) == 0 && len(cfg.Split) == 0 {
+		fmt.Fprintln(os.Stderr, "The configuration names no output to verify.")
+		return 2
+	}
+	opts, err := options(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	files, err := generate(cfg, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failed := false
+	for _, name := range names {
+		committed, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Printf("%s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		diffs, err := schemagen.CompareJSON(committed, []byte(files[name]))
+		if err != nil {
+			fmt.Printf("%s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		if len(diffs) > 0 {
+			fmt.Printf("%s is out of date:\n", name)
+			for _, d := range diffs {
+				fmt.Printf("  %s\n", d)
+			}
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
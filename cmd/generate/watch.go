@@ -0,0 +1,159 @@
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// watch polls the Go sources of the packages described by the generation
+// options and of their imports, and regenerates the schema when they
+// change. Modification times are polled, as no file notification package is
+// vendored. The API types are
+// compiled into this command, so each regeneration runs a fresh build of it
+// with the arguments following the watch flags.
+func watch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	config := fs.String("config", "", "the generation configuration, also passed to each regeneration")
+	interval := fs.Duration("interval", time.Second, "how often to check the sources for changes")
+	debounce := fs.Duration("debounce", 500*time.Millisecond, "how long the sources must stay unchanged before regenerating")
+	command := fs.String("package", "github.com/csrwng/origin-schema-generator/cmd/generate", "the import path of the generator command to rebuild")
+	fs.Parse(args)
+
+	cfg := &schemagen.Config{}
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+			return 2
+		}
+	}
+	opts, err := options(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+		return 2
+	}
+	dirs := sourceDirs(append([]string{*command}, goPackages(opts)...))
+
+	childArgs := []string{"run", *command}
+	if len(*config) > 0 {
+		childArgs = append(childArgs, "-config", *config)
+	}
+	childArgs = append(childArgs, fs.Args()...)
+	regenerate := func() {
+		cmd := exec.Command("go", childArgs...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Regeneration failed: %v\n", err)
+		}
+	}
+
+	last := sourceSnapshot(dirs)
+	regenerate()
+	for {
+		time.Sleep(*interval)
+		current := sourceSnapshot(dirs)
+		if current == last {
+			continue
+		}
+		// Wait for the sources to settle, so that a burst of saves
+		// regenerates once. Changes made while regenerating are picked up
+		// by the next poll rather than queued.
+		for {
+			time.Sleep(*debounce)
+			settled := sourceSnapshot(dirs)
+			if settled == current {
+				break
+			}
+			current = settled
+		}
+		last = current
+		fmt.Fprintln(os.Stderr, "Sources changed, regenerating.")
+		regenerate()
+	}
+}
+
+// goPackages returns the import paths of the packages mapped to Java.
+func goPackages(opts schemagen.Options) []string {
+	paths := make([]string, 0, len(opts.Packages))
+	for _, pkg := range opts.Packages {
+		paths = append(paths, pkg.GoPackage)
+	}
+	return paths
+}
+
+// sourceDirs returns the directories of the packages with the given import
+// paths and of the packages they import, transitively, leaving out the
+// standard library. Types of imported packages, such as ObjectMeta, are part
+// of the schema too.
+func sourceDirs(paths []string) []string {
+	dirs := []string{}
+	seen := map[string]bool{}
+	var visit func(path, srcDir string)
+	visit = func(path, srcDir string) {
+		if path == "C" {
+			return
+		}
+		pkg, err := build.Import(path, srcDir, 0)
+		if err != nil {
+			if _, ok := err.(*build.NoGoError); !ok {
+				fmt.Fprintf(os.Stderr, "Warning: not watching %s: %v\n", path, err)
+				return
+			}
+		}
+		if pkg.Goroot || seen[pkg.Dir] {
+			return
+		}
+		seen[pkg.Dir] = true
+		dirs = append(dirs, pkg.Dir)
+		for _, imp := range pkg.Imports {
+			visit(imp, pkg.Dir)
+		}
+	}
+	for _, path := range paths {
+		visit(path, "")
+	}
+	return dirs
+}
+
+// sourceSnapshot summarizes the name, size and modification time of the Go
+// files in dirs, so that comparing two snapshots tells whether any of them
+// changed.
+func sourceSnapshot(dirs []string) string {
+	entries := []string{}
+	for _, dir := range dirs {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, info := range infos {
+			if info.IsDir() || !strings.HasSuffix(info.Name(), ".go") {
+				continue
+			}
+			entries = append(entries, fmt.Sprintf("%s %d %d", filepath.Join(dir, info.Name()), info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, "\n")
+}
+
+// writeChanged writes content to name unless the file already holds the
+// same content, so that unchanged artifacts keep their modification time
+// and do not trigger downstream rebuilds.
+func writeChanged(name string, content []byte) error {
+	if existing, err := ioutil.ReadFile(name); err == nil && sha256.Sum256(existing) == sha256.Sum256(content) {
+		return nil
+	}
+	return ioutil.WriteFile(name, content, 0644)
+}
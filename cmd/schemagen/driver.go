@@ -0,0 +1,150 @@
+package main
+
+import (
+	"sort"
+	"text/template"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// driver describes the program generating the schema of the root types.
+// Types can only be described once compiled, so schemagen writes a program
+// importing their packages and runs it.
+type driver struct {
+	// Imports lists the import paths of the packages of the types, whose
+	// index is their alias.
+	Imports []string
+	// Roots and Types are the root types and all the types named on the
+	// command line or in the configuration.
+	Roots []driverType
+	Types []driverType
+}
+
+// driverType is a type declared by the package imported as p<Import>.
+type driverType struct {
+	Import int
+	Name   string
+}
+
+// newDriver returns the driver of the given root types of pkgPath, which
+// also resolves the types of the type map of the configuration.
+func newDriver(pkgPath string, roots []string, cfg *schemagen.Config) *driver {
+	d := &driver{}
+	imports := map[string]int{}
+	add := func(pkgPath, name string) driverType {
+		i, ok := imports[pkgPath]
+		if !ok {
+			i = len(d.Imports)
+			imports[pkgPath] = i
+			d.Imports = append(d.Imports, pkgPath)
+		}
+		t := driverType{Import: i, Name: name}
+		d.Types = append(d.Types, t)
+		return t
+	}
+	for _, name := range roots {
+		d.Roots = append(d.Roots, add(pkgPath, name))
+	}
+	names := []string{}
+	for from, to := range cfg.TypeMap {
+		names = append(names, from, to)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if pkgPath, typeName := schemagen.SplitTypeName(name); len(pkgPath) > 0 {
+			add(pkgPath, typeName)
+		}
+	}
+	return d
+}
+
+var driverTemplate = template.Must(template.New("driver").Parse(`// Code generated by schemagen. DO NOT EDIT.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+{{range $i, $path := .Imports}}
+	p{{$i}} {{printf "%q" $path}}{{end}}
+)
+
+func main() {
+	config := flag.String("config", "", "")
+	output := flag.String("output", "", "")
+	snapshot := flag.String("snapshot", "", "")
+	flag.Parse()
+
+	roots := []reflect.Type{ {{range .Roots}}
+		reflect.TypeOf((*p{{.Import}}.{{.Name}})(nil)).Elem(),{{end}}
+	}
+	if len(*snapshot) > 0 {
+		s, err := schemagen.NewTypeSnapshot(roots...)
+		if err != nil {
+			fail(err)
+		}
+		f, err := os.Create(*snapshot)
+		if err != nil {
+			fail(err)
+		}
+		err = s.Write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			fail(err)
+		}
+		return
+	}
+	cfg := &schemagen.Config{}
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fail(err)
+		}
+	}
+	opts := schemagen.Options{
+		Resolver: schemagen.TypeList({{range .Types}}
+			reflect.TypeOf((*p{{.Import}}.{{.Name}})(nil)).Elem(),{{end}}
+		),
+		Warn: func(message string) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+		},
+	}
+	if err := cfg.Apply(&opts); err != nil {
+		fail(err)
+	}
+	if len(*output) == 0 {
+		*output = cfg.Output
+	}
+	var schema *schemagen.JSONSchema
+	var err error
+	if len(roots) == 1 {
+		schema, err = schemagen.GenerateSchemaWithOptions(roots[0], opts)
+	} else {
+		schema, err = schemagen.GenerateSchemas(roots, opts)
+	}
+	if err != nil {
+		fail(err)
+	}
+	b, _ := json.Marshal(schema)
+	if len(*output) == 0 {
+		os.Stdout.Write(b)
+		return
+	}
+	if err := ioutil.WriteFile(*output, b, 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+	os.Exit(1)
+}
+`))
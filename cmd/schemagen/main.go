@@ -0,0 +1,185 @@
+// Command schemagen generates the JSON Schema of Go types given on the
+// command line:
+//
+//	schemagen [-config gen.yaml] [-output schema.json] <package> <Type>...
+//
+// Package descriptors, type maps and the other generation settings are read
+// from the configuration. Since types can only be described once compiled,
+// schemagen writes a program importing the package under the working
+// directory, runs it with go run and removes it. With -source, the types
+// are described from their type-checked sources instead, without compiling
+// them. With -snapshot, the program records the type graph in a snapshot
+// file instead, from which -from-snapshot regenerates the schema later
+// without the packages:
+//
+//	schemagen -snapshot widgets.snapshot.json ./pkg/api Widget
+//	schemagen -from-snapshot widgets.snapshot.json -output widgets.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+func main() {
+	config := flag.String("config", "", "read the generation configuration, including package descriptors and type maps, from this YAML file")
+	output := flag.String("output", "", "write the schema to this file instead of stdout")
+	keep := flag.Bool("keep", false, "keep the generated program for inspection instead of removing it")
+	source := flag.Bool("source", false, "describe the types from their type-checked sources instead of compiling a program importing them")
+	snapshot := flag.String("snapshot", "", "write a snapshot of the types reachable from the roots to this file instead of the schema")
+	fromSnapshot := flag.String("from-snapshot", "", "generate the schema of the roots of this snapshot file, without the package")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: schemagen [flags] <package> <Type>...")
+		fmt.Fprintln(os.Stderr, "       schemagen [flags] -from-snapshot <file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	cfg := &schemagen.Config{}
+	if len(*config) > 0 {
+		var err error
+		if cfg, err = schemagen.LoadConfig(*config); err != nil {
+			fail(err)
+		}
+	}
+	if len(*fromSnapshot) > 0 {
+		if err := generateFromSnapshot(*fromSnapshot, cfg, *output); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	for _, name := range flag.Args()[1:] {
+		if !ast.IsExported(name) {
+			fail(fmt.Errorf("%q is not the name of an exported type; flags go before the package.", name))
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		fail(err)
+	}
+	// Accept relative package paths, such as ./pkg/api.
+	pkg, err := build.Import(flag.Arg(0), wd, build.FindOnly)
+	if err != nil {
+		fail(err)
+	}
+
+	if *source {
+		if err := generateFromSource(pkg.ImportPath, flag.Args()[1:], cfg, *output); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	// The program is written under the working directory so that it
+	// builds against the same GOPATH or module as the package.
+	dir, err := ioutil.TempDir(wd, "schemagen-driver")
+	if err != nil {
+		fail(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		fail(err)
+	}
+	err = driverTemplate.Execute(f, newDriver(pkg.ImportPath, flag.Args()[1:], cfg))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fail(err)
+	}
+
+	args := []string{"run", f.Name()}
+	if len(*config) > 0 {
+		args = append(args, "-config", *config)
+	}
+	if len(*output) > 0 {
+		args = append(args, "-output", *output)
+	}
+	if len(*snapshot) > 0 {
+		args = append(args, "-snapshot", *snapshot)
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if *keep {
+		fmt.Fprintf(os.Stderr, "The generated program is kept in %s.\n", dir)
+	} else {
+		os.RemoveAll(dir)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// generateFromSource writes the schema of the given types of pkgPath,
+// described from their sources.
+func generateFromSource(pkgPath string, names []string, cfg *schemagen.Config, output string) error {
+	opts := schemagen.Options{
+		Warn: func(message string) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+		},
+	}
+	if err := cfg.Apply(&opts); err != nil {
+		return err
+	}
+	schema, err := schemagen.GenerateSourceSchema(pkgPath, names, opts)
+	if err != nil {
+		return err
+	}
+	return writeSchema(schema, cfg, output)
+}
+
+// generateFromSnapshot writes the schema of the roots of the snapshot
+// file at path.
+func generateFromSnapshot(path string, cfg *schemagen.Config, output string) error {
+	snapshot, err := schemagen.LoadTypeSnapshot(path)
+	if err != nil {
+		return err
+	}
+	opts := schemagen.Options{
+		Warn: func(message string) {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+		},
+	}
+	if err := cfg.Apply(&opts); err != nil {
+		return err
+	}
+	schema, err := schemagen.GenerateSnapshotSchema(snapshot, opts)
+	if err != nil {
+		return err
+	}
+	return writeSchema(schema, cfg, output)
+}
+
+// writeSchema writes schema to output, or to the output of the
+// configuration, or else to stdout.
+func writeSchema(schema *schemagen.JSONSchema, cfg *schemagen.Config, output string) error {
+	if len(output) == 0 {
+		output = cfg.Output
+	}
+	b, _ := json.Marshal(schema)
+	if len(output) == 0 {
+		_, err := os.Stdout.Write(b)
+		return err
+	}
+	return ioutil.WriteFile(output, b, 0644)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "An error occurred: %v\n", err)
+	os.Exit(1)
+}
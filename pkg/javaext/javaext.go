@@ -0,0 +1,126 @@
+// Package javaext layers the hints used by jsonschema2pojo to generate the
+// Java model over schemas generated by package schemagen, so that they can
+// be configured independently of the core generation. Java types are still
+// derived from the Go packages by schemagen, and left out of the schema
+// with schemagen.Options.StandardOnly.
+package javaext
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen"
+)
+
+// Options configures the Java hints added to a schema.
+type Options struct {
+	// Interfaces lists, by definition name, the Java interfaces
+	// implemented by the class generated for the definition.
+	Interfaces map[string][]string
+
+	// EnumNames gives the names of the Java constants generated for the
+	// enum values of a definition, or of a property when keyed by
+	// "<definition name>.<property name>".
+	EnumNames map[string][]string
+
+	// BuilderAnnotations are added to the custom annotations of every
+	// object definition, e.g. to have builders generated for the model.
+	BuilderAnnotations []string
+}
+
+// Apply returns a copy of schema with the Java hints of opts added. It
+// fails if opts refers to unknown definitions or properties.
+func Apply(schema *schemagen.JSONSchema, opts Options) (*schemagen.JSONSchema, error) {
+	result := *schema
+	result.Definitions = make(map[string]schemagen.JSONPropertyDescriptor, len(schema.Definitions))
+	for name, def := range schema.Definitions {
+		if len(opts.BuilderAnnotations) > 0 && def.JSONObjectDescriptor != nil {
+			var annotations []string
+			if def.JavaAnnotationsDescriptor != nil {
+				annotations = append(annotations, def.CustomAnnotations...)
+			}
+			def.JavaAnnotationsDescriptor = &schemagen.JavaAnnotationsDescriptor{
+				CustomAnnotations: append(annotations, opts.BuilderAnnotations...),
+			}
+		}
+		result.Definitions[name] = def
+	}
+
+	for _, name := range sortedKeys(opts.Interfaces) {
+		def, ok := result.Definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("Java interfaces for unknown definition %s.", name)
+		}
+		if def.JavaTypeDescriptor != nil {
+			// Merged with the interfaces of the package descriptor.
+			java := *def.JavaTypeDescriptor
+			java.JavaInterfaces = append(append([]string{}, java.JavaInterfaces...), opts.Interfaces[name]...)
+			def.JavaTypeDescriptor = &java
+			result.Definitions[name] = def
+			continue
+		}
+		result.Definitions[name] = extend(def, "javaInterfaces", opts.Interfaces[name])
+	}
+
+	for _, key := range sortedKeys(opts.EnumNames) {
+		parts := strings.SplitN(key, ".", 2)
+		def, ok := result.Definitions[parts[0]]
+		if !ok {
+			return nil, fmt.Errorf("Java enum names for unknown definition %s.", parts[0])
+		}
+		if len(parts) == 1 {
+			result.Definitions[key] = extend(def, "javaEnumNames", opts.EnumNames[key])
+			continue
+		}
+		prop, ok := def.Properties[parts[1]]
+		if def.JSONObjectDescriptor == nil || !ok {
+			return nil, fmt.Errorf("Java enum names for unknown property %s.", key)
+		}
+		object := *def.JSONObjectDescriptor
+		object.Properties = make(map[string]schemagen.JSONPropertyDescriptor, len(def.Properties))
+		for k, v := range def.Properties {
+			object.Properties[k] = v
+		}
+		object.Properties[parts[1]] = extend(prop, "javaEnumNames", opts.EnumNames[key])
+		def.JSONObjectDescriptor = &object
+		result.Definitions[parts[0]] = def
+	}
+	return &result, nil
+}
+
+// extend returns a copy of p with the extension keyword set to value.
+func extend(p schemagen.JSONPropertyDescriptor, keyword string, value interface{}) schemagen.JSONPropertyDescriptor {
+	extensions := make(map[string]interface{}, len(p.Extensions)+1)
+	for k, v := range p.Extensions {
+		extensions[k] = v
+	}
+	extensions[keyword] = value
+	p.Extensions = extensions
+	return p
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Emitter adds the Java hints of Options to the schema before running the
+// wrapped emitter over it.
+type Emitter struct {
+	Options Options
+	Emitter schemagen.Emitter
+}
+
+func (e Emitter) Emit(schema *schemagen.JSONSchema, w io.Writer) error {
+	extended, err := Apply(schema, e.Options)
+	if err != nil {
+		return err
+	}
+	return e.Emitter.Emit(extended, w)
+}
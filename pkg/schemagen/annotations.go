@@ -0,0 +1,121 @@
+package schemagen
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/v1/yaml"
+)
+
+// Annotation enriches a generated definition or property. Annotations are
+// usually read from a sidecar YAML file keyed by definition name, so that
+// schemas can be documented without touching the Go sources:
+//
+//	os_route_Route:
+//	  description: A route exposes a service at a host name.
+//	  properties:
+//	    host:
+//	      description: The host name the route is exposed at.
+//	      examples: [www.example.com]
+//	  extensions:
+//	    x-doc-category: networking
+type Annotation struct {
+	Description string                 `yaml:"description"`
+	Examples    []interface{}          `yaml:"examples"`
+	Deprecated  bool                   `yaml:"deprecated"`
+	Extensions  map[string]interface{} `yaml:"extensions"`
+	Properties  map[string]Annotation  `yaml:"properties"`
+}
+
+// LoadAnnotations reads the annotations stored in the YAML file at path.
+func LoadAnnotations(path string) (map[string]Annotation, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	annotations := map[string]Annotation{}
+	if err := yaml.Unmarshal(data, &annotations); err != nil {
+		return nil, fmt.Errorf("Unable to parse annotations %s: %v", path, err)
+	}
+	return annotations, nil
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced by
+// the YAML decoder into map[string]interface{} so they can be encoded as
+// JSON.
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = normalizeYAML(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = normalizeYAML(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = normalizeYAML(e)
+		}
+		return l
+	}
+	return v
+}
+
+// annotate returns a copy of p enriched with the annotation.
+func annotate(p JSONPropertyDescriptor, a Annotation) JSONPropertyDescriptor {
+	if len(a.Description) > 0 || len(a.Examples) > 0 || a.Deprecated {
+		desc := JSONDescriptor{}
+		if p.JSONDescriptor != nil {
+			desc = *p.JSONDescriptor
+		}
+		if len(a.Description) > 0 {
+			desc.Description = a.Description
+		}
+		if len(a.Examples) > 0 {
+			desc.Examples = normalizeYAML(a.Examples).([]interface{})
+		}
+		if a.Deprecated {
+			desc.Deprecated = true
+		}
+		p.JSONDescriptor = &desc
+	}
+	if len(a.Extensions) > 0 {
+		ext := make(map[string]interface{}, len(p.Extensions)+len(a.Extensions))
+		for k, v := range p.Extensions {
+			ext[k] = v
+		}
+		for k, v := range a.Extensions {
+			ext[k] = normalizeYAML(v)
+		}
+		p.Extensions = ext
+	}
+	if len(a.Properties) > 0 && p.JSONObjectDescriptor != nil {
+		obj := *p.JSONObjectDescriptor
+		obj.Properties = make(map[string]JSONPropertyDescriptor, len(p.Properties))
+		for k, v := range p.Properties {
+			if pa, ok := a.Properties[k]; ok {
+				v = annotate(v, pa)
+			}
+			obj.Properties[k] = v
+		}
+		p.JSONObjectDescriptor = &obj
+	}
+	return p
+}
+
+// applyAnnotations merges the configured annotations into the definitions
+// they are keyed by. Annotations of definitions that are not part of the
+// schema are ignored, so a single file can serve several schemas.
+func (g *schemaGenerator) applyAnnotations(s *JSONSchema) {
+	for name, a := range g.opts.Annotations {
+		if def, ok := s.Definitions[name]; ok {
+			s.Definitions[name] = annotate(def, a)
+		}
+	}
+}
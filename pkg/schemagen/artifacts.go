@@ -0,0 +1,151 @@
+package schemagen
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Media types of the artifacts rendered by the emitters of the package.
+const (
+	MediaTypeSchema = "application/schema+json"
+	MediaTypeJSON   = "application/json"
+	MediaTypeText   = "text/plain"
+)
+
+// Artifact is a file rendered from a schema, kept in memory so that tools
+// can post-process or ship it before, or instead of, writing it to disk.
+type Artifact struct {
+	// Name is the slash separated path of the artifact, relative to the
+	// location the artifacts are written to.
+	Name      string
+	MediaType string
+	Data      []byte
+}
+
+// Artifacts is the result of rendering a schema with several emitters.
+type Artifacts []Artifact
+
+// Lookup returns the artifact with the given name.
+func (a Artifacts) Lookup(name string) (Artifact, bool) {
+	for _, artifact := range a {
+		if artifact.Name == name {
+			return artifact, true
+		}
+	}
+	return Artifact{}, false
+}
+
+// ArtifactSpec names the artifact rendered by an emitter.
+type ArtifactSpec struct {
+	Name      string
+	MediaType string
+	Emitter   Emitter
+}
+
+// EmitArtifacts walks t once and renders the artifact of every spec, like
+// Emit, returning them in the order of the specs.
+func EmitArtifacts(t reflect.Type, opts Options, specs ...ArtifactSpec) (Artifacts, error) {
+	buffers := make([]bytes.Buffer, len(specs))
+	outputs := make([]Output, len(specs))
+	for i, spec := range specs {
+		outputs[i] = Output{Emitter: spec.Emitter, Writer: &buffers[i]}
+	}
+	if err := Emit(t, opts, outputs...); err != nil {
+		return nil, err
+	}
+	artifacts := make(Artifacts, len(specs))
+	for i, spec := range specs {
+		artifacts[i] = Artifact{Name: spec.Name, MediaType: spec.MediaType, Data: buffers[i].Bytes()}
+	}
+	return artifacts, nil
+}
+
+// SchemaArtifacts renders the schemas returned by GenerateSplitSchema as
+// JSON Schema artifacts named after their files.
+func SchemaArtifacts(schemas map[string]*JSONSchema) (Artifacts, error) {
+	var artifacts Artifacts
+	for _, name := range sortedSchemaNames(schemas) {
+		var b bytes.Buffer
+		if err := (JSONSchemaEmitter{}).Emit(schemas[name], &b); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, Artifact{Name: name, MediaType: MediaTypeSchema, Data: b.Bytes()})
+	}
+	return artifacts, nil
+}
+
+func sortedSchemaNames(schemas map[string]*JSONSchema) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ArtifactWriter stores artifacts, e.g. as files or in an archive.
+type ArtifactWriter interface {
+	WriteArtifacts(artifacts Artifacts) error
+}
+
+// DirWriter writes artifacts as files under Dir, creating the directories
+// their names require.
+type DirWriter struct {
+	Dir string
+}
+
+func (w DirWriter) WriteArtifacts(artifacts Artifacts) error {
+	for _, a := range artifacts {
+		path := filepath.Join(w.Dir, filepath.FromSlash(a.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, a.Data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TarWriter writes artifacts as the entries of a tar archive. Entries carry
+// no modification time, so that archives of the same artifacts are
+// identical.
+type TarWriter struct {
+	Writer io.Writer
+}
+
+func (w TarWriter) WriteArtifacts(artifacts Artifacts) error {
+	tw := tar.NewWriter(w.Writer)
+	for _, a := range artifacts {
+		header := &tar.Header{
+			Name:    a.Name,
+			Mode:    0644,
+			Size:    int64(len(a.Data)),
+			ModTime: time.Unix(0, 0),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(a.Data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// MemoryWriter collects the content of artifacts by name.
+type MemoryWriter map[string][]byte
+
+func (w MemoryWriter) WriteArtifacts(artifacts Artifacts) error {
+	for _, a := range artifacts {
+		w[a.Name] = a.Data
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// auditLimit is the largest number of differences listed by a failed
+// determinism audit.
+const auditLimit = 10
+
+// audit generates the schema of t a second time and fails if it does not
+// serialize to the same bytes as s, see Options.Audit. The generation stamp
+// of Stamped builds is left out of the comparison.
+func audit(t reflect.Type, opts Options, s *JSONSchema) error {
+	again, err := newSchemaGenerator(opts).generate(t)
+	if err != nil {
+		return fmt.Errorf("Determinism audit failed: the second generation failed: %v", err)
+	}
+	first, err := auditBytes(s)
+	if err != nil {
+		return err
+	}
+	second, err := auditBytes(again)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(first, second) {
+		return nil
+	}
+	diffs, err := CompareJSON(first, second)
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		// Same model, serialized differently, e.g. by a custom marshaller.
+		diffs = []string{"the documents are equal but serialized differently"}
+	}
+	if len(diffs) > auditLimit {
+		diffs = append(diffs[:auditLimit], fmt.Sprintf("and %d more", len(diffs)-auditLimit))
+	}
+	return fmt.Errorf("Determinism audit failed, two generations of %s differ: %s.", t, strings.Join(diffs, ", "))
+}
+
+func auditBytes(s *JSONSchema) ([]byte, error) {
+	unstamped := *s
+	unstamped.Generated = nil
+	return json.Marshal(unstamped)
+}
@@ -0,0 +1,108 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type benchLeaf struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+type benchBranch struct {
+	Leaves  []*benchLeaf                       `json:"leaves"`
+	ByName  map[string][]*benchLeaf            `json:"byName"`
+	Nested  map[string]map[string][]*benchLeaf `json:"nested"`
+	Grouped [][]map[string]*benchLeaf          `json:"grouped"`
+}
+
+type benchTree struct {
+	Branches []benchBranch                      `json:"branches"`
+	Index    map[string]map[string]*benchBranch `json:"index"`
+	Parent   *benchTree                         `json:"parent"`
+	Children map[string][]*benchTree            `json:"children"`
+}
+
+type benchForest struct {
+	Trees   []benchTree                        `json:"trees"`
+	Regions map[string][]map[string]*benchTree `json:"regions"`
+	Spare   [][]*benchBranch                   `json:"spare"`
+	Leaves  map[string][][]*benchLeaf          `json:"leaves"`
+}
+
+// benchFieldTypes lists the type of every field occurrence reachable from
+// t, as the generator asks javaType for them.
+func benchFieldTypes(t reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	seen := map[reflect.Type]bool{}
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		t = elemType(t)
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			types = append(types, t.Field(i).Type)
+			walk(t.Field(i).Type)
+		}
+	}
+	walk(t)
+	return types
+}
+
+// BenchmarkGenerateJavaTypesCold resolves the Java type of every field
+// occurrence of the forest without memoization, the cache being emptied
+// before each of them.
+func BenchmarkGenerateJavaTypesCold(b *testing.B) {
+	types := benchFieldTypes(reflect.TypeOf(benchForest{}))
+	g := newSchemaGenerator(Options{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, t := range types {
+			g.javaTypes = make(map[reflect.Type]string)
+			g.javaType(t)
+		}
+	}
+}
+
+// BenchmarkGenerateJavaTypesCached resolves them with the cache of a
+// generator, as a schema generation does.
+func BenchmarkGenerateJavaTypesCached(b *testing.B) {
+	types := benchFieldTypes(reflect.TypeOf(benchForest{}))
+	g := newSchemaGenerator(Options{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, t := range types {
+			g.javaType(t)
+		}
+	}
+}
+
+// BenchmarkGenerateCold generates the schema of the forest with a new
+// generator each time, so with cold caches.
+func BenchmarkGenerateCold(b *testing.B) {
+	t := reflect.TypeOf(benchForest{})
+	for i := 0; i < b.N; i++ {
+		if _, err := GenerateSchemaWithOptions(t, Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGenerateCached generates it again and again with the same
+// generator, whose Java types and definitions are cached.
+func BenchmarkGenerateCached(b *testing.B) {
+	t := reflect.TypeOf(benchForest{})
+	gen := NewGenerator(Options{})
+	if _, err := gen.Generate(t); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := gen.Generate(t); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package schemagen
+
+import (
+	"reflect"
+	"sync"
+)
+
+// NewGenerator returns a generator producing the schemas of several root
+// types with opts. The definitions it builds are kept across calls, so
+// that types shared by the roots, such as ObjectMeta, are walked once. The
+// warnings about a shared type are thus reported with the first schema
+// referring to it only, and the x-debug chain of a definition is the one
+// through which the generator first reached it.
+func NewGenerator(opts Options) *Generator {
+	return &Generator{newSchemaGenerator(opts)}
+}
+
+// Generate generates the schema of t like GenerateSchemaWithOptions,
+// reusing the definitions built for the previous roots. A Generator is not
+// safe for concurrent use, see GenerateAll.
+func (gen *Generator) Generate(t reflect.Type) (*JSONSchema, error) {
+	s, err := gen.g.generate(t)
+	if err != nil || !gen.g.opts.Audit {
+		return s, err
+	}
+	if err := audit(t, gen.g.opts, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GenerateAll generates the schemas of roots, in order, with up to workers
+// roots processed concurrently. The first worker uses gen and the others
+// generators of their own, set up anew with Options.Setup and given the
+// type handlers, kind overrides, implementations and enums registered on
+// gen, so that every root is described alike. The reporting callbacks of
+// the options, Warn, Progress, RetentionReport and FormatHeuristicsReport,
+// are called by one worker at a time, while the hooks such as NameFunc,
+// JavaHintsFunc and Refine must be safe for concurrent use. It returns the
+// error of the first root failing.
+func (gen *Generator) GenerateAll(roots []reflect.Type, workers int) ([]*JSONSchema, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(roots) {
+		workers = len(roots)
+	}
+	opts := gen.g.opts
+	if workers > 1 {
+		gen.g.opts = serializeReports(opts)
+		defer func() { gen.g.opts = opts }()
+	}
+	// The workers are set up before any runs, as gen caches enums.
+	generators := []*Generator{gen}
+	for w := 1; w < workers; w++ {
+		worker := &Generator{newSchemaGenerator(gen.g.opts)}
+		worker.g.copyHooks(gen.g)
+		generators = append(generators, worker)
+	}
+	schemas := make([]*JSONSchema, len(roots))
+	errs := make([]error, len(roots))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for _, worker := range generators {
+		worker := worker
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				schemas[i], errs[i] = worker.Generate(roots[i])
+			}
+		}()
+	}
+	for i := range roots {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return schemas, nil
+}
+
+// serializeReports returns opts with reporting callbacks sharing a lock,
+// so that the workers of GenerateAll call them one at a time.
+func serializeReports(opts Options) Options {
+	var mu sync.Mutex
+	if warn := opts.Warn; warn != nil {
+		opts.Warn = func(message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			warn(message)
+		}
+	}
+	if progress := opts.Progress; progress != nil {
+		opts.Progress = func(done, total int, current string) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress(done, total, current)
+		}
+	}
+	if report := opts.RetentionReport; report != nil {
+		opts.RetentionReport = func(name string, reachable, kept bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			report(name, reachable, kept)
+		}
+	}
+	if report := opts.FormatHeuristicsReport; report != nil {
+		opts.FormatHeuristicsReport = func(property, format string) {
+			mu.Lock()
+			defer mu.Unlock()
+			report(property, format)
+		}
+	}
+	return opts
+}
+
+// copyHooks registers on g the hooks registered on from.
+func (g *schemaGenerator) copyHooks(from *schemaGenerator) {
+	for t, fn := range from.handlers {
+		g.handlers[t] = fn
+		g.intern(t)
+	}
+	for k, desc := range from.kindDescriptors {
+		g.kindDescriptors[k] = desc
+	}
+	for iface, impls := range from.implementations {
+		g.implementations[iface] = append([]reflect.Type(nil), impls...)
+	}
+	for t, e := range from.enums {
+		g.enums[t] = e
+	}
+}
+
+// startRoot prepares the generator for the root t, dropping the cached
+// definitions if t prunes other types than the previous root did, since
+// the definitions referring to pruned types depend on them.
+func (g *schemaGenerator) startRoot(t reflect.Type) {
+	pruned := g.prunedTypes(t)
+	if !sameTypes(g.pruned, pruned) {
+		g.types = make(map[reflect.Type]*JSONPropertyDescriptor)
+		g.dependencies = make(map[reflect.Type]map[reflect.Type]bool)
+	}
+	g.pruned = pruned
+	g.rootTypes = make(map[reflect.Type]bool)
+	g.building = nil
+	g.added = nil
+	g.path = nil
+	g.errors = nil
+	g.defining = make(map[reflect.Type]bool)
+	g.expanding = make(map[reflect.Type]bool)
+	g.javaExpanding = make(map[reflect.Type]bool)
+}
+
+// forgetRoot drops the definitions added for a root whose generation
+// failed, which may be incomplete.
+func (g *schemaGenerator) forgetRoot() {
+	for _, t := range g.added {
+		delete(g.types, t)
+		delete(g.dependencies, t)
+		delete(g.provenance, t)
+	}
+	g.added = nil
+}
+
+// dependsOn records that the type being defined, or the root if none is,
+// refers to the definition of t.
+func (g *schemaGenerator) dependsOn(t reflect.Type) {
+	if len(g.building) == 0 {
+		if g.rootTypes != nil {
+			g.rootTypes[t] = true
+		}
+		return
+	}
+	from := g.building[len(g.building)-1]
+	if g.dependencies[from] == nil {
+		g.dependencies[from] = make(map[reflect.Type]bool)
+	}
+	g.dependencies[from][t] = true
+}
+
+// rootDefinitions returns the defined types the current root refers to,
+// directly or through other definitions.
+func (g *schemaGenerator) rootDefinitions() map[reflect.Type]bool {
+	defined := make(map[reflect.Type]bool)
+	var visit func(t reflect.Type)
+	visit = func(t reflect.Type) {
+		if defined[t] {
+			return
+		}
+		if _, ok := g.types[t]; !ok {
+			return
+		}
+		defined[t] = true
+		for dep := range g.dependencies[t] {
+			visit(dep)
+		}
+	}
+	for t := range g.rootTypes {
+		visit(t)
+	}
+	return defined
+}
+
+func sameTypes(a, b map[reflect.Type]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for t := range a {
+		if !b[t] {
+			return false
+		}
+	}
+	return true
+}
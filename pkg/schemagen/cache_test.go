@@ -0,0 +1,156 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cacheState string
+
+type cacheStatus struct {
+	State cacheState `json:"state"`
+}
+
+type cacheValue interface {
+	cacheValue()
+}
+
+type cacheMember struct {
+	Name string `json:"name"`
+}
+
+func (cacheMember) cacheValue() {}
+
+type cacheHolder struct {
+	Value cacheValue `json:"value"`
+}
+
+type cacheBroken struct {
+	Updates chan string `json:"updates"`
+}
+
+type cacheStatusRoot struct {
+	Status cacheStatus `json:"status"`
+}
+
+type cacheHolderRoot struct {
+	Holder cacheHolder `json:"holder"`
+}
+
+// cachePruningRoot prunes the member of the union held by cacheHolder.
+type cachePruningRoot struct {
+	Holder cacheHolder `json:"holder"`
+	Member cacheMember `json:"member" schemagen:"prune"`
+}
+
+type cacheBrokenRoot struct {
+	Status cacheStatus `json:"status"`
+	Broken cacheBroken `json:"broken"`
+}
+
+type cacheBrokenUserRoot struct {
+	Broken cacheBroken `json:"broken"`
+}
+
+// checkRefs fails if a $ref of schema names a missing definition.
+func checkRefs(t *testing.T, schema *JSONSchema) {
+	RewriteRefs(schema, func(ref string) string {
+		if _, ok := schema.Definitions[strings.TrimPrefix(ref, definitionsPrefix)]; !ok {
+			t.Errorf("%s refers to a missing definition %s", schema.ID, ref)
+		}
+		return ref
+	})
+}
+
+func TestGenerateAllCopiesHooks(t *testing.T) {
+	gen := NewGenerator(Options{})
+	gen.RegisterEnum(reflect.TypeOf(cacheState("")), "Ready", "Failed")
+	var roots []reflect.Type
+	for i := 0; i < 8; i++ {
+		roots = append(roots, reflect.TypeOf(cacheStatusRoot{}))
+	}
+	schemas, err := gen.GenerateAll(roots, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, schema := range schemas {
+		p := property(t, schema, "cacheStatus", "state")
+		if !reflect.DeepEqual(p.Enum, []interface{}{"Ready", "Failed"}) {
+			t.Errorf("root %d: unexpected enum %v", i, p.Enum)
+		}
+	}
+}
+
+// TestGenerateAllSerializesReports checks that the workers of GenerateAll
+// call the reporting callbacks one at a time.
+func TestGenerateAllSerializesReports(t *testing.T) {
+	var inside, overlaps, warnings int32
+	enter := func() {
+		if atomic.AddInt32(&inside, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inside, -1)
+	}
+	gen := NewGenerator(Options{
+		Warn: func(string) {
+			atomic.AddInt32(&warnings, 1)
+			enter()
+		},
+		Progress: func(done, total int, current string) { enter() },
+	})
+	var roots []reflect.Type
+	for i := 0; i < 16; i++ {
+		roots = append(roots, reflect.TypeOf(cacheBrokenUserRoot{}), reflect.TypeOf(cacheStatusRoot{}))
+	}
+	if _, err := gen.GenerateAll(roots, 4); err != nil {
+		t.Fatal(err)
+	}
+	if warnings == 0 {
+		t.Fatal("expected warnings")
+	}
+	if overlaps > 0 {
+		t.Errorf("the callbacks were called concurrently %d times", overlaps)
+	}
+}
+
+func TestGeneratorPruneInvalidation(t *testing.T) {
+	gen := NewGenerator(Options{})
+	gen.RegisterImplementations(reflect.TypeOf((*cacheValue)(nil)).Elem(), reflect.TypeOf(cacheMember{}))
+	for _, root := range []reflect.Type{
+		reflect.TypeOf(cacheHolderRoot{}),
+		reflect.TypeOf(cachePruningRoot{}),
+		reflect.TypeOf(cacheHolderRoot{}),
+	} {
+		schema, err := gen.Generate(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkRefs(t, schema)
+		members := FindDefinitions(schema, "cacheMember")
+		if pruned := root == reflect.TypeOf(cachePruningRoot{}); pruned != (len(members) == 0) {
+			t.Errorf("%s: unexpected member definitions %v", root.Name(), members)
+		}
+	}
+}
+
+func TestGeneratorForgetsFailedRoot(t *testing.T) {
+	gen := NewGenerator(Options{Strict: true})
+	if _, err := gen.Generate(reflect.TypeOf(cacheBrokenRoot{})); err == nil {
+		t.Fatal("Expected the channel field to fail strict generation")
+	}
+	// The definitions of the failed root are not reused as if complete.
+	if _, err := gen.Generate(reflect.TypeOf(cacheBrokenUserRoot{})); err == nil {
+		t.Error("Expected the cached broken definition to fail again")
+	}
+	schema, err := gen.Generate(reflect.TypeOf(cacheStatusRoot{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := property(t, schema, "cacheStatus", "state"); p.JSONDescriptor == nil || p.Type != "string" {
+		t.Errorf("unexpected state: %#v", p)
+	}
+}
@@ -0,0 +1,224 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// Kinds of the changes listed in a changelog.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+	// ChangeRetyped and ChangeRequired are only listed by DiffSchemas.
+	ChangeRetyped  = "retyped"
+	ChangeRequired = "required"
+)
+
+// Change is an entry of a changelog: a definition, or one of its
+// properties if Property is set, that was added, removed or changed.
+type Change struct {
+	Kind       string `json:"kind"`
+	Definition string `json:"definition"`
+	Property   string `json:"property,omitempty"`
+	// Details lists the differences of a changed definition or property,
+	// in the format of CompareJSON, relative to it.
+	Details []string `json:"details,omitempty"`
+	// Breaking explains why clients of the old schema may reject documents
+	// valid against the new one, or the other way around, as found by
+	// DiffSchemas.
+	Breaking string `json:"breaking,omitempty"`
+}
+
+// Changelog lists the changes between two versions of a schema, ordered by
+// definition and property name.
+type Changelog struct {
+	Changes []Change `json:"changes"`
+}
+
+// RootDefinition names the root of the schemas in changelogs.
+const RootDefinition = "(root)"
+
+// CompareSchemas compares two schema documents definition by definition.
+// The properties of definitions present in both are compared one by one,
+// the other keywords of a definition are reported as a change of the
+// definition itself. The root of the schema is compared as a definition
+// named RootDefinition.
+func CompareSchemas(from, to []byte) (*Changelog, error) {
+	var a, b map[string]interface{}
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, err
+	}
+	return compareDocuments(a, b), nil
+}
+
+func compareDocuments(a, b map[string]interface{}) *Changelog {
+	oldDefs, newDefs := schemaDefinitions(a), schemaDefinitions(b)
+	c := &Changelog{Changes: []Change{}}
+	for _, name := range unionKeys(oldDefs, newDefs) {
+		oldDef, inOld := oldDefs[name].(map[string]interface{})
+		newDef, inNew := newDefs[name].(map[string]interface{})
+		switch {
+		case !inNew:
+			c.Changes = append(c.Changes, Change{Kind: ChangeRemoved, Definition: name})
+		case !inOld:
+			c.Changes = append(c.Changes, Change{Kind: ChangeAdded, Definition: name})
+		default:
+			c.Changes = append(c.Changes, compareDefinitions(name, oldDef, newDef)...)
+		}
+	}
+	return c
+}
+
+// schemaDefinitions returns the definitions of a schema document, under
+// definitions or $defs, along with its root.
+func schemaDefinitions(schema map[string]interface{}) map[string]interface{} {
+	defs := map[string]interface{}{}
+	root := map[string]interface{}{}
+	for k, v := range schema {
+		if k == "definitions" || k == "$defs" {
+			if m, ok := v.(map[string]interface{}); ok {
+				for name, def := range m {
+					defs[name] = def
+				}
+			}
+			continue
+		}
+		root[k] = v
+	}
+	defs[RootDefinition] = root
+	return defs
+}
+
+func compareDefinitions(name string, a, b map[string]interface{}) []Change {
+	var changes []Change
+	oldProps, _ := a["properties"].(map[string]interface{})
+	newProps, _ := b["properties"].(map[string]interface{})
+	for _, prop := range unionKeys(oldProps, newProps) {
+		oldProp, inOld := oldProps[prop]
+		newProp, inNew := newProps[prop]
+		switch {
+		case !inNew:
+			changes = append(changes, Change{Kind: ChangeRemoved, Definition: name, Property: prop})
+		case !inOld:
+			changes = append(changes, Change{Kind: ChangeAdded, Definition: name, Property: prop})
+		case !reflect.DeepEqual(oldProp, newProp):
+			details := schemamodel.DiffValues(oldProp, newProp)
+			changes = append(changes, Change{Kind: ChangeChanged, Definition: name, Property: prop, Details: details})
+		}
+	}
+	oldRest, newRest := withoutProperties(a), withoutProperties(b)
+	if !reflect.DeepEqual(oldRest, newRest) {
+		details := schemamodel.DiffValues(oldRest, newRest)
+		// The definition itself comes first.
+		changes = append([]Change{{Kind: ChangeChanged, Definition: name, Details: details}}, changes...)
+	}
+	return changes
+}
+
+func withoutProperties(def map[string]interface{}) map[string]interface{} {
+	rest := make(map[string]interface{}, len(def))
+	for k, v := range def {
+		if k != "properties" {
+			rest[k] = v
+		}
+	}
+	return rest
+}
+
+// unionKeys returns the sorted keys present in a or b.
+func unionKeys(a, b map[string]interface{}) []string {
+	keys := map[string]bool{}
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// WriteMarkdown renders the changelog as a Markdown section, for inclusion
+// in release notes.
+func (c *Changelog) WriteMarkdown(w io.Writer) error {
+	var b bytes.Buffer
+	b.WriteString("## Schema changes\n")
+	if len(c.Changes) == 0 {
+		b.WriteString("\nNo changes.\n")
+		_, err := b.WriteTo(w)
+		return err
+	}
+	sections := []struct {
+		kind, title string
+	}{
+		{ChangeAdded, "Added definitions"},
+		{ChangeRemoved, "Removed definitions"},
+	}
+	for _, s := range sections {
+		var changes []Change
+		for _, change := range c.Changes {
+			if change.Kind == s.kind && len(change.Property) == 0 {
+				changes = append(changes, change)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", s.title)
+		for _, change := range changes {
+			fmt.Fprintf(&b, "- `%s`%s\n", change.Definition, breakingNote(change))
+		}
+	}
+	current := ""
+	for _, change := range c.Changes {
+		if change.Kind != ChangeChanged && len(change.Property) == 0 {
+			continue
+		}
+		if len(current) == 0 {
+			b.WriteString("\n### Changed definitions\n")
+		}
+		if change.Definition != current {
+			current = change.Definition
+			fmt.Fprintf(&b, "\n#### `%s`\n\n", current)
+		}
+		if len(change.Property) == 0 {
+			fmt.Fprintf(&b, "- Changed definition%s\n", breakingNote(change))
+		} else {
+			fmt.Fprintf(&b, "- %s property `%s`%s\n", changeVerbs[change.Kind], change.Property, breakingNote(change))
+		}
+		for _, d := range change.Details {
+			fmt.Fprintf(&b, "  - `%s`\n", d)
+		}
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+var changeVerbs = map[string]string{
+	ChangeAdded:    "Added",
+	ChangeRemoved:  "Removed",
+	ChangeChanged:  "Changed",
+	ChangeRetyped:  "Retyped",
+	ChangeRequired: "Required",
+}
+
+func breakingNote(change Change) string {
+	if len(change.Breaking) == 0 {
+		return ""
+	}
+	return " (**breaking**: " + change.Breaking + ")"
+}
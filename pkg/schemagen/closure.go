@@ -0,0 +1,205 @@
+package schemagen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// PropertiesPolicy selects whether an object accepts properties it does not
+// declare.
+type PropertiesPolicy int
+
+const (
+	// OpenProperties accepts any other property: additionalProperties is
+	// true.
+	OpenProperties PropertiesPolicy = iota
+	// ClosedProperties rejects the properties not declared by the object
+	// itself: additionalProperties is false. It also rejects the
+	// properties of definitions composed through allOf.
+	ClosedProperties
+	// UnevaluatedProperties rejects the properties not declared by the
+	// object nor by the definitions it is composed of: unevaluatedProperties
+	// is false, which requires draft 2019-09 or later.
+	UnevaluatedProperties
+)
+
+// ParsePropertiesPolicy parses the name of a policy: "open", "closed",
+// "unevaluated" or an empty string for open objects.
+func ParsePropertiesPolicy(s string) (PropertiesPolicy, error) {
+	switch s {
+	case "", "open":
+		return OpenProperties, nil
+	case "closed":
+		return ClosedProperties, nil
+	case "unevaluated":
+		return UnevaluatedProperties, nil
+	}
+	return 0, fmt.Errorf("Unknown properties policy %q.", s)
+}
+
+// applyPropertiesPolicies closes the root t of s and its definitions under
+// Options.RootProperties and Options.DefinitionProperties, then the
+// properties markers of the doc comments of their types, then
+// Options.DefaultProperties, and types their undeclared properties with
+// Options.AdditionalProperties. Since additionalProperties: true marks every
+// property as evaluated, it is left out of every object of a schema using
+// UnevaluatedProperties, where it is the default anyway.
+func (g *schemaGenerator) applyPropertiesPolicies(s *JSONSchema, t reflect.Type) {
+	configured := make([]string, 0, len(g.opts.DefinitionProperties))
+	for name := range g.opts.DefinitionProperties {
+		configured = append(configured, name)
+	}
+	sort.Strings(configured)
+	for _, name := range configured {
+		if _, ok := s.Definitions[name]; !ok {
+			g.warnf("Unknown definition %s of the properties policies.", name)
+		}
+	}
+	policies := make(map[string]PropertiesPolicy)
+	for k := range g.types {
+		name := g.qualifiedName(k)
+		if def, ok := s.Definitions[name]; ok && def.JSONObjectDescriptor != nil {
+			policies[name] = g.propertiesPolicy(k, name, g.opts.DefaultProperties)
+		}
+	}
+	rootPolicy := g.opts.RootProperties
+	if rootPolicy == OpenProperties && len(t.Name()) > 0 {
+		rootPolicy = g.propertiesPolicy(t, g.qualifiedName(t), g.opts.DefaultProperties)
+	}
+
+	unevaluated := rootPolicy == UnevaluatedProperties
+	names := make([]string, 0, len(policies))
+	for name, policy := range policies {
+		names = append(names, name)
+		unevaluated = unevaluated || policy == UnevaluatedProperties
+	}
+	sort.Strings(names)
+	if unevaluated {
+		if g.opts.Draft != Draft2020 {
+			g.warnf("unevaluatedProperties is only supported by JSON Schema 2019-09 and later drafts.")
+		}
+		mapSchema(s, withoutOpenProperties)
+		s.JSONObjectDescriptor = openPropertiesLeftOut(s.JSONObjectDescriptor)
+	}
+
+	s.JSONObjectDescriptor = closeObject(s.JSONObjectDescriptor, rootPolicy)
+	for _, name := range names {
+		def := s.Definitions[name]
+		policy := policies[name]
+		if policy == ClosedProperties && def.JSONCombinatorDescriptor != nil && len(def.AllOf) > 0 {
+			g.warnf("Definition %s is composed through allOf, closing it with additionalProperties rejects the properties it inherits; use the unevaluated policy instead.", name)
+		}
+		def.JSONObjectDescriptor = closeObject(def.JSONObjectDescriptor, policy)
+		s.Definitions[name] = def
+	}
+
+	typed := make([]string, 0, len(g.opts.AdditionalProperties))
+	for name := range g.opts.AdditionalProperties {
+		typed = append(typed, name)
+	}
+	sort.Strings(typed)
+	for _, name := range typed {
+		schema := g.opts.AdditionalProperties[name]
+		if ref, ok := schemamodel.LocalDefinition(referenceOf(schema)); ok {
+			if _, defined := s.Definitions[ref]; !defined {
+				g.warnf("Unknown definition %s of the additional properties of %s.", ref, name)
+			}
+		}
+		if def, ok := s.Definitions[name]; ok {
+			def.JSONObjectDescriptor = typeAdditionalProperties(def.JSONObjectDescriptor, schema)
+			s.Definitions[name] = def
+		} else if len(t.Name()) > 0 && name == g.qualifiedName(t) {
+			s.JSONObjectDescriptor = typeAdditionalProperties(s.JSONObjectDescriptor, schema)
+		} else {
+			g.warnf("Unknown definition %s of the additional properties.", name)
+		}
+	}
+}
+
+// propertiesPolicy returns the policy of the definition name of t: the one
+// of Options.DefinitionProperties, or else of the properties marker of the
+// doc comment of t, or else policy.
+func (g *schemaGenerator) propertiesPolicy(t reflect.Type, name string, policy PropertiesPolicy) PropertiesPolicy {
+	if p, ok := g.opts.DefinitionProperties[name]; ok {
+		return p
+	}
+	if len(t.PkgPath()) == 0 {
+		return policy
+	}
+	if marker, ok := g.packageDocs(t).properties[t.Name()]; ok {
+		if p, err := ParsePropertiesPolicy(marker); err == nil {
+			return p
+		}
+		g.warnf("Unknown properties policy %q in the doc comment of %s.", marker, t.Name())
+	}
+	return policy
+}
+
+// typeAdditionalProperties returns a copy of o whose undeclared properties
+// are described by schema, creating it if needed.
+func typeAdditionalProperties(o *JSONObjectDescriptor, schema JSONPropertyDescriptor) *JSONObjectDescriptor {
+	desc := JSONObjectDescriptor{}
+	if o != nil {
+		desc = *o
+	}
+	desc.AdditionalProperties = schema
+	desc.UnevaluatedProperties = nil
+	return &desc
+}
+
+// closeObject returns a copy of o applying policy, creating it if needed.
+func closeObject(o *JSONObjectDescriptor, policy PropertiesPolicy) *JSONObjectDescriptor {
+	if policy == OpenProperties {
+		return o
+	}
+	desc := JSONObjectDescriptor{}
+	if o != nil {
+		desc = *o
+	}
+	switch policy {
+	case ClosedProperties:
+		desc.AdditionalProperties = false
+	case UnevaluatedProperties:
+		desc.UnevaluatedProperties = false
+	}
+	return &desc
+}
+
+func withoutOpenProperties(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	p.JSONObjectDescriptor = openPropertiesLeftOut(p.JSONObjectDescriptor)
+	return p
+}
+
+// openPropertiesLeftOut returns a copy of o without additionalProperties if
+// it is true.
+func openPropertiesLeftOut(o *JSONObjectDescriptor) *JSONObjectDescriptor {
+	if o == nil || o.AdditionalProperties != true {
+		return o
+	}
+	desc := *o
+	desc.AdditionalProperties = nil
+	return &desc
+}
+
+// additionalPropertiesSchema returns the schema named by value in
+// Config.AdditionalProperties: a JSON type, or else a reference to the
+// definition of that name.
+func additionalPropertiesSchema(value string) JSONPropertyDescriptor {
+	switch value {
+	case "string", "boolean", "integer", "number", "object":
+		return JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: value}}
+	}
+	return JSONPropertyDescriptor{
+		JSONReferenceDescriptor: &JSONReferenceDescriptor{Reference: definitionsPrefix + value},
+	}
+}
+
+func referenceOf(p JSONPropertyDescriptor) string {
+	if p.JSONReferenceDescriptor == nil {
+		return ""
+	}
+	return p.Reference
+}
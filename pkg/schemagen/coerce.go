@@ -0,0 +1,104 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Coercion declares that a field documented with a JSON type is also
+// transmitted as a string by legacy clients, e.g. ports sent as "8080".
+type Coercion struct {
+	// Type is the documented JSON type: integer, number or boolean.
+	Type string `yaml:"type"`
+	// JavaType is the canonical Java type of the field. It defaults to
+	// the one mapped to the kind of the documented type.
+	JavaType string `yaml:"javaType"`
+}
+
+// coercionPatterns match the string encodings of the coercible types.
+var coercionPatterns = map[string]string{
+	"integer": "^-?[0-9]+$",
+	"number":  "^-?[0-9]+(\\.[0-9]+)?([eE][-+]?[0-9]+)?$",
+	"boolean": "^(true|false)$",
+}
+
+var coercionKinds = map[string]reflect.Kind{
+	"integer": reflect.Int,
+	"number":  reflect.Float64,
+	"boolean": reflect.Bool,
+}
+
+// coercedDescriptor describes a field declared with c as either its
+// documented type or a string encoding it, keeping the canonical Java type
+// outside of the oneOf.
+func (g *schemaGenerator) coercedDescriptor(c Coercion) (JSONPropertyDescriptor, bool) {
+	pattern, ok := coercionPatterns[c.Type]
+	if !ok {
+		return JSONPropertyDescriptor{}, false
+	}
+	javaType := c.JavaType
+	if len(javaType) == 0 {
+		javaType = g.kinds[coercionKinds[c.Type]].JavaType
+	}
+	return JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf: []JSONPropertyDescriptor{
+				{JSONDescriptor: &JSONDescriptor{Type: c.Type}},
+				{JSONDescriptor: &JSONDescriptor{Type: "string", Pattern: pattern}},
+			},
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: javaType,
+		},
+	}, true
+}
+
+// quotedPattern matches the strings encoding/json quotes string fields
+// tagged with the string option in, e.g. "\"web\"".
+const quotedPattern = `^".*"$`
+
+// applyStringOption describes a field tagged with the string json option,
+// e.g. json:"port,string", as the string encoding/json writes its value
+// in: a string matching the pattern of its JSON type, whose enum, default
+// and examples are quoted alike. Its Java type, if any, is kept. It
+// returns false if the field is not a number, a
+// boolean or a string, which the option does not apply to.
+func applyStringOption(prop *JSONPropertyDescriptor) bool {
+	if prop.JSONDescriptor == nil {
+		return false
+	}
+	pattern := coercionPatterns[prop.Type]
+	if prop.Type == "string" {
+		pattern = quotedPattern
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	desc := *prop.JSONDescriptor
+	desc.Type, desc.Format, desc.Pattern = "string", "", pattern
+	desc.Minimum, desc.Maximum = nil, nil
+	desc.ExclusiveMinimum, desc.ExclusiveMaximum = false, false
+	if prop.Type == "string" {
+		desc.MinLength, desc.MaxLength = nil, nil
+	}
+	desc.Enum = quoteValues(desc.Enum)
+	desc.Examples = quoteValues(desc.Examples)
+	if desc.Default != nil {
+		desc.Default = quoteValues([]interface{}{desc.Default})[0]
+	}
+	prop.JSONDescriptor = &desc
+	return true
+}
+
+// quoteValues returns the JSON texts of values.
+func quoteValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	quoted := make([]interface{}, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		quoted[i] = string(b)
+	}
+	return quoted
+}
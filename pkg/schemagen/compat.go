@@ -0,0 +1,277 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Diff compares two generated schemas like DiffSchemas.
+func Diff(old, new *JSONSchema) (*Changelog, error) {
+	from, err := json.Marshal(old)
+	if err != nil {
+		return nil, err
+	}
+	to, err := json.Marshal(new)
+	if err != nil {
+		return nil, err
+	}
+	return DiffSchemas(from, to)
+}
+
+// DiffSchemas compares two schema documents like CompareSchemas, for
+// compatibility checks: properties whose type changed are listed as
+// retyped, properties that became required are listed as required, and
+// the changes breaking clients of the old schema, removed definitions and
+// properties, retyped properties and newly required ones, including added
+// required properties, are flagged. Types include their format and the
+// values of maps, so that int32 becoming int64 is a retype, and narrowed
+// enums, tighter bounds and breaking changes of inline objects are flagged
+// too.
+func DiffSchemas(from, to []byte) (*Changelog, error) {
+	var a, b map[string]interface{}
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, err
+	}
+	c := compareDocuments(a, b)
+	oldDefs, newDefs := schemaDefinitions(a), schemaDefinitions(b)
+	var changes []Change
+	for _, change := range c.Changes {
+		switch {
+		case change.Kind == ChangeRemoved && len(change.Property) == 0:
+			change.Breaking = "definition removed"
+		case change.Kind == ChangeRemoved:
+			change.Breaking = "property removed"
+		case change.Kind == ChangeAdded && len(change.Property) > 0:
+			def, _ := newDefs[change.Definition].(map[string]interface{})
+			for _, prop := range requiredProperties(def) {
+				if prop == change.Property {
+					change.Breaking = "required property added"
+				}
+			}
+		case change.Kind == ChangeChanged && len(change.Property) > 0:
+			oldProp := propertySchema(oldDefs, change.Definition, change.Property)
+			newProp := propertySchema(newDefs, change.Definition, change.Property)
+			if oldType, newType := describeType(oldProp), describeType(newProp); oldType != newType {
+				change.Kind = ChangeRetyped
+				change.Breaking = fmt.Sprintf("type changed from %s to %s", oldType, newType)
+			} else {
+				change.Breaking = narrowed(oldProp, newProp)
+			}
+		case change.Kind == ChangeChanged:
+			oldDef, _ := oldDefs[change.Definition].(map[string]interface{})
+			newDef, _ := newDefs[change.Definition].(map[string]interface{})
+			if oldType, newType := describeType(oldDef), describeType(newDef); oldType != newType {
+				change.Breaking = fmt.Sprintf("type changed from %s to %s", oldType, newType)
+			} else {
+				// Properties, and whether they are required, are
+				// compared on their own.
+				oldRest, newRest := withoutProperties(oldDef), withoutProperties(newDef)
+				delete(oldRest, "required")
+				delete(newRest, "required")
+				change.Breaking = narrowed(oldRest, newRest)
+			}
+		}
+		changes = append(changes, change)
+		if change.Kind == ChangeChanged && len(change.Property) == 0 {
+			// Newly required properties follow their definition.
+			changes = append(changes, newlyRequired(change.Definition, oldDefs, newDefs)...)
+		}
+	}
+	c.Changes = changes
+	return c, nil
+}
+
+// Breaking returns the breaking changes of the changelog.
+func (c *Changelog) Breaking() []Change {
+	var breaking []Change
+	for _, change := range c.Changes {
+		if len(change.Breaking) > 0 {
+			breaking = append(breaking, change)
+		}
+	}
+	return breaking
+}
+
+// newlyRequired lists the properties of a definition that are required by
+// the new schema but were not by the old one. Properties added along are
+// left out, their addition being flagged already.
+func newlyRequired(name string, oldDefs, newDefs map[string]interface{}) []Change {
+	oldDef, _ := oldDefs[name].(map[string]interface{})
+	newDef, _ := newDefs[name].(map[string]interface{})
+	oldProps, _ := oldDef["properties"].(map[string]interface{})
+	wasRequired := map[string]bool{}
+	for _, prop := range requiredProperties(oldDef) {
+		wasRequired[prop] = true
+	}
+	var changes []Change
+	for _, prop := range requiredProperties(newDef) {
+		if _, ok := oldProps[prop]; !ok || wasRequired[prop] {
+			continue
+		}
+		changes = append(changes, Change{
+			Kind:       ChangeRequired,
+			Definition: name,
+			Property:   prop,
+			Breaking:   "property now required",
+		})
+	}
+	return changes
+}
+
+func requiredProperties(def map[string]interface{}) []string {
+	list, _ := def["required"].([]interface{})
+	var props []string
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			props = append(props, s)
+		}
+	}
+	return props
+}
+
+// propertySchema returns the schema of a property of a definition.
+func propertySchema(defs map[string]interface{}, name, prop string) map[string]interface{} {
+	def, _ := defs[name].(map[string]interface{})
+	props, _ := def["properties"].(map[string]interface{})
+	schema, _ := props[prop].(map[string]interface{})
+	return schema
+}
+
+// describeType describes the type of a schema: its type keyword and
+// format, or its reference, along with the type of its items for arrays
+// and of its values for maps.
+func describeType(schema map[string]interface{}) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref
+	}
+	var t string
+	switch v := schema["type"].(type) {
+	case string:
+		t = v
+	case []interface{}:
+		var types []string
+		for _, e := range v {
+			types = append(types, fmt.Sprint(e))
+		}
+		t = strings.Join(types, "|")
+	default:
+		t = "any"
+	}
+	if format, ok := schema["format"].(string); ok {
+		t += "(" + format + ")"
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		t += " of " + describeType(items)
+	}
+	if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+		t += " with values of " + describeType(values)
+	}
+	return t
+}
+
+// lowerBounds and upperBounds are the keywords whose value documents may
+// not go below, respectively above.
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+// narrowed explains why documents valid against the old schema may be
+// rejected by the new one of the same type: enums losing values, tighter
+// bounds or patterns, and removed, retyped, narrowed or newly required
+// properties of inline objects, also in the items of arrays and the values
+// of maps. It returns an empty string if none is found.
+func narrowed(old, new map[string]interface{}) string {
+	if newEnum, ok := new["enum"].([]interface{}); ok {
+		oldEnum, ok := old["enum"].([]interface{})
+		if !ok {
+			return "enum added"
+		}
+		for _, v := range oldEnum {
+			if !containsValue(newEnum, v) {
+				return fmt.Sprintf("enum value %s removed", compactJSON(v))
+			}
+		}
+	}
+	for _, bound := range lowerBounds {
+		if reason := tightened(bound, old[bound], new[bound], 1); len(reason) > 0 {
+			return reason
+		}
+	}
+	for _, bound := range upperBounds {
+		if reason := tightened(bound, old[bound], new[bound], -1); len(reason) > 0 {
+			return reason
+		}
+	}
+	if pattern, ok := new["pattern"].(string); ok && pattern != old["pattern"] {
+		return fmt.Sprintf("pattern changed to %s", pattern)
+	}
+	if new["additionalProperties"] == false && old["additionalProperties"] != false {
+		return "additional properties disallowed"
+	}
+
+	oldProps, _ := old["properties"].(map[string]interface{})
+	newProps, _ := new["properties"].(map[string]interface{})
+	for _, prop := range unionKeys(oldProps, nil) {
+		if _, ok := newProps[prop]; !ok {
+			return fmt.Sprintf("property %s removed", prop)
+		}
+		oldProp, _ := oldProps[prop].(map[string]interface{})
+		newProp, _ := newProps[prop].(map[string]interface{})
+		if oldType, newType := describeType(oldProp), describeType(newProp); oldType != newType {
+			return fmt.Sprintf("property %s: type changed from %s to %s", prop, oldType, newType)
+		}
+		if reason := narrowed(oldProp, newProp); len(reason) > 0 {
+			return fmt.Sprintf("property %s: %s", prop, reason)
+		}
+	}
+	wasRequired := map[string]bool{}
+	for _, prop := range requiredProperties(old) {
+		wasRequired[prop] = true
+	}
+	for _, prop := range requiredProperties(new) {
+		if !wasRequired[prop] {
+			return fmt.Sprintf("property %s now required", prop)
+		}
+	}
+
+	for _, nested := range []struct{ keyword, name string }{{"items", "items"}, {"additionalProperties", "values"}} {
+		oldNested, _ := old[nested.keyword].(map[string]interface{})
+		newNested, ok := new[nested.keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if reason := narrowed(oldNested, newNested); len(reason) > 0 {
+			return nested.name + ": " + reason
+		}
+	}
+	return ""
+}
+
+// tightened explains how a numeric bound got tighter, sign being 1 for
+// lower bounds and -1 for upper ones. Boolean exclusive bounds, as of
+// draft 4, tighten the bound they apply to when they become true.
+func tightened(keyword string, old, new interface{}, sign float64) string {
+	if b, ok := new.(bool); ok {
+		if b && old != true {
+			return keyword + " made exclusive"
+		}
+		return ""
+	}
+	n, ok := new.(float64)
+	if !ok {
+		return ""
+	}
+	o, ok := old.(float64)
+	switch {
+	case !ok:
+		return fmt.Sprintf("%s %v added", keyword, n)
+	case (n-o)*sign > 0:
+		return fmt.Sprintf("%s changed from %v to %v", keyword, o, n)
+	}
+	return ""
+}
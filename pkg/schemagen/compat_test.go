@@ -0,0 +1,78 @@
+package schemagen
+
+import (
+	"strings"
+	"testing"
+)
+
+const compatBase = `{
+  "type": "object",
+  "properties": {"pod": {"$ref": "#/definitions/Pod"}},
+  "definitions": {
+    "Pod": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {
+        "name": {"type": "string"},
+        "replicas": {"type": "integer", "format": "int32", "minimum": 0, "maximum": 10},
+        "phase": {"type": "string", "enum": ["Pending", "Running"]},
+        "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+        "ports": {"type": "array", "items": {"type": "integer", "format": "int32"}},
+        "settings": {"type": "object", "properties": {"verbose": {"type": "boolean"}, "level": {"type": "integer"}}}
+      }
+    },
+    "Volume": {"type": "object", "properties": {"path": {"type": "string"}}},
+    "Policy": {"type": "string", "enum": ["Always", "Never"]}
+  }
+}`
+
+// TestDiffSchemasBreaking checks that every class of breaking change is
+// flagged, and that compatible changes are not.
+func TestDiffSchemasBreaking(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		old, new string
+		breaking string
+	}{
+		{"definition removed", `"Volume": {"type": "object", "properties": {"path": {"type": "string"}}}`, `"Other": {"type": "object"}`, "definition removed"},
+		{"property removed", `"name": {"type": "string"},`, ``, "property removed"},
+		{"type changed", `"name": {"type": "string"}`, `"name": {"type": "integer"}`, "type changed from string to integer"},
+		{"format changed", `"format": "int32", "minimum"`, `"format": "int64", "minimum"`, "type changed from integer(int32) to integer(int64)"},
+		{"map value retyped", `"additionalProperties": {"type": "string"}`, `"additionalProperties": {"type": "integer"}`, "type changed from object with values of string to object with values of integer"},
+		{"items retyped", `"items": {"type": "integer", "format": "int32"}`, `"items": {"type": "string"}`, "type changed from array of integer(int32) to array of string"},
+		{"required property added", `"name": {"type": "string"},`, `"name": {"type": "string"}, "uid": {"type": "string"},`, "required property added"},
+		{"property now required", `"required": ["name"]`, `"required": ["name", "phase"]`, "property now required"},
+		{"inline property removed", `"level": {"type": "integer"}`, `"other": {"type": "integer"}`, "property level removed"},
+		{"inline property retyped", `"verbose": {"type": "boolean"}`, `"verbose": {"type": "string"}`, "property verbose: type changed from boolean to string"},
+		{"enum narrowed", `"enum": ["Pending", "Running"]`, `"enum": ["Pending"]`, `enum value "Running" removed`},
+		{"enum definition narrowed", `"enum": ["Always", "Never"]`, `"enum": ["Always"]`, `enum value "Never" removed`},
+		{"minimum raised", `"minimum": 0`, `"minimum": 1`, "minimum changed from 0 to 1"},
+		{"maximum lowered", `"maximum": 10`, `"maximum": 5`, "maximum changed from 10 to 5"},
+		{"maximum made exclusive", `"maximum": 10`, `"maximum": 10, "exclusiveMaximum": true`, "exclusiveMaximum made exclusive"},
+		{"enum widened", `"enum": ["Pending", "Running"]`, `"enum": ["Pending", "Running", "Failed"]`, ""},
+		{"maximum raised", `"maximum": 10`, `"maximum": 20`, ""},
+		{"optional property added", `"name": {"type": "string"},`, `"name": {"type": "string"}, "uid": {"type": "string"},`, ""},
+	} {
+		old := compatBase
+		if !strings.Contains(old, test.old) {
+			t.Fatalf("%s: %s is not part of the base schema", test.name, test.old)
+		}
+		new := strings.Replace(old, test.old, test.new, 1)
+		if test.name == "required property added" {
+			new = strings.Replace(new, `"required": ["name"]`, `"required": ["name", "uid"]`, 1)
+		}
+		c, err := DiffSchemas([]byte(old), []byte(new))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		breaking := c.Breaking()
+		switch {
+		case len(test.breaking) == 0 && len(breaking) > 0:
+			t.Errorf("%s: expected no breaking change, got %+v", test.name, breaking)
+		case len(test.breaking) > 0 && len(breaking) != 1:
+			t.Errorf("%s: expected one breaking change, got %+v", test.name, breaking)
+		case len(test.breaking) > 0 && breaking[0].Breaking != test.breaking:
+			t.Errorf("%s: expected %q, got %q", test.name, test.breaking, breaking[0].Breaking)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ComponentsMode selects whether the definitions of a schema are mirrored
+// into components.schemas, so that one document serves both JSON Schema
+// consumers and OpenAPI pipelines.
+type ComponentsMode int
+
+const (
+	// NoComponents only emits definitions.
+	NoComponents ComponentsMode = iota
+	// DefinitionRefs mirrors the definitions into components.schemas,
+	// every reference pointing at the definitions.
+	DefinitionRefs
+	// ComponentRefs mirrors the definitions into components.schemas,
+	// every reference pointing at the components.
+	ComponentRefs
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// ParseComponentsMode parses the target of the references of a schema
+// whose definitions are mirrored into components.schemas: "definitions" or
+// "components". An empty string disables the mirror.
+func ParseComponentsMode(s string) (ComponentsMode, error) {
+	switch s {
+	case "":
+		return NoComponents, nil
+	case "definitions":
+		return DefinitionRefs, nil
+	case "components":
+		return ComponentRefs, nil
+	}
+	return 0, fmt.Errorf("Unknown components mode %q.", s)
+}
+
+// applyComponents mirrors the definitions of s into components.schemas
+// under Options.Components. The mirrored schemas are left without Java
+// hints, like the ones of GenerateOpenAPI, while the definitions keep them
+// for jsonschema2pojo.
+func (g *schemaGenerator) applyComponents(s *JSONSchema) {
+	if g.opts.Components == NoComponents || s.Definitions == nil {
+		return
+	}
+	prefix := definitionsPrefix
+	if g.opts.Components == ComponentRefs {
+		prefix = componentsPrefix
+		RewriteRefs(s, func(ref string) string {
+			if strings.HasPrefix(ref, definitionsPrefix) {
+				return componentsPrefix + strings.TrimPrefix(ref, definitionsPrefix)
+			}
+			return ref
+		})
+	}
+	s.Components = &OpenAPIComponents{
+		Schemas: make(map[string]JSONPropertyDescriptor, len(s.Definitions)),
+	}
+	for name, def := range s.Definitions {
+		s.Components.Schemas[name] = openAPISchema(def, prefix, OpenAPI3)
+	}
+}
@@ -0,0 +1,46 @@
+package schemagen
+
+// composition records, in ComposeEmbedded mode, the definitions of the
+// structs embedded by a struct and the properties it inherits from them.
+type composition struct {
+	bases     []JSONPropertyDescriptor
+	inherited map[string]bool
+	// properties holds all the properties of the struct, flattened, for
+	// the structs embedding it in turn and for the root schema.
+	properties map[string]JSONPropertyDescriptor
+	required   []string
+}
+
+// composedDescriptor describes a struct, whose flattened object descriptor
+// is obj, as the allOf of the definitions of its embedded structs and of an
+// object holding its own properties, see Options.ComposeEmbedded.
+func composedDescriptor(c *composition, obj *JSONObjectDescriptor, javaType string) JSONPropertyDescriptor {
+	own := *obj
+	own.Properties = make(map[string]JSONPropertyDescriptor)
+	for k, v := range obj.Properties {
+		if !c.inherited[k] {
+			own.Properties[k] = v
+		}
+	}
+	own.Required = nil
+	for _, k := range obj.Required {
+		if !c.inherited[k] {
+			own.Required = append(own.Required, k)
+		}
+	}
+	allOf := append([]JSONPropertyDescriptor{}, c.bases...)
+	allOf = append(allOf, JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type: "object",
+		},
+		JSONObjectDescriptor: &own,
+	})
+	return JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			AllOf: allOf,
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: javaType,
+		},
+	}
+}
@@ -0,0 +1,638 @@
+package schemagen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/v1/yaml"
+)
+
+// Config is the file based part of a generation setup, read from YAML (or
+// JSON) by the command line tools. Settings that can only be expressed in
+// Go, such as hooks, stay with the program embedding the generator.
+type Config struct {
+	// Output is the file the schema is written to.
+	Output string `yaml:"output"`
+	// Format is "json" or "yaml", see ParseOutputFormat.
+	Format string `yaml:"format"`
+	// Split, if set, is the directory receiving one schema per API
+	// group/version plus the index named by Index.
+	Split string `yaml:"split"`
+	Index string `yaml:"index"`
+	// CRD writes the structural schema of the root instead of the schema,
+	// see StructuralSchema, and OpenAPI an OpenAPI document of this
+	// version, see ParseOpenAPIVersion.
+	CRD     bool   `yaml:"crd"`
+	OpenAPI string `yaml:"openapi"`
+
+	// Profile is "java" or "helm", see ParseProfile.
+	Profile string `yaml:"profile"`
+
+	// Profiles are named sets of settings, such as "k8s-crd" or "docs",
+	// merged over the other ones when selected with LoadConfigProfile, so
+	// that a single configuration drives every consumer of the schemas.
+	Profiles map[string]interface{} `yaml:"profiles"`
+
+	// Retention is "all" or "reachable", see ParseRetention.
+	Retention string `yaml:"retention"`
+
+	// Naming is "path", "short" or "lastSegments", see
+	// ParseNamingStrategy.
+	Naming string `yaml:"naming"`
+
+	// PropertyNaming is "go", "camelCase", "snake_case" or "kebab-case",
+	// see ParsePropertyNaming, applied to the names of json tags too if
+	// RenameTaggedProperties is set.
+	PropertyNaming         string `yaml:"propertyNaming"`
+	RenameTaggedProperties bool   `yaml:"renameTaggedProperties"`
+
+	// AnyItems is "{}" or "true", see ParseAnyItemsStyle, and ItemTypes
+	// declares the items of fields, see Options.ItemTypes.
+	AnyItems  string            `yaml:"anyItems"`
+	ItemTypes map[string]string `yaml:"itemTypes"`
+
+	// TypeFormats maps named types to the name of their format, or to
+	// comma-separated formats any of which their values have, see
+	// Options.TypeFormats. FieldFormats extends the formats guessed from
+	// property names, see Options.FieldFormats.
+	TypeFormats  map[string]string `yaml:"typeFormats"`
+	FieldFormats map[string]string `yaml:"fieldFormats"`
+
+	// Components is "definitions" or "components", see
+	// ParseComponentsMode.
+	Components string `yaml:"components"`
+
+	// PackageAliases identifies the packages at its keys with the ones at
+	// its values, see Options.PackageAliases.
+	PackageAliases map[string]string `yaml:"packageAliases"`
+
+	// RootProperties and DefinitionProperties, keyed by definition name,
+	// are "open", "closed" or "unevaluated", see ParsePropertiesPolicy.
+	RootProperties       string            `yaml:"rootProperties"`
+	DefinitionProperties map[string]string `yaml:"definitionProperties"`
+	// DefaultProperties is the policy of the other objects, see
+	// Options.DefaultProperties.
+	DefaultProperties string `yaml:"defaultProperties"`
+	// AdditionalProperties describes the undeclared properties of
+	// definitions, keyed by definition name, as a JSON type such as
+	// "string" or the name of a definition, see
+	// Options.AdditionalProperties.
+	AdditionalProperties map[string]string `yaml:"additionalProperties"`
+
+	// Titles is one of "asIs", "sentence" or "words", see
+	// ParseTitleStyle.
+	Titles string `yaml:"titles"`
+	// Nullable is one of "swagger2", "openapi3", "both", "oneOf" or
+	// "typeArray".
+	Nullable                string `yaml:"nullable"`
+	WrapRefs                bool   `yaml:"wrapRefs"`
+	StandardOnly            bool   `yaml:"standardOnly"`
+	JavaTypeKeyword         string `yaml:"javaTypeKeyword"`
+	NestDefinitions         bool   `yaml:"nestDefinitions"`
+	InlineReferences        bool   `yaml:"inlineReferences"`
+	ContentAddressed        bool   `yaml:"contentAddressed"`
+	CatchAllMaps            bool   `yaml:"catchAllMaps"`
+	NonEmptyRequiredStrings bool   `yaml:"nonEmptyRequiredStrings"`
+	PatternProperties       bool   `yaml:"patternProperties"`
+	ComposeEmbedded         bool   `yaml:"composeEmbedded"`
+	Required                bool   `yaml:"required"`
+	DocComments             bool   `yaml:"docComments"`
+	Stamped                 bool   `yaml:"stamped"`
+	Strict                  bool   `yaml:"strict"`
+	Audit                   bool   `yaml:"audit"`
+	Debug                   bool   `yaml:"debug"`
+	GoPackages              bool   `yaml:"goPackages"`
+	ProtoFields             bool   `yaml:"protoFields"`
+	ByteArraysAsStrings     bool   `yaml:"byteArraysAsStrings"`
+	InternTypes             bool   `yaml:"internTypes"`
+	DisambiguateNames       bool   `yaml:"disambiguateNames"`
+	EnumConstants           bool   `yaml:"enumConstants"`
+	FormatHeuristics        bool   `yaml:"formatHeuristics"`
+
+	// Draft2020 is the path of a copy of the schema in JSON Schema draft
+	// 2020-12, see DraftEmitter.
+	Draft2020 string `yaml:"draft2020"`
+
+	// JavaImports is the path of a sidecar file listing the Java classes
+	// referenced by each definition, see JavaImports.
+	JavaImports string `yaml:"javaImports"`
+
+	// Usages is the path of a sidecar file listing the JSON pointers
+	// referring to each definition, see Usages.
+	Usages string `yaml:"usages"`
+
+	// UISchema is the path of a companion file holding the JSON Forms UI
+	// schemas of the definitions, see GenerateUISchemas.
+	UISchema string `yaml:"uiSchema"`
+
+	// TypeScript is the path of a companion file holding the TypeScript
+	// declarations of the definitions, see TypeScriptEmitter.
+	TypeScript string `yaml:"typescript"`
+
+	// Docs is the path of a companion file holding the reference
+	// documentation of the schema, in HTML if it ends with .html or .htm
+	// and in Markdown otherwise, see DocsEmitter.
+	Docs string `yaml:"docs"`
+
+	// Proto is the path of a companion proto3 file holding messages for
+	// the root and the definitions, see ProtoEmitter, in the package
+	// ProtoPackage. Their field numbers are kept in the file named by
+	// ProtoNumbersName.
+	Proto        string `yaml:"proto"`
+	ProtoPackage string `yaml:"protoPackage"`
+
+	// Discriminator is the property telling implementations of interfaces
+	// apart, see Options.Discriminator.
+	Discriminator string `yaml:"discriminator"`
+
+	// TimeJavaType is the Java type of date-time strings, see
+	// Options.TimeJavaType.
+	TimeJavaType string `yaml:"timeJavaType"`
+
+	// BaseURI, IDTemplate and SchemaURI set the ids and $schema of the
+	// schemas, see Options.BaseURI.
+	BaseURI    string `yaml:"baseURI"`
+	IDTemplate string `yaml:"idTemplate"`
+	SchemaURI  string `yaml:"schemaURI"`
+
+	// Draft is "draft-04", "draft-07" or "2020-12", see ParseDraft.
+	Draft string `yaml:"draft"`
+
+	// License is emitted as the x-license extension of the schemas.
+	License string `yaml:"license"`
+
+	// Coercions declare legacy fields also transmitted as strings, see
+	// Options.Coercions.
+	Coercions map[string]Coercion `yaml:"coercions"`
+
+	// JavaHints add jsonschema2pojo hints to single properties, see
+	// Options.JavaHints.
+	JavaHints map[string]JavaHints `yaml:"javaHints"`
+
+	// Labels tag definitions and LabelFilter only keeps the ones carrying
+	// one of its labels, see Options.Labels.
+	Labels      map[string][]string `yaml:"labels"`
+	LabelFilter []string            `yaml:"labelFilter"`
+
+	// JavaAcronyms are spelled in capitals in the Java names of fields,
+	// see Options.JavaAcronyms.
+	JavaAcronyms []string `yaml:"javaAcronyms"`
+
+	// TagOptions registers the effects of json tag options, see
+	// Options.TagOptions.
+	TagOptions map[string]TagOption `yaml:"tagOptions"`
+
+	// TagKeys lists the struct tags naming fields, see Options.TagKeys.
+	TagKeys []string `yaml:"tagKeys"`
+
+	// Templates maps the names of custom emitters to the text/template
+	// files they render, see TemplateEmitter.
+	Templates map[string]string `yaml:"templates"`
+
+	// RequiredOverrides marks properties, keyed by "<definition
+	// name>.<property>", required or not, see Options.Required.
+	RequiredOverrides map[string]bool `yaml:"requiredOverrides"`
+
+	// FieldRules excludes, renames or makes opaque fields, see
+	// Options.FieldRules.
+	FieldRules map[string]FieldRule `yaml:"fieldRules"`
+
+	// Overrides tweaks descriptors by JSON pointer, see Options.Overrides.
+	Overrides map[string]PropertyOverride `yaml:"overrides"`
+
+	// Abstract names the definitions marked x-abstract, see
+	// Options.Abstract.
+	Abstract []string `yaml:"abstract"`
+
+	// GroupVersionKinds lists the Kubernetes resource types of
+	// definitions, see Options.GroupVersionKinds.
+	GroupVersionKinds map[string][]GroupVersionKind `yaml:"groupVersionKinds"`
+
+	// Annotations is the path of a sidecar file of definition
+	// annotations, see LoadAnnotations.
+	Annotations string `yaml:"annotations"`
+
+	// Frozen names the definitions whose shape must not change, checked
+	// against the lock file at FreezeLock, see Options.Frozen.
+	Frozen     []string `yaml:"frozen"`
+	FreezeLock string   `yaml:"freezeLock"`
+
+	// ProvenanceLock is the path of the lock file recording the provenance
+	// of the schema, see Provenance.
+	ProvenanceLock string `yaml:"provenanceLock"`
+
+	// Packages describes Go packages in addition to, or in place of, the
+	// ones of the program embedding the generator, matched by GoPackage.
+	Packages []PackageDescriptor `yaml:"packages"`
+
+	// TypeMap describes the types named by its keys as the types named by
+	// its values, see Options.TypeMap. Types are named by import path and
+	// name, e.g. "github.com/GoogleCloudPlatform/kubernetes/pkg/util.Time",
+	// or by the name of a predeclared type such as "string", and are looked
+	// up with the Resolver of the options.
+	TypeMap map[string]string `yaml:"typeMap"`
+}
+
+// SplitTypeName splits the name of a type in a configuration into the
+// import path of its package and its name. The import path of predeclared
+// types is empty.
+func SplitTypeName(name string) (pkgPath, typeName string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 || i < strings.LastIndex(name, "/") {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+var predeclaredTypes = map[string]reflect.Type{
+	"bool":    reflect.TypeOf(false),
+	"string":  reflect.TypeOf(""),
+	"int":     reflect.TypeOf(int(0)),
+	"int32":   reflect.TypeOf(int32(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"uint":    reflect.TypeOf(uint(0)),
+	"uint32":  reflect.TypeOf(uint32(0)),
+	"uint64":  reflect.TypeOf(uint64(0)),
+	"float32": reflect.TypeOf(float32(0)),
+	"float64": reflect.TypeOf(float64(0)),
+}
+
+// resolveTypeName looks up the type named by name with r, see
+// SplitTypeName.
+func resolveTypeName(r TypeResolver, name string) (reflect.Type, error) {
+	pkgPath, typeName := SplitTypeName(name)
+	if len(pkgPath) == 0 {
+		if t, ok := predeclaredTypes[typeName]; ok {
+			return t, nil
+		}
+		return nil, fmt.Errorf("Unknown predeclared type %q.", name)
+	}
+	if r != nil {
+		if t, ok := r.Resolve(pkgPath, typeName); ok {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("Unable to resolve type %s of package %s.", typeName, pkgPath)
+}
+
+// LoadConfig reads the configuration stored in the file at path.
+//
+// A configuration can extend a base configuration named by its extends key,
+// relative to the directory of the file, which can itself extend another.
+// Settings are merged over the ones of the base: mappings, such as
+// coercions or overrides, are merged key by key, packages are merged by
+// goPackage, and other values replace the base ones.
+func LoadConfig(path string) (*Config, error) {
+	return LoadConfigProfile(path, "")
+}
+
+// LoadConfigProfile reads the configuration stored in the file at path like
+// LoadConfig, with the settings of the named profile merged over the other
+// ones. An empty name selects no profile.
+func LoadConfigProfile(path, profile string) (*Config, error) {
+	values, err := loadConfigValues(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(profile) > 0 {
+		profiles, _ := values["profiles"].(map[interface{}]interface{})
+		settings, ok := profiles[profile].(map[interface{}]interface{})
+		if !ok {
+			var names []string
+			for name := range profiles {
+				names = append(names, fmt.Sprint(name))
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("Unknown profile %q, configuration %s defines: %s.", profile, path, strings.Join(names, ", "))
+		}
+		values = mergeConfigValues(values, settings)
+	}
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("Unable to parse configuration %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// loadConfigValues reads the configuration at path as generic YAML values,
+// merged over the configurations it extends. chain holds the absolute paths
+// of the configurations extending it, to detect cycles.
+func loadConfigValues(path string, chain []string) (map[interface{}]interface{}, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range chain {
+		if p == abs {
+			return nil, fmt.Errorf("Configuration %s extends itself: %s.", path, strings.Join(append(chain[i:], abs), " -> "))
+		}
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := map[interface{}]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("Unable to parse configuration %s: %v", path, err)
+	}
+	extends, ok := values["extends"]
+	if !ok {
+		return values, nil
+	}
+	delete(values, "extends")
+	base, ok := extends.(string)
+	if !ok || len(base) == 0 {
+		return nil, fmt.Errorf("Configuration %s extends %v, expected a path.", path, extends)
+	}
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(path), base)
+	}
+	baseValues, err := loadConfigValues(base, append(chain, abs))
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigValues(baseValues, values), nil
+}
+
+// mergeConfigValues returns the values of base overridden by the ones of
+// override, following the semantics described by LoadConfig.
+func mergeConfigValues(base, override map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := make(map[interface{}]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		if k == "packages" {
+			merged[k] = mergePackageValues(merged[k], v)
+			continue
+		}
+		b, baseIsMap := merged[k].(map[interface{}]interface{})
+		o, isMap := v.(map[interface{}]interface{})
+		if baseIsMap && isMap {
+			merged[k] = mergeConfigValues(b, o)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// mergePackageValues appends the package descriptors of override to the
+// ones of base, replacing the base descriptors of the same Go package.
+func mergePackageValues(base, override interface{}) interface{} {
+	b, ok := base.([]interface{})
+	o, ok2 := override.([]interface{})
+	if !ok || !ok2 {
+		return override
+	}
+	goPackage := func(v interface{}) interface{} {
+		if m, ok := v.(map[interface{}]interface{}); ok {
+			return m["goPackage"]
+		}
+		return nil
+	}
+	merged := append([]interface{}{}, b...)
+	for _, pkg := range o {
+		replaced := false
+		for i, existing := range merged {
+			if name := goPackage(pkg); name != nil && name == goPackage(existing) {
+				merged[i] = pkg
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, pkg)
+		}
+	}
+	return merged
+}
+
+// ProfileNames returns the sorted names of the profiles of the
+// configuration.
+func (c *Config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IndexName returns the name of the index schema written in split mode.
+func (c *Config) IndexName() string {
+	if len(c.Index) == 0 {
+		return "index.json"
+	}
+	return c.Index
+}
+
+// ProtoNumbersName returns the path of the file recording the field numbers
+// of the Proto file: its path with a .numbers.json extension.
+func (c *Config) ProtoNumbersName() string {
+	return strings.TrimSuffix(c.Proto, ".proto") + ".numbers.json"
+}
+
+// Apply sets the generation options described by the configuration.
+func (c *Config) Apply(opts *Options) error {
+	nullable, err := ParseNullableStyle(c.Nullable)
+	if err != nil {
+		return err
+	}
+	if opts.Titles, err = ParseTitleStyle(c.Titles); err != nil {
+		return err
+	}
+	opts.Nullable = nullable
+	// The format only matters to the command line tools writing Output,
+	// but is checked along the other settings.
+	if _, err := ParseOutputFormat(c.Format); err != nil {
+		return err
+	}
+	if opts.Profile, err = ParseProfile(c.Profile); err != nil {
+		return err
+	}
+	if opts.Retention, err = ParseRetention(c.Retention); err != nil {
+		return err
+	}
+	if opts.Naming, err = ParseNamingStrategy(c.Naming); err != nil {
+		return err
+	}
+	if opts.PropertyNaming, err = ParsePropertyNaming(c.PropertyNaming); err != nil {
+		return err
+	}
+	opts.RenameTaggedProperties = c.RenameTaggedProperties
+	if opts.AnyItems, err = ParseAnyItemsStyle(c.AnyItems); err != nil {
+		return err
+	}
+	opts.ItemTypes = c.ItemTypes
+	if len(c.TypeFormats) > 0 {
+		opts.TypeFormats = make(map[string]Format, len(c.TypeFormats))
+		for key, name := range c.TypeFormats {
+			if names := strings.Split(name, ","); len(names) > 1 {
+				opts.TypeFormats[key] = Format{AnyOf: names}
+			} else {
+				opts.TypeFormats[key] = Format{Name: name}
+			}
+		}
+	}
+	opts.FieldFormats = c.FieldFormats
+	if opts.Components, err = ParseComponentsMode(c.Components); err != nil {
+		return err
+	}
+	if opts.RootProperties, err = ParsePropertiesPolicy(c.RootProperties); err != nil {
+		return err
+	}
+	if len(c.DefinitionProperties) > 0 {
+		opts.DefinitionProperties = make(map[string]PropertiesPolicy, len(c.DefinitionProperties))
+		for name, value := range c.DefinitionProperties {
+			if opts.DefinitionProperties[name], err = ParsePropertiesPolicy(value); err != nil {
+				return err
+			}
+		}
+	}
+	if opts.DefaultProperties, err = ParsePropertiesPolicy(c.DefaultProperties); err != nil {
+		return err
+	}
+	if len(c.AdditionalProperties) > 0 {
+		opts.AdditionalProperties = make(map[string]JSONPropertyDescriptor, len(c.AdditionalProperties))
+		for name, value := range c.AdditionalProperties {
+			opts.AdditionalProperties[name] = additionalPropertiesSchema(value)
+		}
+	}
+	if opts.Draft, err = ParseDraft(c.Draft); err != nil {
+		return err
+	}
+	opts.WrapRefs = c.WrapRefs
+	opts.StandardOnly = c.StandardOnly
+	opts.JavaTypeKeyword = c.JavaTypeKeyword
+	opts.NestDefinitions = c.NestDefinitions
+	opts.InlineReferences = c.InlineReferences
+	opts.ContentAddressed = c.ContentAddressed
+	opts.CatchAllMaps = c.CatchAllMaps
+	opts.NonEmptyRequiredStrings = c.NonEmptyRequiredStrings
+	opts.PatternProperties = c.PatternProperties
+	opts.ComposeEmbedded = c.ComposeEmbedded
+	opts.Required = c.Required
+	opts.DocComments = c.DocComments
+	opts.RequiredOverrides = c.RequiredOverrides
+	opts.Strict = c.Strict
+	opts.Audit = c.Audit
+	if c.Stamped {
+		opts.BuildMode = Stamped
+	}
+	opts.Debug = c.Debug
+	opts.GoPackages = c.GoPackages
+	opts.ProtoFields = c.ProtoFields
+	opts.ByteArraysAsStrings = c.ByteArraysAsStrings
+	opts.InternTypes = c.InternTypes
+	opts.DisambiguateNames = c.DisambiguateNames
+	opts.EnumConstants = c.EnumConstants
+	opts.FormatHeuristics = c.FormatHeuristics
+	opts.PackageAliases = c.PackageAliases
+	opts.License = c.License
+	opts.BaseURI = c.BaseURI
+	opts.IDTemplate = c.IDTemplate
+	opts.SchemaURI = c.SchemaURI
+	opts.TimeJavaType = c.TimeJavaType
+	opts.Discriminator = c.Discriminator
+	opts.Coercions = c.Coercions
+	opts.JavaHints = c.JavaHints
+	opts.Labels = c.Labels
+	opts.LabelFilter = c.LabelFilter
+	opts.JavaAcronyms = c.JavaAcronyms
+	opts.TagOptions = c.TagOptions
+	opts.TagKeys = c.TagKeys
+	opts.FieldRules = c.FieldRules
+	opts.Overrides = c.Overrides
+	names := make([]string, 0, len(c.TypeMap))
+	for name := range c.TypeMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		from, err := resolveTypeName(opts.Resolver, name)
+		if err != nil {
+			return err
+		}
+		to, err := resolveTypeName(opts.Resolver, c.TypeMap[name])
+		if err != nil {
+			return err
+		}
+		if opts.TypeMap == nil {
+			opts.TypeMap = make(map[reflect.Type]reflect.Type)
+		}
+		opts.TypeMap[from] = to
+	}
+	for _, pkg := range c.Packages {
+		replaced := false
+		for i, existing := range opts.Packages {
+			if existing.GoPackage == pkg.GoPackage {
+				opts.Packages[i] = pkg
+				replaced = true
+			}
+		}
+		if !replaced {
+			opts.Packages = append(opts.Packages, pkg)
+		}
+	}
+	if len(c.Abstract) > 0 {
+		opts.Abstract = make(map[string]bool)
+		for _, name := range c.Abstract {
+			opts.Abstract[name] = true
+		}
+	}
+	opts.GroupVersionKinds = c.GroupVersionKinds
+	opts.Frozen = c.Frozen
+	if len(c.FreezeLock) > 0 {
+		lock, err := LoadFreezeLock(c.FreezeLock)
+		if err != nil {
+			return err
+		}
+		opts.FreezeLock = lock
+	}
+	if len(c.Annotations) > 0 {
+		annotations, err := LoadAnnotations(c.Annotations)
+		if err != nil {
+			return err
+		}
+		opts.Annotations = annotations
+	}
+	for name, path := range c.Templates {
+		emitter, err := LoadTemplateEmitter(path)
+		if err != nil {
+			return err
+		}
+		if opts.Emitters == nil {
+			opts.Emitters = make(map[string]Emitter)
+		}
+		opts.Emitters[name] = emitter
+	}
+	return nil
+}
+
+// ParseNullableStyle parses the name of a nullable style: "swagger2",
+// "openapi3", "both", "oneOf", "typeArray" or an empty string for none.
+func ParseNullableStyle(s string) (NullableStyle, error) {
+	switch s {
+	case "":
+		return 0, nil
+	case "swagger2":
+		return NullableSwagger2, nil
+	case "openapi3":
+		return NullableOpenAPI3, nil
+	case "both":
+		return NullableBoth, nil
+	case "oneOf":
+		return NullableOneOf, nil
+	case "typeArray":
+		return NullableTypeArray, nil
+	}
+	return 0, fmt.Errorf("Unknown nullable style %q.", s)
+}
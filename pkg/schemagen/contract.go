@@ -0,0 +1,228 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// ContractManifest is the file name of the manifest written along the
+// contract fixtures.
+const ContractManifest = "manifest.json"
+
+// ContractFixture describes one fixture of the contract tests.
+type ContractFixture struct {
+	Definition string `json:"definition"`
+	JavaType   string `json:"javaType"`
+	File       string `json:"file"`
+}
+
+// ContractFixtures builds the fixtures of the Java and Go round-trip contract
+// tests: for every object definition with a Java type, a JSON document
+// filling in each of its properties, and a manifest listing them. The Java
+// build deserializes each fixture into its Java type, serializes it back
+// and expects the same JSON, catching mapping drift between the Go structs
+// and the generated Java model. The content of each file is returned keyed
+// by file name.
+func ContractFixtures(schema *JSONSchema) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	var manifest struct {
+		Fixtures []ContractFixture `json:"fixtures"`
+	}
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		def := schema.Definitions[name]
+		if def.JSONObjectDescriptor == nil || def.JavaTypeDescriptor == nil {
+			continue
+		}
+		f := newFixtures(schema, name)
+		b, err := json.MarshalIndent(f.value(def), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		file := name + ".json"
+		files[file] = b
+		manifest.Fixtures = append(manifest.Fixtures, ContractFixture{
+			Definition: name,
+			JavaType:   def.JavaType,
+			File:       file,
+		})
+	}
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[ContractManifest] = b
+	return files, nil
+}
+
+// fixtures builds sample values of the descriptors of a schema, valid
+// against it. It backs ContractFixtures, Example and GenerateExample.
+type fixtures struct {
+	schema *JSONSchema
+	// visiting holds the definitions being filled in, whose references
+	// are left out to stop recursive types.
+	visiting map[string]bool
+	// placeholders fills in enums with a placeholder listing the allowed
+	// values instead of the first one.
+	placeholders bool
+	// random generates the strings matching a pattern and the map keys,
+	// from a fixed seed so that samples are stable.
+	random randomInstances
+}
+
+// newFixtures returns the fixtures of schema, the definitions named by
+// visiting being left out.
+func newFixtures(schema *JSONSchema, visiting ...string) fixtures {
+	f := fixtures{
+		schema:   schema,
+		visiting: make(map[string]bool),
+		random:   randomInstances{schema: schema, rand: rand.New(rand.NewSource(1))},
+	}
+	for _, name := range visiting {
+		f.visiting[name] = true
+	}
+	return f
+}
+
+// value returns a sample value of p, or nil if no value can be built
+// without recursing into a definition being filled in. Properties take
+// their default, else their first example or enum value, else a sample
+// value within their bounds, maps hold one entry and arrays as few items
+// as allowed but one.
+func (f fixtures) value(p JSONPropertyDescriptor) interface{} {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		def, defined := f.schema.Definitions[name]
+		if !ok || !defined || f.visiting[name] {
+			return nil
+		}
+		f.visiting[name] = true
+		defer delete(f.visiting, name)
+		return f.value(def)
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		if len(p.AllOf) > 0 {
+			return f.allOf(p)
+		}
+		for _, alts := range [][]JSONPropertyDescriptor{p.OneOf, p.AnyOf} {
+			for _, alt := range alts {
+				if v := f.value(alt); v != nil {
+					return v
+				}
+			}
+		}
+	}
+	if p.JSONDescriptor == nil {
+		return nil
+	}
+	if len(p.Enum) > 0 && f.placeholders {
+		values := make([]string, len(p.Enum))
+		for i, v := range p.Enum {
+			values[i] = fmt.Sprint(v)
+		}
+		return "<" + strings.Join(values, "|") + ">"
+	}
+	switch {
+	case p.Default != nil:
+		return p.Default
+	case len(p.Examples) > 0:
+		return p.Examples[0]
+	case len(p.Enum) > 0:
+		return p.Enum[0]
+	}
+	switch p.Type {
+	case "object":
+		return f.object(p)
+	case "array":
+		return f.array(p)
+	case "string":
+		if len(p.Pattern) > 0 {
+			return f.random.string(p.JSONDescriptor)
+		}
+		return exampleString(p.JSONDescriptor)
+	case "integer", "number":
+		return exampleNumber(p.JSONDescriptor)
+	case "boolean":
+		return true
+	}
+	return nil
+}
+
+// allOf merges the sample values of the objects p is composed of.
+func (f fixtures) allOf(p JSONPropertyDescriptor) interface{} {
+	obj := make(map[string]interface{})
+	parts := append([]JSONPropertyDescriptor{}, p.AllOf...)
+	if p.JSONObjectDescriptor != nil {
+		own := p
+		own.JSONCombinatorDescriptor = nil
+		parts = append(parts, own)
+	}
+	for _, part := range parts {
+		if v, ok := f.value(part).(map[string]interface{}); ok {
+			for k, v := range v {
+				obj[k] = v
+			}
+		}
+	}
+	return obj
+}
+
+func (f fixtures) object(p JSONPropertyDescriptor) interface{} {
+	obj := make(map[string]interface{})
+	if p.JSONObjectDescriptor != nil {
+		for _, name := range sortedPropertyNames(p.Properties) {
+			if v := f.value(p.Properties[name]); v != nil {
+				obj[name] = v
+			}
+		}
+	}
+	if p.JSONMapDescriptor == nil || p.MaxProperties != nil && *p.MaxProperties == 0 {
+		return obj
+	}
+	for i := 0; i < 4; i++ {
+		if key, value, ok := f.random.key(p, i); ok {
+			if v := f.value(value); v != nil {
+				obj[key] = v
+			}
+			break
+		}
+	}
+	return obj
+}
+
+func (f fixtures) array(p JSONPropertyDescriptor) interface{} {
+	items := []interface{}{}
+	if p.JSONArrayDescriptor == nil || p.MaxItems != nil && *p.MaxItems == 0 {
+		return items
+	}
+	item := f.value(p.Items)
+	if item == nil {
+		return items
+	}
+	n := 1
+	if p.MinItems != nil && *p.MinItems > n {
+		n = *p.MinItems
+	}
+	for i := 0; i < n; i++ {
+		items = append(items, item)
+	}
+	return items
+}
+
+// sampleString returns a string within the length bounds of d.
+func sampleString(d *JSONDescriptor) string {
+	if d.Format == "date-time" {
+		return "2015-01-01T00:00:00Z"
+	}
+	s := "value"
+	if d.MinLength != nil && len(s) < *d.MinLength {
+		s += strings.Repeat("x", *d.MinLength-len(s))
+	}
+	if d.MaxLength != nil && len(s) > *d.MaxLength {
+		s = s[:*d.MaxLength]
+	}
+	return s
+}
@@ -0,0 +1,329 @@
+package schemagen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// CppEmitter renders the definitions of a schema as C++17 structs with
+// to_json/from_json functions for nlohmann::json, so that native agents can
+// consume the same payloads. Definitions are emitted in dependency order
+// into a single header. References between the definitions of a cycle,
+// such as a struct referring to itself, are held through std::shared_ptr
+// and other optional references through std::optional.
+type CppEmitter struct {
+	// Namespace wraps the generated code if it is not empty.
+	Namespace string
+}
+
+var cppKeywords = map[string]bool{
+	"auto": true, "bool": true, "break": true, "case": true, "catch": true,
+	"char": true, "class": true, "const": true, "continue": true,
+	"default": true, "delete": true, "do": true, "double": true,
+	"else": true, "enum": true, "explicit": true, "export": true,
+	"extern": true, "false": true, "float": true, "for": true,
+	"friend": true, "goto": true, "if": true, "inline": true, "int": true,
+	"long": true, "namespace": true, "new": true, "operator": true,
+	"private": true, "protected": true, "public": true, "register": true,
+	"return": true, "short": true, "signed": true, "sizeof": true,
+	"static": true, "struct": true, "switch": true, "template": true,
+	"this": true, "throw": true, "true": true, "try": true, "typedef": true,
+	"typename": true, "union": true, "unsigned": true, "using": true,
+	"virtual": true, "void": true, "volatile": true, "while": true,
+}
+
+func cppIdentifier(name string) string {
+	id := []rune(name)
+	for i, r := range id {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			id[i] = '_'
+		}
+	}
+	s := string(id)
+	if cppKeywords[s] {
+		s += "_"
+	}
+	return s
+}
+
+// cppDefinitions holds the definitions of a schema being emitted as C++,
+// grouped by the cycle they belong to.
+type cppDefinitions struct {
+	schema *JSONSchema
+	// cycle numbers the strongly connected components of the references
+	// between definitions.
+	cycle map[string]int
+}
+
+func newCppDefinitions(schema *JSONSchema) *cppDefinitions {
+	d := &cppDefinitions{schema: schema, cycle: make(map[string]int)}
+	// Tarjan's algorithm over the references between definitions.
+	index := map[string]int{}
+	low := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range referencedDefinitions(schema.Definitions[name]) {
+			if _, ok := schema.Definitions[dep]; !ok {
+				continue
+			}
+			if _, seen := index[dep]; !seen {
+				visit(dep)
+				if low[dep] < low[name] {
+					low[name] = low[dep]
+				}
+			} else if onStack[dep] && index[dep] < low[name] {
+				low[name] = index[dep]
+			}
+		}
+		if low[name] == index[name] {
+			id := len(d.cycle)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				d.cycle[top] = id
+				if top == name {
+					break
+				}
+			}
+		}
+	}
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		if _, seen := index[name]; !seen {
+			visit(name)
+		}
+	}
+	return d
+}
+
+// recursive tells whether the definition owner refers to the definition
+// name, possibly itself, through a cycle.
+func (d *cppDefinitions) recursive(owner, name string) bool {
+	c, ok := d.cycle[name]
+	return ok && c == d.cycle[owner]
+}
+
+// cppReference returns the name of the local definition p refers to, if
+// any.
+func cppReference(p JSONPropertyDescriptor) (string, bool) {
+	if p.JSONReferenceDescriptor != nil {
+		return schemamodel.LocalDefinition(p.Reference)
+	}
+	if p.JSONCombinatorDescriptor != nil && len(p.AllOf) > 0 {
+		return cppReference(p.AllOf[0])
+	}
+	return "", false
+}
+
+// cppType returns the C++ type of the values described by p in the
+// definition owner. Definitions of the cycle of owner that are not structs
+// are described as nlohmann::json, as C++ aliases cannot refer to
+// themselves.
+func (d *cppDefinitions) cppType(owner string, p JSONPropertyDescriptor) string {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		if !ok {
+			return "nlohmann::json"
+		}
+		if d.schema.Definitions[name].JSONObjectDescriptor == nil && d.recursive(owner, name) {
+			return "nlohmann::json"
+		}
+		return cppIdentifier(name)
+	}
+	if p.JSONCombinatorDescriptor != nil && len(p.AllOf) > 0 {
+		return d.cppType(owner, p.AllOf[0])
+	}
+	if p.JSONDescriptor == nil {
+		return "nlohmann::json"
+	}
+	switch p.Type {
+	case "string":
+		return "std::string"
+	case "integer":
+		return "int64_t"
+	case "number":
+		return "double"
+	case "boolean":
+		return "bool"
+	case "array":
+		if p.JSONArrayDescriptor != nil {
+			return "std::vector<" + d.cppType(owner, p.Items) + ">"
+		}
+	case "object":
+		if p.JSONMapDescriptor != nil {
+			return "std::map<std::string, " + d.cppType(owner, p.MapValueType) + ">"
+		}
+	}
+	return "nlohmann::json"
+}
+
+// C++ member kinds: held by value, through std::shared_ptr or through
+// std::optional.
+const (
+	cppValue = iota
+	cppShared
+	cppOptional
+)
+
+// memberKind tells how the struct owner holds its property p.
+func (d *cppDefinitions) memberKind(owner string, p JSONPropertyDescriptor, required bool) int {
+	name, ok := cppReference(p)
+	if !ok || d.schema.Definitions[name].JSONObjectDescriptor == nil {
+		return cppValue
+	}
+	if d.recursive(owner, name) {
+		return cppShared
+	}
+	if !required {
+		return cppOptional
+	}
+	return cppValue
+}
+
+func sortedDefinitionNames(defs map[string]JSONPropertyDescriptor) []string {
+	names := make([]string, 0, len(defs))
+	for name := range defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedPropertyNames(props map[string]JSONPropertyDescriptor) []string {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e CppEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "// Code generated by schemagen. DO NOT EDIT.")
+	fmt.Fprintln(out, "#pragma once")
+	fmt.Fprintln(out)
+	for _, header := range []string{"cstdint", "map", "memory", "optional", "string", "vector", "nlohmann/json.hpp"} {
+		fmt.Fprintf(out, "#include <%s>\n", header)
+	}
+	fmt.Fprintln(out)
+	if len(e.Namespace) > 0 {
+		fmt.Fprintf(out, "namespace %s {\n\n", e.Namespace)
+	}
+
+	// Emit definitions after the ones they depend on. References within a
+	// cycle are held through std::shared_ptr or containers, which accept
+	// the forward declarations.
+	defs := newCppDefinitions(schema)
+	var order []string
+	state := map[string]int{}
+	var visit func(name string)
+	visit = func(name string) {
+		if state[name] != 0 {
+			return
+		}
+		state[name] = 1
+		for _, dep := range referencedDefinitions(schema.Definitions[name]) {
+			if _, ok := schema.Definitions[dep]; ok {
+				visit(dep)
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+	}
+	names := sortedDefinitionNames(schema.Definitions)
+	for _, name := range names {
+		visit(name)
+	}
+
+	for _, name := range names {
+		if schema.Definitions[name].JSONObjectDescriptor != nil {
+			fmt.Fprintf(out, "struct %s;\n", cppIdentifier(name))
+		}
+	}
+	fmt.Fprintln(out)
+	// The structs are all defined before the conversion functions, which
+	// need the types of the members to be complete.
+	var structs []string
+	kinds := map[string]map[string]int{}
+	types := map[string]map[string]string{}
+	for _, name := range order {
+		def := schema.Definitions[name]
+		id := cppIdentifier(name)
+		if def.JSONObjectDescriptor == nil {
+			fmt.Fprintf(out, "using %s = %s;\n\n", id, defs.cppType(name, JSONPropertyDescriptor{JSONDescriptor: def.JSONDescriptor, JSONArrayDescriptor: def.JSONArrayDescriptor, JSONMapDescriptor: def.JSONMapDescriptor}))
+			continue
+		}
+		structs = append(structs, name)
+		required := map[string]bool{}
+		for _, p := range def.Required {
+			required[p] = true
+		}
+		kinds[name] = map[string]int{}
+		types[name] = map[string]string{}
+		fmt.Fprintf(out, "struct %s {\n", id)
+		for _, p := range sortedPropertyNames(def.Properties) {
+			kind := defs.memberKind(name, def.Properties[p], required[p])
+			typ := defs.cppType(name, def.Properties[p])
+			kinds[name][p], types[name][p] = kind, typ
+			switch kind {
+			case cppShared:
+				fmt.Fprintf(out, "    std::shared_ptr<%s> %s;\n", typ, cppIdentifier(p))
+			case cppOptional:
+				fmt.Fprintf(out, "    std::optional<%s> %s;\n", typ, cppIdentifier(p))
+			default:
+				fmt.Fprintf(out, "    %s %s;\n", typ, cppIdentifier(p))
+			}
+		}
+		fmt.Fprintf(out, "};\n\n")
+	}
+
+	for _, name := range structs {
+		for _, fn := range []string{"void to_json(nlohmann::json& j, const %s& v);\n", "void from_json(const nlohmann::json& j, %s& v);\n"} {
+			fmt.Fprintf(out, "inline "+fn, cppIdentifier(name))
+		}
+	}
+	fmt.Fprintln(out)
+	for _, name := range structs {
+		id := cppIdentifier(name)
+		props := sortedPropertyNames(schema.Definitions[name].Properties)
+		fmt.Fprintf(out, "inline void to_json(nlohmann::json& j, const %s& v) {\n", id)
+		fmt.Fprintf(out, "    j = nlohmann::json::object();\n")
+		for _, p := range props {
+			if kinds[name][p] == cppValue {
+				fmt.Fprintf(out, "    j[%q] = v.%s;\n", p, cppIdentifier(p))
+			} else {
+				fmt.Fprintf(out, "    if (v.%s) j[%q] = *v.%s;\n", cppIdentifier(p), p, cppIdentifier(p))
+			}
+		}
+		fmt.Fprintf(out, "}\n\n")
+
+		fmt.Fprintf(out, "inline void from_json(const nlohmann::json& j, %s& v) {\n", id)
+		for _, p := range props {
+			typ := types[name][p]
+			switch kinds[name][p] {
+			case cppShared:
+				fmt.Fprintf(out, "    if (j.contains(%q) && !j.at(%q).is_null()) v.%s = std::make_shared<%s>(j.at(%q).get<%s>());\n", p, p, cppIdentifier(p), typ, p, typ)
+			case cppOptional:
+				fmt.Fprintf(out, "    if (j.contains(%q) && !j.at(%q).is_null()) v.%s = j.at(%q).get<%s>();\n", p, p, cppIdentifier(p), p, typ)
+			default:
+				fmt.Fprintf(out, "    if (j.contains(%q) && !j.at(%q).is_null()) j.at(%q).get_to(v.%s);\n", p, p, p, cppIdentifier(p))
+			}
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+	if len(e.Namespace) > 0 {
+		fmt.Fprintf(out, "}  // namespace %s\n", e.Namespace)
+	}
+	return out.Flush()
+}
@@ -0,0 +1,89 @@
+package schemagen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cppOwner struct {
+	Name string `json:"name"`
+}
+
+type cppDocument struct {
+	Root   recursiveRoot `json:"root"`
+	Owner  *cppOwner     `json:"owner,omitempty"`
+	Labels []string      `json:"labels"`
+}
+
+type cppSchema struct {
+	Document cppDocument `json:"document"`
+}
+
+const cppMain = `#include <iostream>
+#include "schema.h"
+
+int main() {
+    auto in = nlohmann::json::parse(std::cin);
+    schema::NAME document = in.get<schema::NAME>();
+    nlohmann::json out = document;
+    std::cout << out.dump() << std::endl;
+    return 0;
+}
+`
+
+// TestCppCompiles compiles the header emitted for cyclic definitions with
+// g++ and round-trips a document through it. It is skipped unless g++ and
+// nlohmann/json.hpp, e.g. found through CPLUS_INCLUDE_PATH, are available.
+func TestCppCompiles(t *testing.T) {
+	if _, err := exec.LookPath("g++"); err != nil {
+		t.Skip("g++ is not available")
+	}
+	dir, err := ioutil.TempDir("", "schemagen-cpp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	probe := exec.Command("g++", "-std=c++17", "-fsyntax-only", "-x", "c++", "-")
+	probe.Stdin = strings.NewReader("#include <nlohmann/json.hpp>\n")
+	if err := probe.Run(); err != nil {
+		t.Skip("nlohmann/json.hpp is not available")
+	}
+
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(cppSchema{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header bytes.Buffer
+	if err := (CppEmitter{Namespace: "schema"}).Emit(schema, &header); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "schema.h"), header.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	name := cppIdentifier(definitionName(t, schema, "cppDocument"))
+	main := strings.Replace(cppMain, "NAME", name, -1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.cpp"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	build := exec.Command("g++", "-std=c++17", "-Wall", "-o", filepath.Join(dir, "main"), filepath.Join(dir, "main.cpp"))
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s\n%s", err, out, header.String())
+	}
+
+	document := `{"labels":["a"],"owner":{"name":"x"},"root":{"link":{"next":{"next":{}}},"node":{"byName":{"a":{"byName":{},"children":[]}},"children":[]},"parent":{"child":{"parents":[{}]}},"tree":{"a":{"b":{}}}}}`
+	run := exec.Command(filepath.Join(dir, "main"))
+	run.Stdin = strings.NewReader(document)
+	out, err := run.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != document {
+		t.Errorf("round trip changed the document:\n%s\n%s", got, document)
+	}
+}
@@ -0,0 +1,155 @@
+package schemagen
+
+import (
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+const preserveUnknownFields = "x-kubernetes-preserve-unknown-fields"
+
+// StructuralSchema returns the root of s as a Kubernetes structural schema,
+// suitable as the openAPIV3Schema of a CustomResourceDefinition: references
+// are inlined, leaving no definitions; objects with properties do not set
+// additionalProperties; free-form values, catch-all maps and recursive
+// references preserve unknown fields; and Java hints and vendor extensions
+// other than x-kubernetes-* ones, which apiextensions rejects, are left out.
+func StructuralSchema(s *JSONSchema) JSONPropertyDescriptor {
+	c := structuralConverter{defs: s.Definitions, visiting: map[string]bool{}}
+	return c.convert(JSONPropertyDescriptor{
+		JSONDescriptor:       &JSONDescriptor{Type: "object", Description: s.Description},
+		JSONObjectDescriptor: s.JSONObjectDescriptor,
+	})
+}
+
+type structuralConverter struct {
+	defs map[string]JSONPropertyDescriptor
+	// visiting holds the definitions being inlined, whose references
+	// cannot be inlined again.
+	visiting map[string]bool
+}
+
+func (c structuralConverter) convert(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		def, defined := c.defs[name]
+		if !ok || !defined || c.visiting[name] {
+			return preservingDescriptor(p.JSONDescriptor)
+		}
+		c.visiting[name] = true
+		defer delete(c.visiting, name)
+		inlined := c.convert(def)
+		// Keep the description given to the reference itself.
+		if p.JSONDescriptor != nil && len(p.Description) > 0 {
+			desc := JSONDescriptor{}
+			if inlined.JSONDescriptor != nil {
+				desc = *inlined.JSONDescriptor
+			}
+			desc.Description = p.Description
+			inlined.JSONDescriptor = &desc
+		}
+		return inlined
+	}
+
+	result := JSONPropertyDescriptor{
+		EmbeddedResourceDescriptor: p.EmbeddedResourceDescriptor,
+	}
+	if p.JSONDescriptor != nil {
+		// apiextensions knows neither examples nor deprecated.
+		desc := *p.JSONDescriptor
+		desc.Examples = nil
+		desc.Deprecated = false
+		desc.AllowNull = false
+		result.JSONDescriptor = &desc
+	}
+	if p.NullableDescriptor != nil && p.Nullable || p.JSONDescriptor != nil && p.AllowNull {
+		result.NullableDescriptor = &NullableDescriptor{Nullable: true}
+	}
+	for k, v := range p.Extensions {
+		if strings.HasPrefix(k, "x-kubernetes-") {
+			if result.Extensions == nil {
+				result.Extensions = make(map[string]interface{})
+			}
+			result.Extensions[k] = v
+		}
+	}
+	preserve := p.EmbeddedResourceDescriptor != nil
+	if p.JSONObjectDescriptor != nil {
+		obj := JSONObjectDescriptor{Required: p.Required}
+		if len(p.Properties) > 0 {
+			obj.Properties = make(map[string]JSONPropertyDescriptor, len(p.Properties))
+			for k, v := range p.Properties {
+				obj.Properties[k] = c.convert(v)
+			}
+		}
+		if _, ok := p.AdditionalProperties.(JSONPropertyDescriptor); ok || len(obj.Properties) == 0 && p.JSONMapDescriptor == nil {
+			preserve = true
+		}
+		if len(obj.Properties) > 0 || len(obj.Required) > 0 {
+			result.JSONObjectDescriptor = &obj
+		}
+	}
+	if p.JSONArrayDescriptor != nil {
+		result.JSONArrayDescriptor = &JSONArrayDescriptor{
+			Items:    c.convert(p.Items),
+			MinItems: p.MinItems,
+			MaxItems: p.MaxItems,
+		}
+	}
+	if p.JSONMapDescriptor != nil {
+		result.JSONMapDescriptor = &JSONMapDescriptor{
+			MapValueType:  c.convert(p.MapValueType),
+			MinProperties: p.MinProperties,
+			MaxProperties: p.MaxProperties,
+		}
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		result.JSONCombinatorDescriptor = &JSONCombinatorDescriptor{
+			AllOf: c.convertAll(p.AllOf),
+			AnyOf: c.convertAll(p.AnyOf),
+			OneOf: c.convertAll(p.OneOf),
+		}
+	}
+	if p.JSONDescriptor == nil && p.JSONCombinatorDescriptor == nil {
+		// An untyped value, such as an interface{} field, which only
+		// needs to preserve unknown fields to be structural.
+		return JSONPropertyDescriptor{
+			Extensions: map[string]interface{}{
+				preserveUnknownFields: true,
+			},
+		}
+	}
+	if preserve {
+		if result.Extensions == nil {
+			result.Extensions = make(map[string]interface{})
+		}
+		result.Extensions[preserveUnknownFields] = true
+	}
+	return result
+}
+
+func (c structuralConverter) convertAll(ps []JSONPropertyDescriptor) []JSONPropertyDescriptor {
+	if ps == nil {
+		return nil
+	}
+	result := make([]JSONPropertyDescriptor, len(ps))
+	for i, p := range ps {
+		result[i] = c.convert(p)
+	}
+	return result
+}
+
+// preservingDescriptor describes an object whose fields are not validated,
+// keeping the description of d, if any.
+func preservingDescriptor(d *JSONDescriptor) JSONPropertyDescriptor {
+	desc := &JSONDescriptor{Type: "object"}
+	if d != nil {
+		desc.Description = d.Description
+	}
+	return JSONPropertyDescriptor{
+		JSONDescriptor: desc,
+		Extensions: map[string]interface{}{
+			preserveUnknownFields: true,
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package schemagen
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PropertyDecorator may change the property generated for field of the
+// struct t, typically to attach vendor extensions, such as
+// x-kubernetes-list-type or UI hints, to its Extensions, which are
+// serialized inline. It is called once per field of every struct walked,
+// so the properties of a shared definition are decorated once.
+type PropertyDecorator func(t reflect.Type, field reflect.StructField, prop *JSONPropertyDescriptor)
+
+// decorate calls Options.Decorators with the property of field, warning
+// about the extensions they add that are not x- keywords, which
+// validators would take for standard ones.
+func (g *schemaGenerator) decorate(t reflect.Type, field reflect.StructField, prop *JSONPropertyDescriptor) {
+	if len(g.opts.Decorators) == 0 {
+		return
+	}
+	// Decorators may add to the map in place.
+	before := make(map[string]bool, len(prop.Extensions))
+	for k := range prop.Extensions {
+		before[k] = true
+	}
+	for _, decorator := range g.opts.Decorators {
+		decorator(t, field, prop)
+	}
+	var invalid []string
+	for k := range prop.Extensions {
+		if !before[k] && !strings.HasPrefix(k, "x-") {
+			invalid = append(invalid, k)
+		}
+	}
+	sort.Strings(invalid)
+	for _, k := range invalid {
+		g.warnf("%s.%s is decorated with %s, which is not an x- extension.", t.Name(), field.Name, k)
+	}
+}
@@ -0,0 +1,16 @@
+package schemagen
+
+import "reflect"
+
+// Describe returns the property descriptor for a single type without
+// building a schema. Struct types are described by a reference to the
+// definition they would have in a generated schema.
+func Describe(t reflect.Type) JSONPropertyDescriptor {
+	return DescribeWithOptions(t, Options{})
+}
+
+// DescribeWithOptions is like Describe but honors the given options.
+func DescribeWithOptions(t reflect.Type, opts Options) JSONPropertyDescriptor {
+	g := newSchemaGenerator(opts)
+	return g.getPropertyDescriptor(t)
+}
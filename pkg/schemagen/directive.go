@@ -0,0 +1,76 @@
+package schemagen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+)
+
+// Directive is a schema generation requested by a go:generate directive
+// such as
+//
+//	//go:generate generate -type=Template -output=template-schema.json
+type Directive struct {
+	// Package is the import path of the package containing the directive.
+	Package string
+	// Type is the name of the struct type to generate the schema of.
+	Type string
+}
+
+// ParseDirective infers the package and type of a directive at the given
+// line of a Go source file, as passed by go generate in $GOFILE and
+// $GOLINE. If typeName is empty, the first struct type declared after the
+// directive is used.
+func ParseDirective(file string, line int, typeName string) (Directive, error) {
+	dir, err := filepath.Abs(filepath.Dir(file))
+	if err != nil {
+		return Directive{}, err
+	}
+	pkg, err := build.ImportDir(dir, build.FindOnly)
+	if err != nil {
+		return Directive{}, err
+	}
+	if len(pkg.ImportPath) == 0 || pkg.ImportPath == "." {
+		return Directive{}, fmt.Errorf("Unable to determine the import path of %s.", dir)
+	}
+	d := Directive{Package: pkg.ImportPath, Type: typeName}
+	if len(typeName) > 0 {
+		return d, nil
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	if err != nil {
+		return Directive{}, err
+	}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || fset.Position(gen.Pos()).Line < line {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.StructType); ok {
+				d.Type = ts.Name.Name
+				return d, nil
+			}
+		}
+	}
+	return Directive{}, fmt.Errorf("No struct type declared after line %d of %s.", line, file)
+}
+
+// GenerateDirectiveSchema generates the schema of the type named by the
+// directive, looked up with opts.Resolver.
+func GenerateDirectiveSchema(d Directive, opts Options) (*JSONSchema, error) {
+	if opts.Resolver == nil {
+		return nil, fmt.Errorf("A type resolver is required to generate a directive schema.")
+	}
+	t, ok := opts.Resolver.Resolve(d.Package, d.Type)
+	if !ok {
+		return nil, fmt.Errorf("Unable to resolve type %s of package %s.", d.Type, d.Package)
+	}
+	return GenerateSchemaWithOptions(t, opts)
+}
@@ -0,0 +1,143 @@
+package schemagen
+
+import (
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// packageDocs holds the doc comments of the types declared by a package,
+// keyed by type name, and of their fields, keyed by "<type>.<field>", and
+// the labels and properties policies declared by the doc comments of the
+// types, along with the exported string constants of the types and the
+// names of the deprecated constants.
+type packageDocs struct {
+	types      map[string]string
+	fields     map[string]string
+	labels     map[string][]string
+	properties map[string]string
+	constants  map[string][]enumConstant
+	deprecated map[string]bool
+}
+
+// loadPackageDocs parses the sources of the package with the given import
+// path, located through go/build, and extracts its doc comments.
+func loadPackageDocs(pkgPath string) (*packageDocs, error) {
+	pkg, err := build.Import(pkgPath, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	files := make(map[string]*ast.File)
+	for _, file := range pkg.GoFiles {
+		path := filepath.Join(pkg.Dir, file)
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files[path] = f
+	}
+	docs := &packageDocs{
+		types:      make(map[string]string),
+		fields:     make(map[string]string),
+		labels:     make(map[string][]string),
+		properties: make(map[string]string),
+		constants:  make(map[string][]enumConstant),
+		deprecated: make(map[string]bool),
+	}
+	// go/doc takes over the files, so constants are looked at first.
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.CONST {
+				for _, name := range deprecatedConstants(gen) {
+					docs.deprecated[name] = true
+				}
+			}
+		}
+	}
+	d := doc.New(&ast.Package{Name: pkg.Name, Files: files}, pkgPath, doc.AllDecls)
+	for _, typ := range d.Types {
+		docs.labels[typ.Name], docs.types[typ.Name] = parseLabelMarkers(typ.Doc)
+		if policy, ok, text := parsePropertiesMarker(docs.types[typ.Name]); ok {
+			docs.properties[typ.Name], docs.types[typ.Name] = policy, text
+		}
+		for _, c := range typ.Consts {
+			docs.constants[typ.Name] = append(docs.constants[typ.Name], stringConstants(c.Decl)...)
+		}
+		for _, spec := range typ.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != typ.Name {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			for _, field := range st.Fields.List {
+				text := field.Doc.Text()
+				if len(text) == 0 {
+					text = field.Comment.Text()
+				}
+				for _, name := range field.Names {
+					docs.fields[typ.Name+"."+name.Name] = strings.TrimSpace(text)
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+// packageDocs returns the doc comments of the package declaring t, parsed
+// once per package. Packages whose sources cannot be found or parsed are
+// left undocumented.
+func (g *schemaGenerator) packageDocs(t reflect.Type) *packageDocs {
+	return g.pathDocs(t.PkgPath())
+}
+
+func (g *schemaGenerator) pathDocs(pkgPath string) *packageDocs {
+	docs, ok := g.docs[pkgPath]
+	if !ok {
+		var err error
+		if docs, err = loadPackageDocs(pkgPath); err != nil {
+			docs = &packageDocs{}
+		}
+		g.docs[pkgPath] = docs
+	}
+	return docs
+}
+
+// applyTypeDoc sets the doc comment of the named type t as the description
+// of its definition, unless it already has one.
+func (g *schemaGenerator) applyTypeDoc(def *JSONPropertyDescriptor, t reflect.Type) {
+	if !g.opts.DocComments || len(t.PkgPath()) == 0 {
+		return
+	}
+	applyDescription(def, g.packageDocs(t).types[t.Name()])
+}
+
+// applyFieldDoc sets the doc comment of the field f of t as the
+// description of its property, unless it already has one, e.g. from a
+// description struct tag.
+func (g *schemaGenerator) applyFieldDoc(prop *JSONPropertyDescriptor, t reflect.Type, f reflect.StructField) {
+	if !g.opts.DocComments || len(t.PkgPath()) == 0 {
+		return
+	}
+	applyDescription(prop, g.packageDocs(t).fields[t.Name()+"."+f.Name])
+}
+
+func applyDescription(p *JSONPropertyDescriptor, description string) {
+	if len(description) == 0 || (p.JSONDescriptor != nil && len(p.Description) > 0) {
+		return
+	}
+	desc := JSONDescriptor{}
+	if p.JSONDescriptor != nil {
+		desc = *p.JSONDescriptor
+	}
+	desc.Description = description
+	p.JSONDescriptor = &desc
+}
@@ -0,0 +1,222 @@
+package schemagen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// DocsFormat selects the markup of the reference documentation rendered by
+// DocsEmitter.
+type DocsFormat int
+
+const (
+	// MarkdownDocs renders GitHub flavored Markdown.
+	MarkdownDocs DocsFormat = iota
+	// HTMLDocs renders a standalone HTML page.
+	HTMLDocs
+)
+
+// DocsFormatFor returns the format of documentation written to the file at
+// path: HTMLDocs for .html and .htm files, MarkdownDocs otherwise.
+func DocsFormatFor(path string) DocsFormat {
+	lower := strings.ToLower(path)
+	if strings.HasSuffix(lower, ".html") || strings.HasSuffix(lower, ".htm") {
+		return HTMLDocs
+	}
+	return MarkdownDocs
+}
+
+// DocsEmitter renders a schema as human-readable reference documentation:
+// a section for the root and for each definition, in name order, with the
+// description of the type and a table of its properties giving their
+// type, whether they are required and their description. References
+// become links to the sections of their definitions.
+type DocsEmitter struct {
+	Format DocsFormat
+	// Title heads the document, the title of the schema or "API
+	// reference" if it is empty.
+	Title string
+	// Root names the section of the root object, which is left out if it
+	// is empty.
+	Root string
+}
+
+func (e DocsEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	title := e.Title
+	if len(title) == 0 {
+		title = schema.Title
+	}
+	if len(title) == 0 {
+		title = "API reference"
+	}
+	if e.Format == HTMLDocs {
+		fmt.Fprintln(out, "<!DOCTYPE html>")
+		fmt.Fprintln(out, "<html>")
+		fmt.Fprintf(out, "<head><meta charset=\"utf-8\"><title>%s</title></head>\n", html.EscapeString(title))
+		fmt.Fprintln(out, "<body>")
+		fmt.Fprintf(out, "<h1>%s</h1>\n", html.EscapeString(title))
+	} else {
+		fmt.Fprintf(out, "# %s\n", title)
+	}
+	if len(e.Root) > 0 && schema.JSONObjectDescriptor != nil {
+		root := JSONPropertyDescriptor{
+			JSONDescriptor:       &schema.JSONDescriptor,
+			JSONObjectDescriptor: schema.JSONObjectDescriptor,
+		}
+		e.writeSection(out, e.Root, root)
+	}
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		e.writeSection(out, name, schema.Definitions[name])
+	}
+	if e.Format == HTMLDocs {
+		fmt.Fprintln(out, "</body>")
+		fmt.Fprintln(out, "</html>")
+	}
+	return out.Flush()
+}
+
+// writeSection documents the type described by def under the heading name.
+func (e DocsEmitter) writeSection(w io.Writer, name string, def JSONPropertyDescriptor) {
+	var description string
+	if def.JSONDescriptor != nil {
+		description = def.Description
+	}
+	if e.Format == HTMLDocs {
+		fmt.Fprintf(w, "<h2 id=\"%s\">%s</h2>\n", docsAnchor(name), html.EscapeString(name))
+		if len(description) > 0 {
+			fmt.Fprintf(w, "<p>%s</p>\n", strings.Replace(html.EscapeString(description), "\n", "<br>", -1))
+		}
+	} else {
+		fmt.Fprintf(w, "\n## %s\n", name)
+		if len(description) > 0 {
+			fmt.Fprintf(w, "\n%s\n", description)
+		}
+	}
+	if def.JSONObjectDescriptor == nil || len(def.Properties) == 0 || def.JSONCombinatorDescriptor != nil {
+		if e.Format == HTMLDocs {
+			fmt.Fprintf(w, "<p>Type: %s</p>\n", e.docsType(def))
+		} else {
+			fmt.Fprintf(w, "\nType: %s\n", e.docsType(def))
+		}
+		return
+	}
+	required := make(map[string]bool, len(def.Required))
+	for _, p := range def.Required {
+		required[p] = true
+	}
+	if e.Format == HTMLDocs {
+		fmt.Fprintln(w, "<table>")
+		fmt.Fprintln(w, "<tr><th>Property</th><th>Type</th><th>Required</th><th>Description</th></tr>")
+	} else {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "| Property | Type | Required | Description |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	}
+	for _, p := range sortedPropertyNames(def.Properties) {
+		prop := def.Properties[p]
+		var doc string
+		if prop.JSONDescriptor != nil {
+			doc = prop.Description
+		}
+		req := "no"
+		if required[p] {
+			req = "yes"
+		}
+		if e.Format == HTMLDocs {
+			fmt.Fprintf(w, "<tr><td><code>%s</code></td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				html.EscapeString(p), e.docsType(prop), req, strings.Replace(html.EscapeString(doc), "\n", "<br>", -1))
+		} else {
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", p, e.docsType(prop), req, markdownCell(doc))
+		}
+	}
+	if e.Format == HTMLDocs {
+		fmt.Fprintln(w, "</table>")
+	}
+}
+
+// docsType returns the type of the values described by p, as markup.
+func (e DocsEmitter) docsType(p JSONPropertyDescriptor) string {
+	if p.JSONReferenceDescriptor != nil {
+		if name, ok := schemamodel.LocalDefinition(p.Reference); ok {
+			if e.Format == HTMLDocs {
+				return fmt.Sprintf("<a href=\"#%s\">%s</a>", docsAnchor(name), html.EscapeString(name))
+			}
+			return fmt.Sprintf("[%s](#%s)", name, docsAnchor(name))
+		}
+		return e.escape(p.Reference)
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		for _, c := range []struct {
+			alts []JSONPropertyDescriptor
+			sep  string
+		}{{p.AllOf, " and "}, {p.OneOf, " or "}, {p.AnyOf, " or "}} {
+			if len(c.alts) > 0 {
+				types := make([]string, len(c.alts))
+				for i, alt := range c.alts {
+					types[i] = e.docsType(alt)
+				}
+				return strings.Join(types, c.sep)
+			}
+		}
+	}
+	if p.JSONDescriptor == nil || len(p.Type) == 0 {
+		return "any"
+	}
+	t := p.Type
+	switch {
+	case p.Type == "array" && p.JSONArrayDescriptor != nil:
+		t = "array of " + e.docsType(p.Items)
+	case p.Type == "object" && p.JSONMapDescriptor != nil:
+		t = "map of " + e.docsType(p.MapValueType)
+	case len(p.Format) > 0:
+		t += " (" + e.escape(p.Format) + ")"
+	}
+	if len(p.Enum) > 0 {
+		values := make([]string, len(p.Enum))
+		for i, v := range p.Enum {
+			b, _ := json.Marshal(v)
+			values[i] = string(b)
+		}
+		t += ", one of " + e.escape(strings.Join(values, ", "))
+	}
+	if p.AllowNull {
+		t += ", nullable"
+	}
+	return t
+}
+
+func (e DocsEmitter) escape(s string) string {
+	if e.Format == HTMLDocs {
+		return html.EscapeString(s)
+	}
+	return markdownCell(s)
+}
+
+// markdownCell escapes s to fit a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(strings.TrimRight(s, "\n"), "\n", "<br>", -1)
+}
+
+// docsAnchor returns the anchor of the section of name, as GitHub derives
+// it from the heading.
+func docsAnchor(name string) string {
+	var b bytes.Buffer
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
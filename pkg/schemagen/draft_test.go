@@ -0,0 +1,116 @@
+package schemagen
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+type draftItem struct {
+	Name  string  `json:"name"`
+	Ratio float64 `json:"ratio" schemagen:"exclusiveMinimum=0,maximum=1"`
+}
+
+type draftRoot struct {
+	Items  []draftItem          `json:"items"`
+	ByName map[string]draftItem `json:"byName,omitempty"`
+	Count  uint16               `json:"count"`
+	Next   *draftRoot           `json:"next,omitempty"`
+}
+
+// TestDraftGoldenFiles compares the schema emitted in each draft with its
+// golden file in testdata/drafts, rewritten by go test -update.
+func TestDraftGoldenFiles(t *testing.T) {
+	for _, test := range []struct {
+		draft Draft
+		name  string
+	}{{Draft04, "draft-04"}, {Draft07, "draft-07"}, {Draft2020, "2020-12"}} {
+		schema, err := GenerateSchemaWithOptions(reflect.TypeOf(draftRoot{}), Options{Draft: test.draft})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b bytes.Buffer
+		if err := (JSONSchemaEmitter{Indent: "  "}).Emit(schema, &b); err != nil {
+			t.Fatal(err)
+		}
+		b.WriteString("\n")
+		golden := filepath.Join("testdata", "drafts", test.name+".json")
+		if *update {
+			if err := ioutil.WriteFile(golden, b.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b.Bytes(), want) {
+			t.Errorf("the %s schema differs from %s:\n%s", test.name, golden, b.String())
+		}
+	}
+}
+
+type draftShape interface {
+	draftShape()
+}
+
+type draftCircle struct {
+	Radius float64 `json:"radius" schemagen:"exclusiveMinimum=0"`
+}
+
+func (draftCircle) draftShape() {}
+
+type draftSquare struct {
+	Side float64 `json:"side" schemagen:"exclusiveMinimum=0"`
+}
+
+func (draftSquare) draftShape() {}
+
+type draftCanvas struct {
+	Circles map[string]draftCircle `json:"circles" schemagen:"keyPattern=^[a-z]+$,patternProperties"`
+	Weights map[string]struct {
+		Weight float64 `json:"weight" schemagen:"exclusiveMaximum=1"`
+	} `json:"weights" schemagen:"keyPattern=^[a-z]+$,patternProperties"`
+	Main draftShape `json:"main"`
+}
+
+// TestDraftPatternProperties checks that the schemas nested in
+// patternProperties and the discriminator mappings are converted too.
+func TestDraftPatternProperties(t *testing.T) {
+	opts := Options{
+		Draft:         Draft2020,
+		Discriminator: "kind",
+		Setup: func(gen *Generator) {
+			gen.RegisterImplementations(reflect.TypeOf((*draftShape)(nil)).Elem(), reflect.TypeOf(draftCircle{}), reflect.TypeOf(draftSquare{}))
+		},
+	}
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(draftCanvas{}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := (JSONSchemaEmitter{Indent: "  "}).Emit(schema, &b); err != nil {
+		t.Fatal(err)
+	}
+	b.WriteString("\n")
+	golden := filepath.Join("testdata", "drafts", "2020-12-pattern.json")
+	if *update {
+		if err := ioutil.WriteFile(golden, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("the schema differs from %s:\n%s", golden, b.String())
+	}
+}
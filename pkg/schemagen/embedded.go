@@ -0,0 +1,41 @@
+package schemagen
+
+import "reflect"
+
+// DefaultEmbeddedObjectTypes maps the Kubernetes RawExtension types to the
+// Java type used for embedded resources.
+var DefaultEmbeddedObjectTypes = map[string]string{
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/runtime.RawExtension": "io.fabric8.kubernetes.api.model.HasMetadata",
+	"k8s.io/apimachinery/pkg/runtime.RawExtension":                       "io.fabric8.kubernetes.api.model.HasMetadata",
+}
+
+func typeKey(t reflect.Type) string {
+	return t.PkgPath() + "." + t.Name()
+}
+
+// embeddedObjectDescriptor describes t as a free-form embedded resource if
+// it is one of the configured embedded object types.
+func (g *schemaGenerator) embeddedObjectDescriptor(t reflect.Type) (JSONPropertyDescriptor, bool) {
+	types := g.opts.EmbeddedObjectTypes
+	if types == nil {
+		types = DefaultEmbeddedObjectTypes
+	}
+	javaType, ok := types[typeKey(t)]
+	if !ok {
+		return JSONPropertyDescriptor{}, false
+	}
+	return JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type: "object",
+		},
+		JSONObjectDescriptor: &JSONObjectDescriptor{
+			AdditionalProperties: true,
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: javaType,
+		},
+		EmbeddedResourceDescriptor: &EmbeddedResourceDescriptor{
+			EmbeddedResource: true,
+		},
+	}, true
+}
@@ -0,0 +1,119 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Emitter renders a generated schema into an artifact such as a JSON
+// Schema document. Emitters run concurrently over the same schema and must
+// not modify it.
+type Emitter interface {
+	Emit(schema *JSONSchema, w io.Writer) error
+}
+
+// EmitterFunc adapts a function to the Emitter interface.
+type EmitterFunc func(schema *JSONSchema, w io.Writer) error
+
+func (f EmitterFunc) Emit(schema *JSONSchema, w io.Writer) error {
+	return f(schema, w)
+}
+
+// JSONSchemaEmitter writes the schema as a JSON Schema document, indented
+// by Indent if it is not empty.
+type JSONSchemaEmitter struct {
+	Indent string
+}
+
+func (e JSONSchemaEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	var b []byte
+	var err error
+	if len(e.Indent) > 0 {
+		b, err = json.MarshalIndent(schema, "", e.Indent)
+	} else {
+		b, err = json.Marshal(schema)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// DraftEmitter writes the schema as a JSON Schema document of Draft,
+// indented by Indent if it is not empty. Running several of them through
+// Emit produces the same model in several drafts from a single walk, e.g.
+// draft-04 for jsonschema2pojo along 2020-12 for validators.
+type DraftEmitter struct {
+	Draft  Draft
+	Indent string
+}
+
+func (e DraftEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	if schema.Draft != e.Draft {
+		converted := *schema
+		converted.Draft = e.Draft
+		converted.Schema = e.Draft.URI()
+		schema = &converted
+	}
+	return JSONSchemaEmitter{Indent: e.Indent}.Emit(schema, w)
+}
+
+// CommentHeader wraps an emitter of a format supporting line comments,
+// such as YAML, TypeScript, Java or C++, so that its artifact starts with
+// a header comment, e.g. a license or ownership notice. Every line of
+// Header is prefixed with Prefix, e.g. "# " or "// ".
+type CommentHeader struct {
+	Emitter Emitter
+	Header  string
+	Prefix  string
+}
+
+func (e CommentHeader) Emit(schema *JSONSchema, w io.Writer) error {
+	if len(e.Header) > 0 {
+		var b bytes.Buffer
+		for _, line := range strings.Split(strings.TrimRight(e.Header, "\n"), "\n") {
+			b.WriteString(strings.TrimRight(e.Prefix+line, " ") + "\n")
+		}
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return e.Emitter.Emit(schema, w)
+}
+
+// Output pairs an emitter with the writer receiving its artifact.
+type Output struct {
+	Emitter Emitter
+	Writer  io.Writer
+}
+
+// Emit walks t once and runs the emitters of all outputs concurrently over
+// the resulting schema, so every artifact describes the same model. It
+// returns the first error reported by an emitter.
+func Emit(t reflect.Type, opts Options, outputs ...Output) error {
+	schema, err := GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, len(outputs))
+	var wg sync.WaitGroup
+	for i, o := range outputs {
+		wg.Add(1)
+		go func(i int, o Output) {
+			defer wg.Done()
+			errs[i] = o.Emitter.Emit(schema, o.Writer)
+		}(i, o)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package schemagen
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// enumType holds the values of an enum type and, when they were read from
+// its constants, the names of the constants and the deprecated values.
+type enumType struct {
+	values     []string
+	names      []string
+	deprecated []string
+}
+
+// enumConstant is an exported string constant of a named type.
+type enumConstant struct {
+	name  string
+	value string
+}
+
+// RegisterEnum describes the values of the named string type t as one of
+// values, like Options.Enums.
+func (gen *Generator) RegisterEnum(t reflect.Type, values ...string) {
+	gen.g.enums[t] = &enumType{values: values}
+}
+
+// enum returns the values of the enum type t: the registered ones, the
+// ones of Options.Enums or, with Options.EnumConstants, the values of the
+// exported constants of t declared by its package.
+func (g *schemaGenerator) enum(t reflect.Type) (*enumType, bool) {
+	if e, ok := g.enums[t]; ok {
+		return e, e != nil
+	}
+	var e *enumType
+	if values, ok := g.opts.Enums[t]; ok {
+		e = &enumType{values: values}
+	} else if g.opts.EnumConstants && t.Kind() == reflect.String && len(t.PkgPath()) > 0 {
+		docs := g.packageDocs(t)
+		for _, c := range docs.constants[t.Name()] {
+			if e == nil {
+				e = &enumType{}
+			}
+			e.values = append(e.values, c.value)
+			e.names = append(e.names, c.name)
+			if docs.deprecated[c.name] {
+				e.deprecated = append(e.deprecated, c.value)
+			}
+		}
+	}
+	g.enums[t] = e
+	return e, e != nil
+}
+
+// applyEnumHints adds the names of the constants of e and its deprecated
+// values to the descriptor of the enum.
+func applyEnumHints(desc *JSONPropertyDescriptor, e *enumType) {
+	if len(e.names) > 0 {
+		desc.JavaHintsDescriptor = &JavaHintsDescriptor{
+			JavaEnumNames: e.names,
+		}
+	}
+	if len(e.deprecated) > 0 {
+		desc.DeprecatedValuesDescriptor = &DeprecatedValuesDescriptor{
+			DeprecatedValues: e.deprecated,
+		}
+	}
+}
+
+// stringConstants returns the exported constants of a const declaration
+// whose values are string literals, e.g. PodPending PodPhase = "Pending" or
+// PodPending = PodPhase("Pending").
+func stringConstants(decl *ast.GenDecl) []enumConstant {
+	var constants []enumConstant
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || len(vs.Names) != len(vs.Values) {
+			continue
+		}
+
+		for i, name := range vs.Names {
+			value := vs.Values[i]
+			if call, ok := value.(*ast.CallExpr); ok && len(call.Args) == 1 {
+				value = call.Args[0]
+			}
+			lit, ok := value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING || !name.IsExported() {
+				continue
+			}
+			if s, err := strconv.Unquote(lit.Value); err == nil {
+				constants = append(constants, enumConstant{name: name.Name, value: s})
+			}
+		}
+	}
+	return constants
+}
+
+// deprecatedConstants returns the names of the constants of a const
+// declaration whose doc comment has a paragraph starting with
+// "Deprecated:", following the Go convention.
+func deprecatedConstants(decl *ast.GenDecl) []string {
+	var names []string
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		doc := vs.Doc
+		if !decl.Lparen.IsValid() {
+			doc = decl.Doc
+		}
+		if !isDeprecated(doc.Text()) {
+			continue
+		}
+		for _, name := range vs.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+// isDeprecated tells whether a doc comment has a paragraph starting with
+// "Deprecated:".
+func isDeprecated(text string) bool {
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated:") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Example builds a sample document of the definition with the given name,
+// for operators to edit when writing manifests by hand. Every property is
+// filled in, required ones included, and enums hold a placeholder listing
+// their allowed values, e.g. "<Always|Never>".
+func Example(schema *JSONSchema, definition string) (interface{}, error) {
+	def, ok := schema.Definitions[definition]
+	if !ok {
+		return nil, fmt.Errorf("Unknown definition %s.", definition)
+	}
+	f := newFixtures(schema, definition)
+	f.placeholders = true
+	return f.value(def), nil
+}
+
+// FindDefinitions returns the sorted names of the definitions of schema
+// named after the type name, i.e. equal to it or ending with "_" and it,
+// such as os_route_Route for Route.
+func FindDefinitions(schema *JSONSchema, typeName string) []string {
+	var names []string
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		if name == typeName || strings.HasSuffix(name, "_"+typeName) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GenerateExample walks t like GenerateSchemaWithOptions and returns an
+// indented JSON document of t valid against its schema, for tests and
+// documentation, built like the contract fixtures. Recursive references
+// are left out. The document is checked with Verify.
+func GenerateExample(t reflect.Type, opts Options) ([]byte, error) {
+	schema, err := GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return nil, err
+	}
+	root := JSONPropertyDescriptor{
+		JSONDescriptor:       &schema.JSONDescriptor,
+		JSONObjectDescriptor: schema.JSONObjectDescriptor,
+	}
+	b, err := json.MarshalIndent(newFixtures(schema).value(root), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(schema, json.RawMessage(b)); err != nil {
+		return nil, fmt.Errorf("Unable to build a valid example of %s. %v", t, err)
+	}
+	return b, nil
+}
+
+// exampleFormats are the examples of strings of common formats.
+var exampleFormats = map[string]string{
+	"byte":      "dmFsdWU=",
+	"date":      "2015-01-01",
+	"date-time": "2015-01-01T00:00:00Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com/",
+	"uuid":      "123e4567-e89b-12d3-a456-426614174000",
+}
+
+// exampleString returns a string of the format of d, if known, or a sample
+// string within its length bounds.
+func exampleString(d *JSONDescriptor) string {
+	if s, ok := exampleFormats[d.Format]; ok && fitsLength(s, d) {
+		return s
+	}
+	return sampleString(d)
+}
+
+// exampleNumber returns 1, or 1.5 for numbers so that they do not read as
+// integers, if within the bounds of d, else a value between them: the
+// midpoint if both are set, or the bound itself, moved by one if exclusive.
+func exampleNumber(d *JSONDescriptor) interface{} {
+	v := 1.5
+	if d.Type == "integer" {
+		v = 1
+	}
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if d.Minimum != nil {
+		lo = *d.Minimum
+	}
+	if d.Maximum != nil {
+		hi = *d.Maximum
+	}
+	if d.Type == "integer" {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+		if d.ExclusiveMinimum && d.Minimum != nil && lo == *d.Minimum {
+			lo++
+		}
+		if d.ExclusiveMaximum && d.Maximum != nil && hi == *d.Maximum {
+			hi--
+		}
+		switch {
+		case v < lo:
+			v = lo
+		case v > hi:
+			v = hi
+		}
+		return int64(v)
+	}
+	below := v < lo || v == lo && d.ExclusiveMinimum
+	above := v > hi || v == hi && d.ExclusiveMaximum
+	switch {
+	case !below && !above:
+	case d.Minimum != nil && d.Maximum != nil:
+		v = lo + (hi-lo)/2
+	case below:
+		v = lo
+		if d.ExclusiveMinimum {
+			v++
+		}
+	default:
+		v = hi
+		if d.ExclusiveMaximum {
+			v--
+		}
+	}
+	return v
+}
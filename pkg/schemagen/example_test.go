@@ -0,0 +1,127 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExampleNumber(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	for _, test := range []struct {
+		desc JSONDescriptor
+		want interface{}
+	}{
+		{JSONDescriptor{Type: "integer"}, int64(1)},
+		{JSONDescriptor{Type: "number"}, 1.5},
+		{JSONDescriptor{Type: "number", Minimum: f(0.5), Maximum: f(1), ExclusiveMaximum: true}, 0.75},
+		{JSONDescriptor{Type: "number", Minimum: f(2), Maximum: f(4)}, 3.0},
+		{JSONDescriptor{Type: "number", Minimum: f(2), ExclusiveMinimum: true}, 3.0},
+		{JSONDescriptor{Type: "number", Maximum: f(-1)}, -1.0},
+		{JSONDescriptor{Type: "number", Maximum: f(1.5), ExclusiveMaximum: true}, 0.5},
+		{JSONDescriptor{Type: "integer", Minimum: f(1), ExclusiveMinimum: true}, int64(2)},
+		{JSONDescriptor{Type: "integer", Maximum: f(1), ExclusiveMaximum: true}, int64(0)},
+		{JSONDescriptor{Type: "integer", Minimum: f(2.5), Maximum: f(10)}, int64(3)},
+		{JSONDescriptor{Type: "integer", Minimum: f(-10), Maximum: f(-2.5)}, int64(-3)},
+	} {
+		if v := exampleNumber(&test.desc); v != test.want {
+			t.Errorf("%+v: expected %v, got %v", test.desc, test.want, v)
+		}
+	}
+}
+
+type examplePhase string
+
+type exampleItem struct {
+	Ratio  float64 `json:"ratio" schemagen:"minimum=0.5,exclusiveMaximum=1"`
+	Weight int32   `json:"weight" schemagen:"exclusiveMinimum=10,maximum=20"`
+	Name   string  `json:"name" schemagen:"pattern=^[a-z]{3}-[0-9]$"`
+}
+
+type exampleDocument struct {
+	Replicas int32                  `json:"replicas" default:"3"`
+	Host     string                 `json:"host" example:"api.example.com"`
+	Phase    examplePhase           `json:"phase"`
+	Items    []exampleItem          `json:"items"`
+	Labels   map[string]string      `json:"labels" schemagen:"keyPattern=^x[0-9]+$"`
+	ByPhase  map[examplePhase]int64 `json:"byPhase"`
+	Next     *exampleDocument       `json:"next,omitempty"`
+}
+
+// TestGenerateExample checks that examples honour defaults, examples,
+// enums and bounds and validate against their schema.
+func TestGenerateExample(t *testing.T) {
+	opts := Options{Enums: map[reflect.Type][]string{reflect.TypeOf(examplePhase("")): {"Pending", "Running"}}}
+	b, err := GenerateExample(reflect.TypeOf(exampleDocument{}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc exampleDocument
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Replicas != 3 || doc.Host != "api.example.com" || doc.Phase != "Pending" {
+		t.Errorf("expected the default, example and first enum value, got %s", b)
+	}
+	if len(doc.Items) != 1 || doc.Items[0].Ratio != 0.75 || doc.Items[0].Weight != 11 {
+		t.Errorf("expected one item within its bounds, got %s", b)
+	}
+	if len(doc.Labels) != 1 || len(doc.ByPhase) != 1 {
+		t.Errorf("expected one entry per map, got %s", b)
+	}
+}
+
+func TestFixturesArrayBounds(t *testing.T) {
+	two, none := 2, 0
+	f := newFixtures(&JSONSchema{})
+	item := JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: "string"}}
+	for _, test := range []struct {
+		array JSONArrayDescriptor
+		want  int
+	}{
+		{JSONArrayDescriptor{Items: item}, 1},
+		{JSONArrayDescriptor{Items: item, MinItems: &two}, 2},
+		{JSONArrayDescriptor{Items: item, MaxItems: &none}, 0},
+	} {
+		array := test.array
+		p := JSONPropertyDescriptor{
+			JSONDescriptor:      &JSONDescriptor{Type: "array"},
+			JSONArrayDescriptor: &array,
+		}
+		if items := f.value(p).([]interface{}); len(items) != test.want {
+			t.Errorf("%+v: expected %d items, got %v", test.array, test.want, items)
+		}
+	}
+}
+
+// TestContractFixturesVerify checks that every contract fixture validates
+// against its definition.
+func TestContractFixturesVerify(t *testing.T) {
+	opts := Options{Enums: map[reflect.Type][]string{reflect.TypeOf(examplePhase("")): {"Pending", "Running"}}}
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(exampleDocument{}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ContractFixtures(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		Fixtures []ContractFixture `json:"fixtures"`
+	}
+	if err := json.Unmarshal(files[ContractManifest], &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Fixtures) == 0 {
+		t.Fatal("expected fixtures")
+	}
+	for _, fixture := range manifest.Fixtures {
+		def := schema.Definitions[fixture.Definition]
+		root := *schema
+		root.JSONDescriptor = *def.JSONDescriptor
+		root.JSONObjectDescriptor = def.JSONObjectDescriptor
+		if err := Verify(&root, json.RawMessage(files[fixture.File])); err != nil {
+			t.Errorf("%s: %v", fixture.File, err)
+		}
+	}
+}
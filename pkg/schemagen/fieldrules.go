@@ -0,0 +1,57 @@
+package schemagen
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldRule changes how a field is described without touching its struct
+// tags, e.g. for the types of third-party packages. Rules are keyed by
+// "<definition name>.<property name>", the property name being the one
+// derived from the tags, e.g. "v1_PodSpec.nodeSelector".
+type FieldRule struct {
+	// Exclude leaves the field out, like a json:"-" tag.
+	Exclude bool `yaml:"exclude"`
+	// Name renames the property of the field.
+	Name string `yaml:"name"`
+	// Opaque describes the field as a free-form object without walking
+	// its type, like the any=object tag option.
+	Opaque bool `yaml:"opaque"`
+}
+
+// fieldRule returns the rule of the field of t whose property is name,
+// recording that it applies.
+func (g *schemaGenerator) fieldRule(t reflect.Type, name string) FieldRule {
+	if len(g.opts.FieldRules) == 0 {
+		return FieldRule{}
+	}
+	return g.namedFieldRule(g.qualifiedName(t) + "." + name)
+}
+
+func (g *schemaGenerator) namedFieldRule(key string) FieldRule {
+	rule, ok := g.opts.FieldRules[key]
+	if ok {
+		if g.appliedRules == nil {
+			g.appliedRules = make(map[string]bool)
+		}
+		g.appliedRules[key] = true
+	}
+	return rule
+}
+
+// checkFieldRules reports the rules of the definitions of s which matched
+// no field, such as misspelled property names.
+func (g *schemaGenerator) checkFieldRules(s *JSONSchema) {
+	var unknown []string
+	for key := range g.opts.FieldRules {
+		definition := strings.SplitN(key, ".", 2)[0]
+		if _, ok := s.Definitions[definition]; ok && !g.appliedRules[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		g.warnf("Unknown field %s of the field rules.", key)
+	}
+}
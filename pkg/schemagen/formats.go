@@ -0,0 +1,175 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"time"
+)
+
+// Format describes a type emitted as a string with a JSON Schema format.
+type Format struct {
+	// Name is the value of the format keyword, e.g. "date-time".
+	Name string
+	// AnyOf, if set instead of Name, lists formats any of which the values
+	// have, e.g. "ipv4" and "ipv6", emitted as an anyOf of formats.
+	AnyOf []string
+	// Pattern optionally restricts the values with a regular expression,
+	// catching malformed values such as "5 minutes" for a duration.
+	Pattern string
+	// Hint is emitted as the x-format extension, naming formats that JSON
+	// Schema does not define, e.g. "duration".
+	Hint string
+	// JavaType optionally sets the Java type used for the values.
+	JavaType string
+}
+
+// DurationFormat describes strings holding a Go duration such as "5m" or
+// "1h30m".
+var DurationFormat = Format{
+	Pattern: `^(0|[-+]?(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+)$`,
+	Hint:    "duration",
+}
+
+// BigFloatFormat describes the strings holding an arbitrary-precision
+// number, such as the text of a big.Float, e.g. "1.5" or "-2e+100".
+var BigFloatFormat = Format{
+	Pattern:  `^[-+]?(Inf|([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][-+]?[0-9]+)?)$`,
+	JavaType: "java.math.BigDecimal",
+}
+
+// BigIntFormat describes the strings holding an arbitrary-precision
+// integer. It is meant for types marshalling a big.Int as a string, e.g.
+// in resource accounting, since encoding/json writes a big.Int itself as a
+// bare number.
+var BigIntFormat = Format{
+	Pattern:  `^[-+]?[0-9]+$`,
+	JavaType: "java.math.BigInteger",
+}
+
+// IPFormat describes the text of a net.IP, an IPv4 or an IPv6 address.
+var IPFormat = Format{AnyOf: []string{"ipv4", "ipv6"}}
+
+// DefaultTypeFormats maps well-known types marshalled as strings, keyed by
+// "<package path>.<name>", to their format: IP addresses, URLs and the
+// UUIDs of the common UUID packages.
+var DefaultTypeFormats = map[string]Format{
+	"net.IP":                              IPFormat,
+	"net/url.URL":                         {Name: "uri"},
+	"github.com/google/uuid.UUID":         {Name: "uuid"},
+	"github.com/gofrs/uuid.UUID":          {Name: "uuid"},
+	"github.com/satori/go.uuid.UUID":      {Name: "uuid"},
+	"github.com/pborman/uuid.UUID":        {Name: "uuid"},
+	"code.google.com/p/go-uuid/uuid.UUID": {Name: "uuid"},
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	bigIntType     = reflect.TypeOf(big.Int{})
+	bigFloatType   = reflect.TypeOf(big.Float{})
+)
+
+// isTimeWrapper reports whether t is a struct whose only field is an
+// embedded time.Time, such as the Kubernetes Time and MicroTime types.
+func isTimeWrapper(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() != 1 {
+		return false
+	}
+	f := t.Field(0)
+	return f.Anonymous && f.Type == timeType
+}
+
+// isBytes reports whether t is a byte slice, which encoding/json encodes as
+// a base64 string. json.RawMessage holds raw JSON instead.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 && t != rawMessageType
+}
+
+// isByteArray reports whether t is a fixed-size byte array, see
+// Options.ByteArraysAsStrings.
+func isByteArray(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8
+}
+
+// typeFormat returns the format of the named type key, "<package
+// path>.<name>", from Options.TypeFormats or DefaultTypeFormats.
+func (g *schemaGenerator) typeFormat(key string) (Format, bool) {
+	format, ok := g.opts.TypeFormats[key]
+	if !ok {
+		format, ok = DefaultTypeFormats[key]
+	}
+	return format, ok
+}
+
+// formatDescriptor describes t as a formatted string if it is registered in
+// Options.Formats or Options.TypeFormats, is one of DefaultTypeFormats, is
+// a time.Time or a wrapper of it, is a big.Float, is a byte slice, or is a
+// byte array under Options.ByteArraysAsStrings. A big.Int, which
+// encoding/json writes as a bare number, is described as an integer of
+// arbitrary precision.
+func (g *schemaGenerator) formatDescriptor(t reflect.Type) (JSONPropertyDescriptor, bool) {
+	format, ok := g.opts.Formats[t]
+	if !ok && len(t.Name()) > 0 {
+		format, ok = g.typeFormat(typeKey(t))
+	}
+	if !ok {
+		switch {
+		case t == timeType || isTimeWrapper(t):
+			format = Format{Name: "date-time", JavaType: g.opts.TimeJavaType}
+		case t == bigFloatType:
+			format = BigFloatFormat
+		case t == bigIntType:
+			return JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type: "integer",
+				},
+				JavaTypeDescriptor: &JavaTypeDescriptor{
+					JavaType: "java.math.BigInteger",
+				},
+			}, true
+		case isBytes(t):
+			format = Format{Name: "byte"}
+		case g.opts.ByteArraysAsStrings && isByteArray(t):
+			format = Format{}
+		default:
+			return JSONPropertyDescriptor{}, false
+		}
+	}
+	return formatString(format), true
+}
+
+// formatString describes the strings of format.
+func formatString(format Format) JSONPropertyDescriptor {
+	desc := JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type:    "string",
+			Format:  format.Name,
+			Pattern: format.Pattern,
+		},
+	}
+	if len(format.Hint) > 0 {
+		desc.FormatHintDescriptor = &FormatHintDescriptor{
+			XFormat: format.Hint,
+		}
+	}
+	if len(format.AnyOf) > 0 {
+		alts := make([]JSONPropertyDescriptor, len(format.AnyOf))
+		for i, name := range format.AnyOf {
+			alts[i] = JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Format: name,
+				},
+			}
+		}
+		desc.JSONCombinatorDescriptor = &JSONCombinatorDescriptor{
+			AnyOf: alts,
+		}
+	}
+	if len(format.JavaType) > 0 {
+		desc.JavaTypeDescriptor = &JavaTypeDescriptor{
+			JavaType: format.JavaType,
+		}
+	}
+	return desc
+}
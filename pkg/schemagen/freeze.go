@@ -0,0 +1,110 @@
+package schemagen
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// FreezeLock records the frozen definitions of a schema, see
+// Options.Frozen. It is committed along the configuration.
+type FreezeLock struct {
+	Definitions map[string]FrozenDefinition `json:"definitions"`
+}
+
+// FrozenDefinition is a definition recorded in a lock file, along with the
+// hash of its canonical form.
+type FrozenDefinition struct {
+	Hash   string          `json:"hash"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// LoadFreezeLock reads the lock file at path. A missing file yields an
+// empty lock, in which no definition is recorded.
+func LoadFreezeLock(path string) (*FreezeLock, error) {
+	lock := &FreezeLock{Definitions: make(map[string]FrozenDefinition)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("Unable to parse lock file %s: %v", path, err)
+	}
+	return lock, nil
+}
+
+// NewFreezeLock records the named definitions of s.
+func NewFreezeLock(s *JSONSchema, names []string) (*FreezeLock, error) {
+	lock := &FreezeLock{Definitions: make(map[string]FrozenDefinition)}
+	for _, name := range names {
+		def, ok := s.Definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown frozen definition %s.", name)
+		}
+		frozen, err := freezeDefinition(def)
+		if err != nil {
+			return nil, err
+		}
+		lock.Definitions[name] = frozen
+	}
+	return lock, nil
+}
+
+func freezeDefinition(def JSONPropertyDescriptor) (FrozenDefinition, error) {
+	b, err := json.Marshal(def)
+	if err != nil {
+		return FrozenDefinition{}, err
+	}
+	canonical, err := Canonicalize(b)
+	if err != nil {
+		return FrozenDefinition{}, err
+	}
+	return FrozenDefinition{
+		Hash:   fmt.Sprintf("sha256:%x", sha256.Sum256(canonical)),
+		Schema: json.RawMessage(canonical),
+	}, nil
+}
+
+// checkFrozen fails if one of the frozen definitions of s is missing from
+// the lock, or differs from the copy recorded in it, listing the
+// differences.
+func (g *schemaGenerator) checkFrozen(s *JSONSchema) error {
+	var problems []string
+	for _, name := range g.opts.Frozen {
+		def, ok := s.Definitions[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("frozen definition %s is no longer generated", name))
+			continue
+		}
+		var locked FrozenDefinition
+		if g.opts.FreezeLock != nil {
+			locked, ok = g.opts.FreezeLock.Definitions[name]
+		}
+		if !ok {
+			problems = append(problems, fmt.Sprintf("frozen definition %s is not recorded in the lock file", name))
+			continue
+		}
+		current, err := freezeDefinition(def)
+		if err != nil {
+			return err
+		}
+		if current.Hash == locked.Hash {
+			continue
+		}
+		diffs, err := CompareJSON(locked.Schema, current.Schema)
+		if err != nil {
+			return err
+		}
+		problems = append(problems, fmt.Sprintf("frozen definition %s changed: %s", name, strings.Join(diffs, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Frozen definitions check failed: %s.", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -3,239 +3,1709 @@ package schemagen
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type PackageDescriptor struct {
-	GoPackage   string
-	JavaPackage string
-	Prefix      string
+	GoPackage   string `yaml:"goPackage"`
+	JavaPackage string `yaml:"javaPackage"`
+	Prefix      string `yaml:"prefix"`
+
+	// TypePrefixes overrides Prefix for some of the types of the package,
+	// keyed by type name, e.g. to tell config types from runtime types.
+	TypePrefixes map[string]string `yaml:"typePrefixes"`
+
+	// SchemaURL, if set, is the URL of a schema generated separately with
+	// this descriptor. The types of the package are then referenced there,
+	// e.g. "https://example.com/k8s-v1.json#/definitions/v1Pod", rather
+	// than defined, so that combined schemas can be split into layered
+	// documents.
+	SchemaURL string `yaml:"schemaURL"`
+
+	// Group and Version name the API group/version the package belongs
+	// to, the empty group being the core one. They are used to split
+	// definitions into per-group documents, are available to
+	// Options.IDTemplate as {{.Group}} and {{.Version}} for the root type
+	// of the package, and, if Prefix is unset, prefix the definition names
+	// of the package as "<group>_<version>_", the dots of the group
+	// replaced, e.g. "route_openshift_io_v1_Route", or "<version>_" for
+	// the core group, so that the versions of a multi-version API do not
+	// collide.
+	Group   string `yaml:"group"`
+	Version string `yaml:"version"`
+
+	// Kinds names the types of the package which are resources of the
+	// group/version, e.g. "Route", whose definitions are given an
+	// x-kubernetes-group-version-kind extension with the type name as
+	// kind, unless Options.GroupVersionKinds lists theirs, for Kubernetes
+	// OpenAPI aggregation.
+	Kinds []string `yaml:"kinds"`
+
+	// JavaAnnotations are added as customAnnotations hints to every
+	// definition of the package, e.g. "@JsonInclude(NON_NULL)".
+	JavaAnnotations []string `yaml:"javaAnnotations"`
+
+	// JavaInterfaces, GenerateBuilders and JavaSerializable are emitted
+	// as the javaInterfaces, generateBuilders and javaSerializable hints
+	// of every object definition of the package, e.g. to have the classes
+	// implement io.fabric8.kubernetes.api.model.KubernetesResource.
+	JavaInterfaces   []string `yaml:"javaInterfaces"`
+	GenerateBuilders bool     `yaml:"generateBuilders"`
+	JavaSerializable bool     `yaml:"javaSerializable"`
+}
+
+// ValidatePackages checks that no two descriptors describe the same Go
+// package and that packages sharing a prefix also share their Java package,
+// since their types would otherwise get colliding definition names mapped
+// to different Java classes, counting the prefixes of single types too.
+// The error enumerates every conflict.
+func ValidatePackages(packages []PackageDescriptor) error {
+	var conflicts []string
+	goPackages := make(map[string]bool)
+	prefixes := make(map[string]PackageDescriptor)
+	for _, p := range packages {
+		if goPackages[p.GoPackage] {
+			conflicts = append(conflicts, fmt.Sprintf("%s is described more than once", p.GoPackage))
+		}
+		goPackages[p.GoPackage] = true
+		var typePrefixes []string
+		for _, prefix := range p.TypePrefixes {
+			typePrefixes = append(typePrefixes, prefix)
+		}
+		sort.Strings(typePrefixes)
+		for _, prefix := range append([]string{p.definitionPrefix()}, typePrefixes...) {
+			if other, ok := prefixes[prefix]; ok && other.JavaPackage != p.JavaPackage {
+				conflicts = append(conflicts, fmt.Sprintf("%s and %s share prefix %q but map to Java packages %s and %s",
+					other.GoPackage, p.GoPackage, prefix, other.JavaPackage, p.JavaPackage))
+				continue
+			}
+			prefixes[prefix] = p
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("Conflicting package descriptors: %s.", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// Options controls how a schema is generated.
+type Options struct {
+	Packages []PackageDescriptor
+	TypeMap  map[reflect.Type]reflect.Type
+
+	// BaseURI prefixes the ids of generated schemas, DefaultBaseURI if
+	// unset. IDTemplate, if set, is a text/template building the ids
+	// instead, from the BaseURI and the Name of the schema, e.g.
+	// "{{.BaseURI}}schemas/{{.Name}}.json", and from the Group and Version
+	// of its package, see PackageDescriptor. SchemaURI is the $schema of
+	// generated schemas, the URI of Draft if unset.
+	BaseURI    string
+	IDTemplate string
+	SchemaURI  string
+
+	// RootProperties and DefinitionProperties, keyed by definition name,
+	// select whether the root and definitions accept properties they do
+	// not declare, through additionalProperties or unevaluatedProperties.
+	// The latter closes definitions composed through allOf, such as in
+	// ComposeEmbedded mode.
+	RootProperties       PropertiesPolicy
+	DefinitionProperties map[string]PropertiesPolicy
+
+	// DefaultProperties is the policy of the root and of the object
+	// definitions given none by RootProperties, DefinitionProperties or a
+	// "+schemagen:properties=closed" line in the doc comment of their
+	// type, e.g. ClosedProperties for strict validation of payloads.
+	DefaultProperties PropertiesPolicy
+
+	// AdditionalProperties describes the undeclared properties of
+	// definitions, keyed by definition name, instead of accepting or
+	// rejecting them all, e.g. {"type": "string"} for string annotations.
+	AdditionalProperties map[string]JSONPropertyDescriptor
+
+	// InternTypes identifies the types sharing their name and package path
+	// once vendor directories are stripped, e.g. the types of
+	// k8s.io/kubernetes/vendor/github.com/docker/docker/api and of
+	// github.com/docker/docker/api, so that vendored copies share a single
+	// definition, the one of the first type reached. PackageAliases
+	// identifies explicitly the packages at the keys with the ones at the
+	// values.
+	InternTypes    bool
+	PackageAliases map[string]string
+
+	// DisambiguateNames names the definitions of types that would get the
+	// name of another type, such as types of packages sharing a prefix,
+	// after their full package path. Such collisions fail the generation
+	// otherwise, rather than a definition overwriting the other.
+	DisambiguateNames bool
+
+	// Naming selects how the definitions of the types of packages without
+	// a PackageDescriptor are named, PathNames by default. NameFunc, if
+	// set, names them instead, given the import path of their package and
+	// their name. Under ShortNames, a name already given to another type
+	// gets a numeric suffix rather than being a problem.
+	Naming   NamingStrategy
+	NameFunc func(pkgPath, name string) string
+
+	// Components mirrors the definitions into components.schemas, with
+	// references pointing at either, for documents consumed both as JSON
+	// Schemas and as OpenAPI documents.
+	Components ComponentsMode
+
+	// Draft is the version of JSON Schema the schemas are serialized in,
+	// Draft04 as expected by jsonschema2pojo by default. The keywords
+	// differing between drafts, such as definitions and $defs, id and $id
+	// or exclusiveMinimum, are emitted accordingly.
+	Draft Draft
+
+	// KindMappings overrides the JSON and Java types and the formats used
+	// for primitive kinds, e.g. to map every reflect.Int64 to a Java long,
+	// or to describe complex numbers marshalled by custom means.
+	KindMappings map[reflect.Kind]Mapping
+
+	// EmbeddedObjectTypes maps types holding arbitrary embedded objects,
+	// keyed by "<package path>.<name>", to the Java type used for them.
+	// They are emitted as free-form objects marked with
+	// x-kubernetes-embedded-resource instead of being expanded. Defaults to
+	// DefaultEmbeddedObjectTypes when nil.
+	EmbeddedObjectTypes map[string]string
+
+	// UnionTypes maps wrapper types marshalled as one of several JSON
+	// types, keyed by "<package path>.<name>", to the union they are
+	// emitted as, a oneOf of the JSON types with the Java type of the
+	// wrapper. They are consulted before DefaultUnionTypes, which covers
+	// the Kubernetes IntOrString types.
+	UnionTypes map[string]UnionType
+
+	// Formats registers types emitted as strings with a format. Unless
+	// registered otherwise, time.Time and structs whose only field is an
+	// embedded time.Time are emitted as date-time strings, with
+	// TimeJavaType as Java type if set, e.g. "java.time.Instant", and byte
+	// slices as base64 strings of format byte.
+	Formats      map[reflect.Type]Format
+	TimeJavaType string
+
+	// TypeFormats maps named types marshalled as strings, keyed by
+	// "<package path>.<name>", to their format, e.g. the email address
+	// types of a project. They are consulted after Formats and before
+	// DefaultTypeFormats, which covers net.IP, url.URL and UUID types.
+	TypeFormats map[string]Format
+
+	// ByteArraysAsStrings emits fixed-size byte arrays, such as [16]byte,
+	// as strings instead of arrays of integers, for types marshalling
+	// themselves as hex or base64 text.
+	ByteArraysAsStrings bool
+
+	// FormatHeuristics gives the string properties without a format of
+	// their own the standard format their name suggests: uri for names
+	// ending with URL or URI, hostname for Host, ipv4 for IP, email for
+	// Email and uuid for UUID. Fields tagged with a format, a pattern or an enum are left as
+	// they are. FormatHeuristicsReport, if set, is called with
+	// "<definition name>.<property name>" and the format of every guess,
+	// so that they can be reviewed. FieldFormats extends the guesses,
+	// mapping the lower-case last word of property names to their format
+	// before DefaultFieldFormats; an empty format disables a default one.
+	FormatHeuristics       bool
+	FormatHeuristicsReport func(property, format string)
+	FieldFormats           map[string]string
+
+	// Nullable selects the nullability keywords emitted for optional
+	// fields, i.e. pointers and fields tagged omitempty or another
+	// optional tag option.
+	Nullable NullableStyle
+
+	// PrimitiveStyle selects how named types over primitives, such as
+	// type UID string, are emitted. PrimitiveStyles overrides it per type.
+	PrimitiveStyle  PrimitiveStyle
+	PrimitiveStyles map[reflect.Type]PrimitiveStyle
+
+	// DefinitionOverrides maps definition names to handwritten definitions
+	// replacing the generated ones. Raw JSON can be decoded into a
+	// JSONPropertyDescriptor with encoding/json.
+	DefinitionOverrides map[string]JSONPropertyDescriptor
+
+	// Annotations maps definition names to descriptions, examples,
+	// deprecation flags and extensions merged into the definitions, see
+	// LoadAnnotations.
+	Annotations map[string]Annotation
+
+	// JavaTypeByType overrides the Java type of single types. It is
+	// checked before the package descriptors, so a type can map to a
+	// different class than the rest of its package.
+	JavaTypeByType map[reflect.Type]string
+
+	// JavaAnnotations maps definition names to customAnnotations hints
+	// added to the definition after the ones of its package.
+	JavaAnnotations map[string][]string
+
+	// JavaHints, keyed by "<definition name>.<property name>", and
+	// JavaHintsFunc, called for every field, add jsonschema2pojo hints to
+	// single properties, such as another javaType, an existingJavaType or
+	// Jackson annotations. The hints of JavaHints take precedence.
+	JavaHints     map[string]JavaHints
+	JavaHintsFunc func(t reflect.Type, f reflect.StructField) (JavaHints, bool)
+
+	// Labels, keyed by definition name, tag definitions with labels such
+	// as alpha, beta or stable, in addition to the ones declared by a
+	// "+schemagen:labels=beta" line in the doc comment of their type.
+	// LabelFilter, if set, only keeps the labelled definitions carrying one
+	// of its labels, along with the definitions without labels, e.g. to
+	// publish a stable-only schema. Properties referring to the definitions
+	// left out are removed.
+	Labels      map[string][]string
+	LabelFilter []string
+
+	// JavaAcronyms lists the acronyms spelled in capitals in the Java
+	// names of fields, e.g. IP and URL. When set, properties whose name
+	// spells capitals otherwise, e.g. hostIp or podCIDR, get a javaName
+	// hint, e.g. hostIP or podCidr, naming their field and accessors.
+	JavaAcronyms []string
+
+	// Abstract names the definitions of base types only used through
+	// embedding, which are marked x-abstract so that tools generate
+	// abstract classes for them and leave them out of type listings.
+	Abstract map[string]bool
+
+	// GroupVersionKinds lists the Kubernetes resource types described by
+	// definitions, keyed by definition name, emitted as their
+	// x-kubernetes-group-version-kind extension. The root type is looked
+	// up by its would-be definition name.
+	GroupVersionKinds map[string][]GroupVersionKind
+
+	// MapEntries emits maps with non-string keys as arrays of synthesized
+	// entry objects with a key and a value property, matching the custom
+	// marshaling commonly used for them.
+	MapEntries bool
+
+	// CatchAllMaps describes string keyed map fields tagged json:"-" or
+	// json:",inline", which hold the fields of an object not otherwise
+	// listed, as the additionalProperties of the object rather than as a
+	// property.
+	CatchAllMaps bool
+
+	// Titles selects the titles given to the properties and object
+	// definitions lacking a title struct tag, derived from the names of
+	// their Go field or type.
+	Titles TitleStyle
+
+	// NonEmptyRequiredStrings adds minLength: 1 to the string properties
+	// of required fields, i.e. fields neither pointers nor tagged
+	// omitempty, so that empty strings fail validation.
+	NonEmptyRequiredStrings bool
+
+	// PropertyNaming converts the Go names of the fields without a name
+	// in their json tag into the names of their properties, e.g. to
+	// camelCase, and, with RenameTaggedProperties, the names of the tags
+	// too. Such schemas describe the JSON of other serializers than
+	// encoding/json, which keeps the Go names.
+	PropertyNaming         PropertyNaming
+	RenameTaggedProperties bool
+
+	// PatternProperties moves the key patterns of maps, such as the ones
+	// of keyPattern tags, of keys presets or of integer keys, from
+	// propertyNames to patternProperties, which draft-04 validators
+	// understand, rejecting the keys not matching them. The
+	// patternProperties tag option does so for a single field.
+	PatternProperties bool
+
+	// Required lists the properties of the fields that are neither
+	// pointers nor tagged with an optional json option, such as omitempty,
+	// in the required keyword of their object. A required:"true" or
+	// required:"false" struct tag, or RequiredOverrides keyed by
+	// "<definition name>.<property>", overrides it per field.
+	Required          bool
+	RequiredOverrides map[string]bool
+
+	// AnyItems selects how the items of slices and arrays of empty
+	// interfaces are described, the empty schema by default. ItemTypes,
+	// keyed by "<definition name>.<property>", or an items tag option
+	// declares them per field instead, see applyItems.
+	AnyItems  AnyItemsStyle
+	ItemTypes map[string]string
+
+	// DocComments emits the doc comments of struct types and of their
+	// fields, parsed from the package sources located through go/build,
+	// as the descriptions of their definitions and properties. Description
+	// struct tags and annotations take precedence.
+	DocComments bool
+
+	// ComposeEmbedded describes the definitions of structs embedding other
+	// structs as the allOf of the definitions of the embedded structs and
+	// of their own properties, instead of copying the embedded properties,
+	// preserving the inheritance for Java code generation. The root
+	// schema keeps flattened properties.
+	ComposeEmbedded bool
+
+	// Enums registers the allowed values of named string types. Fields of
+	// these types are emitted with an enum and the Java type of the named
+	// type, and maps keyed by them restrict their keys with
+	// propertyNames.
+	Enums map[reflect.Type][]string
+
+	// EnumConstants describes the named string types without registered
+	// values as enums of the values of their exported constants, read from
+	// the sources of their package like doc comments, with the names of
+	// the constants as javaEnumNames hint.
+	EnumConstants bool
+
+	// Coercions, keyed by "<definition name>.<property name>", declare
+	// fields that legacy clients transmit as strings. They are emitted as
+	// oneOf: [<type>, <string encoding the type>] with a canonical Java
+	// type.
+	Coercions map[string]Coercion
+
+	// FieldRules excludes, renames or makes opaque the fields of types
+	// that cannot be tagged, keyed by "<definition name>.<property
+	// name>". Rules matching no field of the generated definitions are
+	// reported through Warn.
+	FieldRules map[string]FieldRule
+
+	// Overrides tweaks the descriptors at the given JSON pointers, e.g.
+	// "/definitions/os_route_Route/properties/host", as the final pass of
+	// the generation, so that pointers address the final schema.
+	Overrides map[string]PropertyOverride
+
+	// Frozen names definitions whose shape must not change, such as the
+	// ones of stable API types. The generation fails, listing the
+	// differences, unless each of them is identical to the copy recorded
+	// in FreezeLock, see NewFreezeLock.
+	Frozen     []string
+	FreezeLock *FreezeLock
+
+	// Refine, if set, is called for every field and may return constraints
+	// applying to the schema of the field at this usage site only, such as
+	// a pattern for names used in a specific parent type. They are emitted
+	// as allOf: [<field schema>, <constraints>] so that shared definitions
+	// are left untouched.
+	Refine func(parent reflect.Type, field reflect.StructField) *JSONPropertyDescriptor
+
+	// Decorators are called in order with every property generated for a
+	// field, once its descriptor is complete, see PropertyDecorator.
+	Decorators []PropertyDecorator
+
+	// Setup, if set, is called with the generator before any type is
+	// walked, e.g. to override the descriptor of a kind or register type
+	// handlers.
+	Setup func(g *Generator)
+
+	// Discriminator is the property telling which implementation of an
+	// interface registered with Generator.RegisterImplementations a value
+	// is, "kind" if unset.
+	Discriminator string
+
+	// Profile tailors the schema to its consumer, the Java model by
+	// default.
+	Profile Profile
+
+	// StandardOnly omits javaType and all vendor extensions, producing a
+	// pure JSON Schema for strict validators and third parties.
+	StandardOnly bool
+
+	// JavaTypeKeyword renames the javaType keyword, e.g. to x-go-type for
+	// validators rejecting unknown keywords, or omits it if "-". The
+	// interfaces, builders and serializable class hints become extensions
+	// of their own name, or are omitted along with it. Emitters relying on
+	// the Java types, such as the contract emitter, then lose them.
+	JavaTypeKeyword string
+
+	// WrapRefs wraps every $ref having sibling keywords, such as javaType,
+	// in an allOf so that strict draft-04 tooling accepts the schema while
+	// the Java hints are preserved.
+	WrapRefs bool
+
+	// Resolver looks up the compiled type of a struct declared in a Go
+	// package. It is used when generating schemas from package sources.
+	Resolver TypeResolver
+
+	// ContentAddressed names definitions after a short hash of their
+	// canonical shape instead of their package prefix and type name, so
+	// that structurally identical types deduplicate across independently
+	// generated schemas.
+	ContentAddressed bool
+
+	// NestDefinitions emits the definitions of types used by a single
+	// parent type under the definitions of that parent.
+	NestDefinitions bool
+
+	// InlineReferences expands every reference to a definition in place,
+	// producing a self-contained schema without definitions for consumers
+	// unable to resolve $ref. The references of recursive types, which
+	// cannot be expanded, become permissive objects. Overrides and frozen
+	// definitions apply before the expansion.
+	InlineReferences bool
+
+	// Emitters registers named emitters of custom artifacts, such as
+	// template emitters, for tools to run by name.
+	Emitters map[string]Emitter
+
+	// Retention selects whether definitions unreachable from the root are
+	// kept. RetentionReport, if set, is called for every definition with
+	// whether it is reachable and whether it was kept.
+	Retention       Retention
+	RetentionReport func(name string, reachable, kept bool)
+
+	// Progress, if set, is called whenever a new type starts being
+	// defined with the number of types reached so far, the total number of
+	// types to define and the definition name of the current one.
+	Progress func(done, total int, current string)
+
+	// BuildMode selects whether the schema records when and where it was
+	// generated. It defaults to Deterministic.
+	BuildMode BuildMode
+
+	// License is emitted as the x-license extension of the root of the
+	// schema, so published schemas carry their attribution. It is kept
+	// by StandardOnly.
+	License string
+
+	// TagOptions registers the effects of json tag options, adding to or
+	// replacing DefaultTagOptions, e.g. for the options of encoding/json
+	// alternatives or of internal conventions.
+	TagOptions map[string]TagOption
+
+	// TagKeys lists the struct tags naming fields and holding their
+	// options, looked up in order, e.g. yaml then json for configuration
+	// structs only carrying yaml tags. Fields without any of them are
+	// named after the Go field. Defaults to json.
+	TagKeys []string
+
+	// Warn, if set, is called for every field that cannot be described
+	// faithfully, such as embedded interfaces, fields sharing a JSON name
+	// or channels and functions, which have no JSON representation. The
+	// messages are also recorded in JSONSchema.Warnings.
+	Warn func(message string)
+
+	// Strict fails the generation with the problems otherwise reported
+	// through Warn, naming the offending fields. All the problems are
+	// returned at once, as a *GenerationError.
+	Strict bool
+
+	// Audit generates every schema twice and fails if both serialize
+	// differently, detecting nondeterminism such as map iteration leaking
+	// into the output or hooks with side effects before schemas are
+	// published. The x-generated stamp is not compared.
+	Audit bool
+
+	// Debug adds an x-debug extension to every definition listing the
+	// chain of fields through which the type was first reached.
+	Debug bool
+
+	// GoPackages adds an x-go-package extension to every definition with
+	// the import path of the package declaring its type, so that
+	// downstream tools can group definitions by source package.
+	GoPackages bool
+
+	// ProtoFields adds x-proto-field-number and x-proto-name extensions
+	// to the properties of fields with a protobuf struct tag.
+	ProtoFields bool
 }
 
 type schemaGenerator struct {
-	types    map[reflect.Type]*JSONObjectDescriptor
+	types    map[reflect.Type]*JSONPropertyDescriptor
 	packages map[string]PackageDescriptor
 	typeMap  map[reflect.Type]reflect.Type
+	kinds    map[reflect.Kind]Mapping
+	opts     Options
+
+	// kindDescriptors replaces the descriptors of the kinds overridden
+	// through Generator.OverrideKind.
+	kindDescriptors map[reflect.Kind]JSONPropertyDescriptor
+
+	// handlers holds the types described by the handlers registered
+	// through Generator.RegisterTypeHandler.
+	handlers map[reflect.Type]TypeHandler
+
+	// implementations holds the types registered for interface types.
+	implementations map[reflect.Type][]reflect.Type
+
+	// path is the chain of fields leading to the type currently being
+	// walked; provenance keeps a copy of it for each newly defined type.
+	path       []string
+	provenance map[reflect.Type][]string
+
+	// synthetic names the definitions of types without a name of their
+	// own, such as map entries.
+	synthetic map[reflect.Type]syntheticType
+
+	// compositions holds the structs embedding other structs in
+	// ComposeEmbedded mode.
+	compositions map[reflect.Type]*composition
+
+	// defining holds the types whose definition is being built, and
+	// expanding and javaExpanding the named slice and map types being
+	// described, to detect types referring to themselves.
+	defining      map[reflect.Type]bool
+	expanding     map[reflect.Type]bool
+	javaExpanding map[reflect.Type]bool
+
+	// javaTypes caches the Java types of the types reached by javaType,
+	// which is called for every occurrence of a field type.
+	javaTypes map[reflect.Type]string
+
+	// enums caches the values of the types looked up as enums, nil for
+	// the types that are not.
+	enums map[reflect.Type]*enumType
+
+	// interned holds the first type reached for every canonical package
+	// path and name, see Options.InternTypes.
+	interned map[string]reflect.Type
+
+	// names holds the definition names given to the types identified by
+	// their canonical package path and name, and nameOwners the type
+	// each name is given to, to detect collisions.
+	names      map[string]string
+	nameOwners map[string]string
+
+	// docs caches the doc comments of packages by import path.
+	docs map[string]*packageDocs
+
+	// pruned holds the types of fields tagged schemagen:"prune".
+	pruned map[reflect.Type]bool
+
+	// appliedRules holds the keys of the Options.FieldRules matching a
+	// field.
+	appliedRules map[string]bool
+
+	// total is the number of types to define, computed by a reachability
+	// pass when progress is reported.
+	total int
+
+	// dependencies holds the types each definition refers to, and
+	// rootTypes the ones the root being generated refers to directly, so
+	// that a generator reused across roots emits the definitions reachable
+	// from the current root only. building is the stack of the types being
+	// defined and added the types defined for the current root.
+	dependencies map[reflect.Type]map[reflect.Type]bool
+	rootTypes    map[reflect.Type]bool
+	building     []reflect.Type
+	added        []reflect.Type
+
+	// errors holds the problems found in Strict mode, warnings the ones
+	// reported otherwise.
+	errors   []FieldProblem
+	warnings []Warning
+
+	// renamed holds the hashed names given to the definitions in
+	// ContentAddressed mode, and nested the top level definition holding
+	// each definition moved by NestDefinitions.
+	renamed map[string]string
+	nested  map[string]string
 }
 
 func GenerateSchema(t reflect.Type, packages []PackageDescriptor, typeMap map[reflect.Type]reflect.Type) (*JSONSchema, error) {
-	g := newSchemaGenerator(packages, typeMap)
-	return g.generate(t)
+	return GenerateSchemaWithOptions(t, Options{Packages: packages, TypeMap: typeMap})
+}
+
+func GenerateSchemaWithOptions(t reflect.Type, opts Options) (*JSONSchema, error) {
+	g := newSchemaGenerator(opts)
+	s, err := g.generate(t)
+	if err != nil || !opts.Audit {
+		return s, err
+	}
+	if err := audit(t, opts, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GenerateSchemas generates a single schema for several root types sharing
+// one definitions section, as consumed by the fabric8 kubernetes-model. The
+// root object has one property per root type, named after it as if the
+// roots were the fields of a struct named Schema.
+func GenerateSchemas(roots []reflect.Type, opts Options) (*JSONSchema, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("At least one root type is required.")
+	}
+	fields := make([]reflect.StructField, 0, len(roots))
+	names := make(map[string]reflect.Type, len(roots))
+	for _, t := range roots {
+		name := t.Name()
+		if r, _ := utf8.DecodeRuneInString(name); !unicode.IsUpper(r) {
+			return nil, fmt.Errorf("Root type %s is not an exported named type.", t)
+		}
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("Root types %s and %s share the name %s.", other, t, name)
+		}
+		names[name] = t
+		fields = append(fields, reflect.StructField{Name: name, Type: t})
+	}
+	s, err := GenerateSchemaWithOptions(reflect.StructOf(fields), opts)
+	if err != nil {
+		return nil, err
+	}
+	if s.ID, err = schemaID(opts, "Schema", PackageDescriptor{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GenerateDefinitions generates the definitions of types, and of the types
+// they refer to, without a root schema, so that callers can merge them into
+// a schema or an OpenAPI document they maintain. References point at
+// #/definitions unless Options.Components sets them to components.schemas.
+// Types without a definition of their own, such as strings, are reported.
+func GenerateDefinitions(types []reflect.Type, opts Options) (map[string]JSONPropertyDescriptor, error) {
+	if len(types) == 0 {
+		return nil, fmt.Errorf("At least one type is required.")
+	}
+	if opts.InlineReferences {
+		return nil, fmt.Errorf("Inlined references leave no definitions to generate.")
+	}
+	fields := make([]reflect.StructField, len(types))
+	for i, t := range types {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("T%d", i),
+			Type: t,
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"t%d"`, i)),
+		}
+	}
+	s, err := GenerateSchemaWithOptions(reflect.StructOf(fields), opts)
+	if err != nil {
+		return nil, err
+	}
+	var undefined []string
+	for i, t := range types {
+		if s.Properties[fmt.Sprintf("t%d", i)].JSONReferenceDescriptor == nil {
+			undefined = append(undefined, t.String())
+		}
+	}
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("Types without a definition: %s.", strings.Join(undefined, ", "))
+	}
+	return s.Definitions, nil
 }
 
-func newSchemaGenerator(packages []PackageDescriptor, typeMap map[reflect.Type]reflect.Type) *schemaGenerator {
+func newSchemaGenerator(opts Options) *schemaGenerator {
+	opts = profileOptions(opts)
 	pkgMap := make(map[string]PackageDescriptor)
-	for _, p := range packages {
+	for _, p := range opts.Packages {
 		pkgMap[p.GoPackage] = p
 	}
 	g := schemaGenerator{
-		types:    make(map[reflect.Type]*JSONObjectDescriptor),
-		packages: pkgMap,
-		typeMap:  typeMap,
+		types:           make(map[reflect.Type]*JSONPropertyDescriptor),
+		packages:        pkgMap,
+		typeMap:         opts.TypeMap,
+		kinds:           newKindMappings(opts.KindMappings),
+		opts:            opts,
+		kindDescriptors: make(map[reflect.Kind]JSONPropertyDescriptor),
+		handlers:        make(map[reflect.Type]TypeHandler),
+		implementations: make(map[reflect.Type][]reflect.Type),
+		provenance:      make(map[reflect.Type][]string),
+		synthetic:       make(map[reflect.Type]syntheticType),
+		compositions:    make(map[reflect.Type]*composition),
+		docs:            make(map[string]*packageDocs),
+		defining:        make(map[reflect.Type]bool),
+		expanding:       make(map[reflect.Type]bool),
+		javaExpanding:   make(map[reflect.Type]bool),
+		javaTypes:       make(map[reflect.Type]string),
+		interned:        make(map[string]reflect.Type),
+		names:           make(map[string]string),
+		nameOwners:      make(map[string]string),
+		enums:           make(map[reflect.Type]*enumType),
+		dependencies:    make(map[reflect.Type]map[reflect.Type]bool),
+	}
+	if opts.Setup != nil {
+		opts.Setup(&Generator{&g})
+	}
+	// The types the generator is configured with identify their copies.
+	for from, to := range g.typeMap {
+		g.intern(from)
+		g.intern(to)
+	}
+	for t := range g.handlers {
+		g.intern(t)
 	}
 	return &g
 }
 
-func getFieldName(f reflect.StructField) string {
-	json := f.Tag.Get("json")
-	if len(json) > 0 {
-		parts := strings.Split(json, ",")
-		return parts[0]
+// NullableStyle is a set of flags selecting the keywords used to mark
+// optional fields as nullable.
+type NullableStyle int
+
+const (
+	// NullableSwagger2 emits the Swagger 2 x-nullable extension.
+	NullableSwagger2 NullableStyle = 1 << iota
+	// NullableOpenAPI3 emits the OpenAPI 3 nullable keyword.
+	NullableOpenAPI3
+	// NullableBoth emits both keywords for consumers of either generation.
+	NullableBoth = NullableSwagger2 | NullableOpenAPI3
+	// NullableOneOf describes optional struct pointer fields as
+	// oneOf: [{"type": "null"}, {"$ref": ...}], the draft-04 way of
+	// making a reference nullable, for strict validation profiles.
+	NullableOneOf NullableStyle = 1 << 2
+	// NullableTypeArray adds "null" to the type of pointer fields, as in
+	// "type": ["object", "null"], and describes those referring to a
+	// definition like NullableOneOf. OpenAPI documents and CRDs, which
+	// know no type arrays, mark them with the nullable keyword instead.
+	NullableTypeArray NullableStyle = 1 << 3
+)
+
+// PrimitiveStyle selects how a named type over a primitive is emitted.
+type PrimitiveStyle int
+
+const (
+	// PrimitiveInline emits the underlying primitive in place.
+	PrimitiveInline PrimitiveStyle = iota
+	// PrimitiveDefinition emits a definition of its own for the type, so
+	// that a dedicated wrapper class can be generated for it.
+	PrimitiveDefinition
+)
+
+// fieldTag returns the tag naming f: the first of Options.TagKeys it has,
+// json by default.
+func (g *schemaGenerator) fieldTag(f reflect.StructField) string {
+	keys := g.opts.TagKeys
+	if len(keys) == 0 {
+		keys = []string{"json"}
+	}
+	for _, key := range keys {
+		if tag, ok := f.Tag.Lookup(key); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// jsonOptions returns the options following the name in the tag of f.
+func (g *schemaGenerator) jsonOptions(f reflect.StructField) []string {
+	return strings.Split(g.fieldTag(f), ",")[1:]
+}
+
+func (g *schemaGenerator) hasJSONOption(f reflect.StructField, option string) bool {
+	for _, p := range g.jsonOptions(f) {
+		if p == option {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *schemaGenerator) nullableDescriptor() *NullableDescriptor {
+	if g.opts.Nullable&NullableBoth == 0 {
+		return nil
+	}
+	return &NullableDescriptor{
+		XNullable: g.opts.Nullable&NullableSwagger2 != 0,
+		Nullable:  g.opts.Nullable&NullableOpenAPI3 != 0,
+	}
+}
+
+// isStruct tells whether t is a struct or a pointer to a struct.
+func isStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	return f.Name
+	return t.Kind() == reflect.Struct
+}
+
+// isRecursive tells whether t is a named slice, array or map type, which may
+// refer to itself without a definition cutting the cycle.
+func isRecursive(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map:
+		return len(t.Name()) > 0
+	}
+	return false
+}
+
+// isIgnored tells whether f is tagged json:"-", which encoding/json never
+// marshals. A field tagged json:"-," is named "-" instead.
+func (g *schemaGenerator) isIgnored(f reflect.StructField) bool {
+	return g.fieldTag(f) == "-"
+}
+
+// getFieldName returns the JSON name of f, which defaults to the name of
+// the Go field when the json tag only holds options, e.g. ",omitempty",
+// following Options.PropertyNaming.
+func (g *schemaGenerator) getFieldName(f reflect.StructField) string {
+	name := strings.Split(g.fieldTag(f), ",")[0]
+	if len(name) > 0 {
+		if g.opts.RenameTaggedProperties {
+			return g.opts.PropertyNaming.convert(name)
+		}
+		return name
+	}
+	return g.opts.PropertyNaming.convert(f.Name)
 }
 
 func (g *schemaGenerator) qualifiedName(t reflect.Type) string {
-	pkgDesc, ok := g.packages[t.PkgPath()]
+	if synthetic, ok := g.synthetic[t]; ok {
+		return synthetic.name
+	}
+	return g.definitionName(t.PkgPath(), t.Name())
+}
+
+// definitionName returns the name of the definition of the type declared
+// as name by the package with the given import path. A name already given
+// to another type is a problem, unless Options.DisambiguateNames is set or
+// the names are ShortNames.
+func (g *schemaGenerator) definitionName(pkgPath, name string) string {
+	if len(pkgPath) == 0 || len(name) == 0 {
+		return g.packageDefinitionName(pkgPath, name)
+	}
+	id := g.canonicalPkgPath(pkgPath) + "." + name
+	if defName, ok := g.names[id]; ok {
+		return defName
+	}
+	defName := g.packageDefinitionName(pkgPath, name)
+	if other, ok := g.nameOwners[defName]; ok {
+		if _, described := g.packageByPath(pkgPath); !described && g.opts.NameFunc == nil && g.opts.Naming == ShortNames {
+			base := defName
+			for i := 2; len(g.nameOwners[defName]) > 0; i++ {
+				defName = fmt.Sprintf("%s%d", base, i)
+			}
+		} else if !g.opts.DisambiguateNames {
+			g.problem(fmt.Sprintf("%s and %s are both defined as %s.", other, id, defName))
+		} else {
+			defName = pathDefinitionName(pkgPath, name)
+			for i := 2; len(g.nameOwners[defName]) > 0; i++ {
+				defName = fmt.Sprintf("%s%d", pathDefinitionName(pkgPath, name), i)
+			}
+		}
+	}
+	g.names[id] = defName
+	g.nameOwners[defName] = id
+	return defName
+}
+
+// packageDefinitionName names a definition after the descriptor of its
+// package, or after its package path for undescribed packages.
+func (g *schemaGenerator) packageDefinitionName(pkgPath, name string) string {
+	pkgDesc, ok := g.packageByPath(pkgPath)
 	if !ok {
-		prefix := strings.Replace(t.PkgPath(), "/", "_", -1)
-		prefix = strings.Replace(prefix, ".", "_", -1)
-		prefix = strings.Replace(prefix, "-", "_", -1)
-		return prefix + "_" + t.Name()
+		return g.undescribedDefinitionName(pkgPath, name)
+	} else if prefix, ok := pkgDesc.TypePrefixes[name]; ok {
+		return prefix + name
 	} else {
-		return pkgDesc.Prefix + t.Name()
+		return pkgDesc.definitionPrefix() + name
+	}
+}
+
+// definitionPrefix returns the prefix of the definition names of the
+// package, Prefix or, if unset, one derived from Group and Version.
+func (p PackageDescriptor) definitionPrefix() string {
+	if len(p.Prefix) > 0 || len(p.Version) == 0 {
+		return p.Prefix
 	}
+	if len(p.Group) == 0 {
+		return p.Version + "_"
+	}
+	return strings.NewReplacer(".", "_", "-", "_").Replace(p.Group) + "_" + p.Version + "_"
+}
+
+func pathDefinitionName(pkgPath, name string) string {
+	prefix := strings.Replace(pkgPath, "/", "_", -1)
+	prefix = strings.Replace(prefix, ".", "_", -1)
+	prefix = strings.Replace(prefix, "-", "_", -1)
+	return prefix + "_" + name
 }
 
 func (g *schemaGenerator) generateReference(t reflect.Type) string {
-	return "#/definitions/" + g.qualifiedName(t)
+	return g.schemaURL(t) + definitionsPrefix + g.qualifiedName(t)
 }
 
+// schemaURL returns the URL of the external schema defining t, if the
+// descriptor of its package has one.
+func (g *schemaGenerator) schemaURL(t reflect.Type) string {
+	if pkgDesc, ok := g.packageByPath(t.PkgPath()); ok {
+		return pkgDesc.SchemaURL
+	}
+	return ""
+}
+
+// javaType returns the Java type jsonschema2pojo will generate for t. It
+// follows the same resolution order as getPropertyDescriptor, stripping
+// pointers at every level so element and value types of slices and maps get
+// the same treatment as top level types.
 func (g *schemaGenerator) javaType(t reflect.Type) string {
-	if t.Kind() == reflect.Ptr {
+	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
-	pkgDesc, ok := g.packages[t.PkgPath()]
-	if ok {
-		return pkgDesc.JavaPackage + "." + t.Name()
-	} else {
-		switch t.Kind() {
-		case reflect.Bool:
-			return "bool"
-		case reflect.Int, reflect.Int8, reflect.Int16,
-			reflect.Int32, reflect.Int64, reflect.Uint,
-			reflect.Uint8, reflect.Uint16, reflect.Uint32,
-			reflect.Uint64:
-			return "int"
-		case reflect.Float32, reflect.Float64, reflect.Complex64,
-			reflect.Complex128:
-			return "double"
-		case reflect.String:
-			return "String"
-		case reflect.Array, reflect.Slice:
-			return "java.util.ArrayList<" + g.javaType(t.Elem()) + ">"
-		case reflect.Map:
-			return "java.util.Map<String," + g.javaType(t.Elem()) + ">"
-		default:
-			if len(t.Name()) == 0 && t.NumField() == 0 {
-				return "Object"
-			}
+	t = g.intern(t)
+	if javaType, ok := g.javaTypes[t]; ok {
+		return javaType
+	}
+	// The types reached while a recursive type is expanded may be cut
+	// short to Object, they are only cached at the top level.
+	if len(g.javaExpanding) > 0 {
+		return g.resolveJavaType(t)
+	}
+	javaType := g.resolveJavaType(t)
+	g.javaTypes[t] = javaType
+	return javaType
+}
+
+// resolveJavaType computes javaType for the interned type t.
+func (g *schemaGenerator) resolveJavaType(t reflect.Type) string {
+	if fn, ok := g.handlers[t]; ok {
+		if desc := fn(t); desc.JavaTypeDescriptor != nil {
+			return desc.JavaType
+		}
+		return "Object"
+	}
+	if tt, ok := g.typeMap[t]; ok {
+		t = tt
+	}
+	if javaType, ok := g.opts.JavaTypeByType[t]; ok {
+		return javaType
+	}
+	if synthetic, ok := g.synthetic[t]; ok {
+		return synthetic.javaType
+	}
+	if desc, ok := g.embeddedObjectDescriptor(t); ok {
+		return desc.JavaType
+	}
+	if desc, ok := g.unionTypeDescriptor(t); ok {
+		if desc.JavaTypeDescriptor != nil {
+			return desc.JavaType
+		}
+		return "Object"
+	}
+	if desc, ok := g.formatDescriptor(t); ok {
+		if desc.JavaTypeDescriptor != nil {
+			return desc.JavaType
+		}
+		return "String"
+	}
+	if isRecursive(t) {
+		if g.javaExpanding[t] {
+			return "Object"
+		}
+		g.javaExpanding[t] = true
+		defer delete(g.javaExpanding, t)
+	}
+	pkgDesc, known := g.packageDescriptor(t)
+	if m, ok := g.kinds[t.Kind()]; ok {
+		_, enum := g.enum(t)
+		defined := g.primitiveStyle(t) == PrimitiveDefinition
+		switch {
+		case known && (defined || enum):
+			return pkgDesc.JavaPackage + "." + t.Name()
+		case defined:
 			return t.Name()
 		}
+		return g.kindJavaType(t, m)
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice:
+		return "java.util.ArrayList<" + typeArgument(g.javaType(t.Elem())) + ">"
+	case reflect.Map:
+		return "java.util.Map<" + g.mapKeyJavaType(t) + "," + typeArgument(g.javaType(t.Elem())) + ">"
+	case reflect.Struct:
+		if known {
+			return pkgDesc.JavaPackage + "." + t.Name()
+		}
+		if len(t.Name()) == 0 {
+			// Anonymous structs are named by registerAnonymousStruct.
+			return "Object"
+		}
+		return t.Name()
+	case reflect.Interface:
+		if _, ok := g.implementations[t]; !ok {
+			return "Object"
+		}
+		if known {
+			return pkgDesc.JavaPackage + "." + t.Name()
+		}
+		return t.Name()
+	default:
+		return "Object"
 	}
 }
 
-func (g *schemaGenerator) generate(t reflect.Type) (*JSONSchema, error) {
+func (g *schemaGenerator) generate(t reflect.Type) (schema *JSONSchema, err error) {
 	if t.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("Only struct types can be converted.")
 	}
+	if err := ValidatePackages(g.opts.Packages); err != nil {
+		return nil, err
+	}
+	if g.opts.AnyItems == AnyItemsTrue && g.opts.Draft == Draft04 {
+		return nil, fmt.Errorf("Boolean schema items need draft-07 or later.")
+	}
+	if g.opts.InlineReferences && g.opts.Components != NoComponents {
+		return nil, fmt.Errorf("Inlined references leave no definitions to mirror into components.")
+	}
+	g.startRoot(t)
+	warned := len(g.warnings)
+	defer func() {
+		if err != nil {
+			g.forgetRoot()
+		}
+	}()
+	// Report unexpected type shapes as errors locating the offending
+	// field rather than crashing the caller.
+	defer func() {
+		if r := recover(); r != nil {
+			schema, err = nil, fmt.Errorf("Unable to describe %s: %v.", g.location(t), r)
+		}
+	}()
 
+	pkgDesc, _ := g.packageDescriptor(t)
+	id, err := schemaID(g.opts, t.Name(), pkgDesc)
+	if err != nil {
+		return nil, err
+	}
 	s := JSONSchema{
-		ID:     "http://fabric8.io/fabric8/v2/" + t.Name() + "#",
-		Schema: "http://json-schema.org/schema#",
+		ID:      id,
+		Schema:  schemaURI(g.opts),
+		Draft:   g.opts.Draft,
+		License: g.opts.License,
 		JSONDescriptor: JSONDescriptor{
 			Type: "object",
 		},
 	}
+	if g.opts.BuildMode == Stamped {
+		s.Generated = newGenerationStamp()
+	}
+	if len(t.Name()) > 0 {
+		if gvks := g.groupVersionKinds(g.qualifiedName(t), pkgDesc, t.Name()); gvks != nil {
+			s.GroupVersionKinds = gvks.GroupVersionKinds
+		}
+		s.Title = g.title(t.Name())
+	}
+	if g.opts.Progress != nil {
+		g.total = len(g.reachableTypes(t))
+	}
 	s.JSONObjectDescriptor = g.generateObjectDescriptor(t)
-	if len(g.types) > 0 {
+	if err := g.generationError(); err != nil {
+		return nil, err
+	}
+	if defined := g.rootDefinitions(); len(defined) > 0 {
 		s.Definitions = make(map[string]JSONPropertyDescriptor)
-		for k, v := range g.types {
+		for k := range defined {
+			v := g.types[k]
 			name := g.qualifiedName(k)
-			value := JSONPropertyDescriptor{
-				JSONDescriptor: &JSONDescriptor{
-					Type: "object",
-				},
-				JSONObjectDescriptor: v,
-				JavaTypeDescriptor: &JavaTypeDescriptor{
-					JavaType: g.javaType(k),
-				},
+			value := *v
+			if annotations := g.javaAnnotations(k, name); len(annotations) > 0 {
+				value.JavaAnnotationsDescriptor = &JavaAnnotationsDescriptor{
+					CustomAnnotations: annotations,
+				}
+			}
+			if g.opts.Abstract[name] {
+				value.AbstractDescriptor = &AbstractDescriptor{
+					Abstract: true,
+				}
+			}
+			pkgDesc, ok := g.packageDescriptor(k)
+			if ok {
+				applyJavaClassHints(&value, pkgDesc)
+			}
+			value.GroupVersionKindDescriptor = g.groupVersionKinds(name, pkgDesc, k.Name())
+			if value.JSONObjectDescriptor != nil && len(k.Name()) > 0 {
+				g.applyTitle(&value, k.Name())
+			}
+			if g.opts.Debug {
+				value.DebugDescriptor = &DebugDescriptor{
+					Debug: g.provenance[k],
+				}
+			}
+			if g.opts.GoPackages && len(k.PkgPath()) > 0 {
+				value.GoPackageDescriptor = &GoPackageDescriptor{
+					GoPackage: k.PkgPath(),
+				}
 			}
 			s.Definitions[name] = value
 		}
 	}
+	g.checkFieldRules(&s)
+	if err := g.applyDefinitionOverrides(&s); err != nil {
+		return nil, err
+	}
+	g.applyAnnotations(&s)
+	g.applyLabelFilter(&s)
+	g.applyRetention(&s)
+	if g.opts.StandardOnly {
+		mapSchema(&s, stripExtensions)
+	}
+	if len(g.opts.JavaTypeKeyword) > 0 && g.opts.JavaTypeKeyword != "javaType" {
+		mapSchema(&s, renameJavaTypes(g.opts.JavaTypeKeyword))
+	}
+	if g.opts.WrapRefs {
+		mapSchema(&s, wrapRef)
+	}
+	if g.opts.ContentAddressed {
+		g.renamed = contentAddress(&s)
+	}
+	if g.opts.NestDefinitions {
+		g.nested = nestDefinitions(&s)
+	}
+	g.applyProfile(&s, t)
+	if err := g.applyOverrides(&s); err != nil {
+		return nil, err
+	}
+	g.applyPropertiesPolicies(&s, t)
+	if err := g.generationError(); err != nil {
+		return nil, err
+	}
+	g.applyComponents(&s)
+	if err := g.checkFrozen(&s); err != nil {
+		return nil, err
+	}
+	if g.opts.InlineReferences {
+		inlineReferences(&s)
+	}
+	s.Warnings = warningMessages(g.warnings[warned:])
 	return &s, nil
 }
 
+// applyJavaClassHints adds the class hints of pkgDesc to def if it is an
+// object definition with a Java type.
+func applyJavaClassHints(def *JSONPropertyDescriptor, pkgDesc PackageDescriptor) {
+	if def.JSONObjectDescriptor == nil || def.JavaTypeDescriptor == nil {
+		return
+	}
+	if len(pkgDesc.JavaInterfaces) == 0 && !pkgDesc.GenerateBuilders && !pkgDesc.JavaSerializable {
+		return
+	}
+	java := *def.JavaTypeDescriptor
+	java.JavaInterfaces = append(append([]string{}, java.JavaInterfaces...), pkgDesc.JavaInterfaces...)
+	java.GenerateBuilders = java.GenerateBuilders || pkgDesc.GenerateBuilders
+	java.JavaSerializable = java.JavaSerializable || pkgDesc.JavaSerializable
+	def.JavaTypeDescriptor = &java
+}
+
+func (g *schemaGenerator) javaAnnotations(t reflect.Type, name string) []string {
+	var annotations []string
+	if pkgDesc, ok := g.packageDescriptor(t); ok {
+		annotations = append(annotations, pkgDesc.JavaAnnotations...)
+	}
+	return append(annotations, g.opts.JavaAnnotations[name]...)
+}
+
 func (g *schemaGenerator) getPropertyDescriptor(t reflect.Type) JSONPropertyDescriptor {
-	if t.Kind() == reflect.Ptr {
+	for t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
+	t = g.intern(t)
+	if fn, ok := g.handlers[t]; ok {
+		return fn(t)
+	}
 	tt, ok := g.typeMap[t]
 	if ok {
 		t = tt
 	}
-	switch t.Kind() {
-	case reflect.Bool:
-		return JSONPropertyDescriptor{
-			JSONDescriptor: &JSONDescriptor{
-				Type: "boolean",
-			},
+	if desc, ok := g.embeddedObjectDescriptor(t); ok {
+		return desc
+	}
+	if desc, ok := g.unionTypeDescriptor(t); ok {
+		return desc
+	}
+	if desc, ok := g.formatDescriptor(t); ok {
+		return desc
+	}
+	if m, ok := g.kinds[t.Kind()]; ok {
+		desc := g.kindDescriptor(t, m)
+		if e, ok := g.enum(t); ok {
+			desc = JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type: m.JSONType,
+					Enum: enumValues(e.values),
+				},
+				JavaTypeDescriptor: &JavaTypeDescriptor{
+					JavaType: g.javaType(t),
+				},
+			}
+			applyEnumHints(&desc, e)
 		}
-	case reflect.Int, reflect.Int8, reflect.Int16,
-		reflect.Int32, reflect.Int64, reflect.Uint,
-		reflect.Uint8, reflect.Uint16, reflect.Uint32,
-		reflect.Uint64:
-		return JSONPropertyDescriptor{
-			JSONDescriptor: &JSONDescriptor{
-				Type: "integer",
-			},
+		if g.primitiveStyle(t) == PrimitiveDefinition {
+			return g.defineType(t, func() JSONPropertyDescriptor {
+				desc.JavaTypeDescriptor = &JavaTypeDescriptor{
+					JavaType: g.javaType(t),
+				}
+				return desc
+			})
 		}
-	case reflect.Float32, reflect.Float64, reflect.Complex64,
-		reflect.Complex128:
-		return JSONPropertyDescriptor{
-			JSONDescriptor: &JSONDescriptor{
-				Type: "number",
-			},
+		return desc
+	}
+	if isRecursive(t) {
+		// Types such as type Tree map[string]Tree can only be described
+		// up to their first repetition since they have no definition.
+		if g.expanding[t] {
+			g.warnf("%s refers to itself other than through a struct, described as a free-form value.", t.Name())
+			return JSONPropertyDescriptor{}
 		}
-	case reflect.String:
+		g.expanding[t] = true
+		defer delete(g.expanding, t)
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		// Fixed-size arrays are emitted like slices of exactly their
+		// length.
+		length := t.Len()
 		return JSONPropertyDescriptor{
 			JSONDescriptor: &JSONDescriptor{
-				Type: "string",
+				Type: "array",
+			},
+			JSONArrayDescriptor: &JSONArrayDescriptor{
+				Items:    g.itemsDescriptor(t.Elem()),
+				MinItems: &length,
+				MaxItems: &length,
 			},
 		}
-	case reflect.Array:
+	case reflect.Interface:
+		if impls, ok := g.implementations[t]; ok {
+			return g.unionDescriptor(t, impls)
+		}
 	case reflect.Slice:
 		return JSONPropertyDescriptor{
 			JSONDescriptor: &JSONDescriptor{
 				Type: "array",
 			},
 			JSONArrayDescriptor: &JSONArrayDescriptor{
-				Items: g.getPropertyDescriptor(t.Elem()),
+				Items: g.itemsDescriptor(t.Elem()),
 			},
 		}
 	case reflect.Map:
-		return JSONPropertyDescriptor{
+		if g.usesMapEntries(t) {
+			return g.mapEntriesDescriptor(t)
+		}
+		desc := JSONPropertyDescriptor{
 			JSONDescriptor: &JSONDescriptor{
 				Type: "object",
 			},
 			JSONMapDescriptor: &JSONMapDescriptor{
-				MapValueType: g.getPropertyDescriptor(t.Elem()),
+				MapValueType: g.elementDescriptor("{}", t.Elem()),
 			},
+			// Named map types holding themselves are cut short to Object
+			// by javaType where their values are free-form.
 			JavaTypeDescriptor: &JavaTypeDescriptor{
-				JavaType: "java.util.Map<String," + g.javaType(t.Elem()) + ">",
+				JavaType: g.javaType(t),
 			},
 		}
+		desc.PropertyNames = g.mapKeyNames(t)
+		if g.opts.PatternProperties {
+			patternKeys(&desc)
+		}
+		return desc
 	case reflect.Struct:
-		definedType, ok := g.types[t]
-		if !ok {
-			g.types[t] = &JSONObjectDescriptor{}
-			definedType = g.generateObjectDescriptor(t)
-			g.types[t] = definedType
+		if g.pruned[t] {
+			// The type is reached through something other than a field,
+			// such as a union or an interface implementation.
+			g.warnf("%s is pruned but also used other than through a field, described as a free-form object.", t)
+			return prunedDescriptor()
 		}
-		return JSONPropertyDescriptor{
-			JSONReferenceDescriptor: &JSONReferenceDescriptor{
-				Reference: g.generateReference(t),
-			},
-			JavaTypeDescriptor: &JavaTypeDescriptor{
-				JavaType: g.javaType(t),
-			},
+		if len(t.Name()) == 0 {
+			// Anonymous structs have no name to be defined under and are
+			// described in place.
+			return JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type: "object",
+				},
+				JSONObjectDescriptor: g.generateObjectDescriptor(t),
+				JavaTypeDescriptor: &JavaTypeDescriptor{
+					JavaType: g.javaType(t),
+				},
+			}
 		}
+		return g.defineType(t, func() JSONPropertyDescriptor {
+			obj := g.generateObjectDescriptor(t)
+			var def JSONPropertyDescriptor
+			if c, ok := g.compositions[t]; ok {
+				def = composedDescriptor(c, obj, g.javaType(t))
+			} else {
+				def = JSONPropertyDescriptor{
+					JSONDescriptor: &JSONDescriptor{
+						Type: "object",
+					},
+					JSONObjectDescriptor: obj,
+					JavaTypeDescriptor: &JavaTypeDescriptor{
+						JavaType: g.javaType(t),
+					},
+				}
+			}
+			g.applyTypeDoc(&def, t)
+			return def
+		})
+	case reflect.Complex64, reflect.Complex128:
+		g.problem(fmt.Sprintf("%s of type %s is a complex number, which encoding/json cannot marshal.", g.location(t), t))
+	default:
+		// Channels, functions, unsafe pointers and the kinds without a
+		// mapping cannot be marshalled by encoding/json.
+		g.warnf("%s of type %s has no JSON representation, described as a free-form value.", g.location(t), t)
 	}
 	return JSONPropertyDescriptor{}
 }
 
-func (g *schemaGenerator) getStructProperties(t reflect.Type) map[string]JSONPropertyDescriptor {
+// location returns the chain of fields leading to the value being described,
+// or t outside of any field.
+func (g *schemaGenerator) location(t reflect.Type) string {
+	if len(g.path) == 0 {
+		return t.String()
+	}
+	return g.fieldPath()
+}
+
+// elementDescriptor describes t, the type of the items or values of the
+// current field, marked with suffix in the path.
+func (g *schemaGenerator) elementDescriptor(suffix string, t reflect.Type) JSONPropertyDescriptor {
+	return g.element(suffix, func() JSONPropertyDescriptor {
+		return g.getPropertyDescriptor(t)
+	})
+}
+
+// defineType adds the definition built by fn for t unless it is already
+// defined and returns a reference to it. A placeholder is registered while
+// fn runs so that recursive types refer to the definition being built.
+// Types defined by an external schema are only referenced.
+func (g *schemaGenerator) defineType(t reflect.Type, fn func() JSONPropertyDescriptor) JSONPropertyDescriptor {
+	g.dependsOn(t)
+	if _, ok := g.types[t]; !ok && len(g.schemaURL(t)) == 0 {
+		g.types[t] = &JSONPropertyDescriptor{JSONObjectDescriptor: &JSONObjectDescriptor{}}
+		g.added = append(g.added, t)
+		g.provenance[t] = append([]string{}, g.path...)
+		if g.opts.Progress != nil {
+			g.opts.Progress(len(g.types), g.total, g.qualifiedName(t))
+		}
+		g.defining[t] = true
+		g.building = append(g.building, t)
+		definition := fn()
+		g.building = g.building[:len(g.building)-1]
+		delete(g.defining, t)
+		g.types[t] = &definition
+	}
+	return JSONPropertyDescriptor{
+		JSONReferenceDescriptor: &JSONReferenceDescriptor{
+			Reference: g.generateReference(t),
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: g.javaType(t),
+		},
+	}
+}
+
+func (g *schemaGenerator) primitiveStyle(t reflect.Type) PrimitiveStyle {
+	if len(t.Name()) == 0 || len(t.PkgPath()) == 0 {
+		return PrimitiveInline
+	}
+	if style, ok := g.opts.PrimitiveStyles[t]; ok {
+		return style
+	}
+	return g.opts.PrimitiveStyle
+}
+
+// refine combines the schema of a property with usage site constraints.
+// The Java type stays on the combined schema.
+func refine(prop, constraints JSONPropertyDescriptor) JSONPropertyDescriptor {
+	result := JSONPropertyDescriptor{
+		JavaTypeDescriptor: prop.JavaTypeDescriptor,
+	}
+	prop.JavaTypeDescriptor = nil
+	result.JSONCombinatorDescriptor = &JSONCombinatorDescriptor{
+		AllOf: []JSONPropertyDescriptor{prop, constraints},
+	}
+	return result
+}
+
+// enumValues returns values as the elements of an enum keyword.
+func enumValues(values []string) []interface{} {
+	enum := make([]interface{}, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// nonEmpty returns a copy of prop requiring at least one character if prop
+// describes a string without a minimum length.
+func nonEmpty(prop JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if prop.JSONDescriptor == nil || prop.Type != "string" || prop.MinLength != nil {
+		return prop
+	}
+	desc := *prop.JSONDescriptor
+	minLength := 1
+	desc.MinLength = &minLength
+	prop.JSONDescriptor = &desc
+	return prop
+}
+
+// nullableRef describes a reference as either null or the referenced
+// schema, keeping the Java type hint outside of the oneOf.
+func nullableRef(prop JSONPropertyDescriptor) JSONPropertyDescriptor {
+	return JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf: []JSONPropertyDescriptor{
+				{JSONDescriptor: &JSONDescriptor{Type: "null"}},
+				{JSONReferenceDescriptor: prop.JSONReferenceDescriptor},
+			},
+		},
+		JavaTypeDescriptor: prop.JavaTypeDescriptor,
+	}
+}
+
+// nullablePointer describes the property of a pointer field as allowing
+// null, as selected by the NullableOneOf and NullableTypeArray styles.
+func (g *schemaGenerator) nullablePointer(prop JSONPropertyDescriptor) JSONPropertyDescriptor {
+	switch {
+	case prop.JSONReferenceDescriptor != nil:
+		if g.opts.Nullable&(NullableOneOf|NullableTypeArray) != 0 {
+			return nullableRef(prop)
+		}
+	case prop.JSONDescriptor != nil && len(prop.Type) > 0:
+		if g.opts.Nullable&NullableTypeArray != 0 {
+			desc := *prop.JSONDescriptor
+			desc.AllowNull = true
+			prop.JSONDescriptor = &desc
+		}
+	}
+	return prop
+}
+
+// getStructProperties returns the properties of the struct t and the
+// sorted names of the required ones.
+func (g *schemaGenerator) getStructProperties(t reflect.Type) (map[string]JSONPropertyDescriptor, []string) {
 	props := map[string]JSONPropertyDescriptor{}
+	required := map[string]bool{}
+	// direct and embedded map the names of the properties declared by t
+	// and flattened from its embedded structs to the Go fields declaring
+	// them. Like encoding/json, direct properties shadow embedded ones.
+	direct := map[string]string{}
+	embedded := map[string]string{}
+	// bases holds the references to the embedded structs composed in
+	// ComposeEmbedded mode, composed the names of their fields.
+	var bases []JSONPropertyDescriptor
+	composed := map[string]bool{}
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
 		if len(field.PkgPath) > 0 { // Skip private fields
 			continue
 		}
-		name := getFieldName(field)
-		prop := g.getPropertyDescriptor(field.Type)
-		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+		name := g.getFieldName(field)
+		tag := getSchemagenTag(field)
+		if _, ok := tag["prune"]; ok {
+			continue
+		}
+		if g.isCatchAll(field) || g.isIgnored(field) {
+			continue
+		}
+		rule := g.fieldRule(t, name)
+		if rule.Exclude {
+			continue
+		}
+		if len(rule.Name) > 0 {
+			name = rule.Name
+		}
+		g.registerAnonymousStruct(t, field)
+		var prop JSONPropertyDescriptor
+		var union []reflect.Type
+		if value, ok := tag["oneOf"]; ok && !rule.Opaque {
+			union = g.tagUnionTypes(t, field, value, true)
+		}
+		if rule.Opaque {
+			prop = prunedDescriptor()
+		} else if value, ok := tag["any"]; ok {
+			prop = anyDescriptor(value)
+		} else if len(union) > 0 {
+			_, nullable := tag["nullable"]
+			g.path = append(g.path, t.Name()+"."+name)
+			prop = g.tagUnionDescriptor(field, union, nullable)
+			g.path = g.path[:len(g.path)-1]
+		} else if field.Anonymous && field.Type.Kind() == reflect.Interface {
+			// The fields of the dynamic value cannot be flattened.
+			g.warnf("%s embeds interface %s, described as an object.", t.Name(), field.Type)
+			prop = JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type: "object",
+				},
+			}
+		} else {
+			g.path = append(g.path, t.Name()+"."+name)
+			prop = g.getPropertyDescriptor(fieldType(field, tag))
+			g.path = g.path[:len(g.path)-1]
+		}
+		if c, ok := g.opts.Coercions[g.qualifiedName(t)+"."+name]; ok {
+			if desc, ok := g.coercedDescriptor(c); ok {
+				prop = desc
+			} else {
+				g.warnf("Unknown coercion type %s of %s.%s.", c.Type, g.qualifiedName(t), name)
+			}
+		}
+		if hints, ok := g.javaHints(t, field, name); ok {
+			prop = applyJavaHints(prop, hints)
+		}
+		if javaName := g.javaName(name); len(javaName) > 0 {
+			prop.JavaNameDescriptor = &JavaNameDescriptor{
+				JavaName: javaName,
+			}
+		}
+		if g.isInline(field) && isStruct(field.Type) {
 			var newProps map[string]JSONPropertyDescriptor
+			var newRequired []string
 			if prop.JSONReferenceDescriptor != nil {
 				pType := field.Type
 				if pType.Kind() == reflect.Ptr {
 					pType = pType.Elem()
 				}
-				newProps = g.types[pType].Properties
-			} else {
-				newProps = prop.Properties
+				if g.defining[pType] {
+					// The embedded type refers back to t, which it is
+					// reached from: its definition is not built yet, so
+					// its fields are walked again.
+					newProps, newRequired = g.getStructProperties(pType)
+				} else if c, ok := g.compositions[pType]; ok {
+					newProps, newRequired = c.properties, c.required
+				} else {
+					newProps, newRequired = g.types[pType].Properties, g.types[pType].Required
+				}
+				if g.opts.ComposeEmbedded {
+					bases = append(bases, prop)
+					composed[field.Name] = true
+				}
+			} else if prop.JSONObjectDescriptor != nil {
+				newProps, newRequired = prop.Properties, prop.Required
 			}
 			for k, v := range newProps {
+				if _, ok := direct[k]; ok {
+					continue
+				}
+				if other, ok := embedded[k]; ok {
+					g.warnf("%s embeds %s and %s which both have a field named %s.", t.Name(), other, field.Name, k)
+				}
+				embedded[k] = field.Name
 				props[k] = v
+				required[k] = false
+			}
+			// The fields of a nil embedded pointer are all left out.
+			for _, k := range newRequired {
+				if embedded[k] == field.Name && field.Type.Kind() != reflect.Ptr {
+					required[k] = true
+				}
 			}
 		} else {
+			g.applyItems(&prop, t, field, name, tag)
+			if !applyMapTag(&prop, tag) {
+				g.warnf("%s.%s is tagged closed but does not limit its number of entries with maxProperties.", t.Name(), field.Name)
+			}
+			if keys, ok := tag["keys"]; ok {
+				if _, known := keyPresets[keys]; !known {
+					g.warnf("%s.%s is tagged with unknown keys %s.", t.Name(), field.Name, keys)
+				}
+			}
+			if valueType, ok := tag["valueType"]; ok {
+				if _, known := valueTypes[valueType]; !known {
+					g.warnf("%s.%s is tagged with unknown valueType %s.", t.Name(), field.Name, valueType)
+				}
+			}
+			if _, ok := tag["patternProperties"]; ok || g.opts.PatternProperties {
+				if !patternKeys(&prop) && ok {
+					g.warnf("%s.%s is tagged patternProperties but is not a map with a key pattern.", t.Name(), field.Name)
+				}
+			}
+			g.checkTagValues(t, field, tag)
+			applyValidationTag(&prop, field, tag)
+			g.guessFormat(&prop, t, name, tag)
+			if !applyStringFormTag(&prop, tag) {
+				g.warnf("%s.%s is tagged stringForm but is not a map.", t.Name(), field.Name)
+			}
+			applyExampleTag(&prop, field)
+			applyDocTags(&prop, field)
+			if g.hasJSONOption(field, "string") && !applyStringOption(&prop) {
+				g.warnf("%s.%s is tagged string but is not described as a number, a boolean or a string.", t.Name(), field.Name)
+			}
+			g.applyTitle(&prop, field.Name)
+			g.applyFieldDoc(&prop, t, field)
+			g.applyTagOptions(&prop, field)
+			if g.opts.ProtoFields {
+				applyProtobufTag(&prop, field)
+			}
+			if g.opts.Refine != nil {
+				if constraints := g.opts.Refine(t, field); constraints != nil {
+					prop = refine(prop, *constraints)
+				}
+			}
+			if g.isOptional(field) {
+				if field.Type.Kind() == reflect.Ptr {
+					prop = g.nullablePointer(prop)
+				}
+				prop.NullableDescriptor = g.nullableDescriptor()
+			} else if g.opts.NonEmptyRequiredStrings {
+				prop = nonEmpty(prop)
+			}
+			g.decorate(t, field, &prop)
+			if other, ok := direct[name]; ok {
+				g.warnf("%s has fields %s and %s both named %s.", t.Name(), other, field.Name, name)
+			}
+			direct[name] = field.Name
 			props[name] = prop
+			required[name] = g.isRequired(t, field, name)
 		}
 	}
-	return props
+	var requiredNames []string
+	for k, ok := range required {
+		if ok {
+			requiredNames = append(requiredNames, k)
+		}
+	}
+	sort.Strings(requiredNames)
+	if len(bases) > 0 {
+		c := &composition{
+			bases:      bases,
+			inherited:  map[string]bool{},
+			properties: props,
+			required:   requiredNames,
+		}
+		for k, fieldName := range embedded {
+			if _, ok := direct[k]; !ok && composed[fieldName] {
+				c.inherited[k] = true
+			}
+		}
+		g.compositions[t] = c
+	}
+	return props, requiredNames
 }
+
+// isRequired tells whether the field of t described by the property name
+// is required: as set by Options.RequiredOverrides or a required struct
+// tag, otherwise, with Options.Required, if it is not optional.
+func (g *schemaGenerator) isRequired(t reflect.Type, f reflect.StructField, name string) bool {
+	if required, ok := g.opts.RequiredOverrides[g.qualifiedName(t)+"."+name]; ok {
+		return required
+	}
+	switch f.Tag.Get("required") {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return g.opts.Required && !g.isOptional(f)
+}
+
+// warnf reports a problem through Options.Warn and records it in the
+// Warnings of the schema, or fails the generation if Options.Strict is set.
+func (g *schemaGenerator) warnf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	if g.opts.Strict {
+		g.problem(message)
+		return
+	}
+	g.warnings = append(g.warnings, Warning{Field: g.fieldPath(), Message: message})
+	if g.opts.Warn != nil {
+		g.opts.Warn(message)
+	}
+}
+
 func (g *schemaGenerator) generateObjectDescriptor(t reflect.Type) *JSONObjectDescriptor {
 	desc := JSONObjectDescriptor{AdditionalProperties: true}
-	desc.Properties = g.getStructProperties(t)
+	desc.Properties, desc.Required = g.getStructProperties(t)
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); len(field.PkgPath) == 0 && g.isCatchAll(field) {
+			g.path = append(g.path, t.Name()+"."+field.Name)
+			desc.AdditionalProperties = g.getPropertyDescriptor(field.Type.Elem())
+			g.path = g.path[:len(g.path)-1]
+		}
+	}
 	return &desc
 }
+
+// isCatchAll tells whether f is a map holding the fields of its struct not
+// otherwise listed, see Options.CatchAllMaps.
+func (g *schemaGenerator) isCatchAll(f reflect.StructField) bool {
+	if !g.opts.CatchAllMaps || f.Type.Kind() != reflect.Map || f.Type.Key().Kind() != reflect.String {
+		return false
+	}
+	return g.fieldTag(f) == "-" || g.hasJSONOption(f, "inline")
+}
@@ -0,0 +1,91 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fuzzBasicTypes are the leaves of the types built by fuzzType.
+var fuzzBasicTypes = []reflect.Type{
+	reflect.TypeOf(false),
+	reflect.TypeOf(int(0)),
+	reflect.TypeOf(int32(0)),
+	reflect.TypeOf(uint64(0)),
+	reflect.TypeOf(float64(0)),
+	reflect.TypeOf(""),
+	reflect.TypeOf([]byte(nil)),
+	reflect.TypeOf((*interface{})(nil)).Elem(),
+}
+
+// fuzzType builds a struct type from data, read as the instructions of a
+// stack machine: each byte pushes a basic type, wraps the top of the stack
+// in a slice, pointer, map or array, or replaces the top items by a struct
+// holding them. The root struct holds what is left on the stack.
+func fuzzType(data []byte) reflect.Type {
+	stack := []reflect.Type{reflect.TypeOf("")}
+	fields := func(types []reflect.Type, b byte) []reflect.StructField {
+		result := make([]reflect.StructField, len(types))
+		for i, t := range types {
+			tag := fmt.Sprintf(`json:"f%d"`, i)
+			if b&0x80 != 0 {
+				tag = fmt.Sprintf(`json:"f%d,omitempty"`, i)
+			}
+			result[i] = reflect.StructField{Name: fmt.Sprintf("F%d", i), Type: t, Tag: reflect.StructTag(tag)}
+		}
+		return result
+	}
+	for _, b := range data {
+		top := stack[len(stack)-1]
+		switch op := b % 16; {
+		case op < 8:
+			stack = append(stack, fuzzBasicTypes[op])
+		case op == 8:
+			stack[len(stack)-1] = reflect.SliceOf(top)
+		case op == 9:
+			stack[len(stack)-1] = reflect.PtrTo(top)
+		case op == 10:
+			stack[len(stack)-1] = reflect.MapOf(reflect.TypeOf(""), top)
+		case op == 11:
+			stack[len(stack)-1] = reflect.ArrayOf(int(b>>4)%3+1, top)
+		case op == 12:
+			stack[len(stack)-1] = reflect.MapOf(reflect.TypeOf(int(0)), top)
+		default:
+			n := int(b>>4)%4 + 1
+			if n > len(stack) {
+				n = len(stack)
+			}
+			s := reflect.StructOf(fields(stack[len(stack)-n:], b))
+			stack = append(stack[:len(stack)-n], s)
+		}
+	}
+	return reflect.StructOf(fields(stack, 0))
+}
+
+// FuzzGenerate checks that no type shape makes the generator panic, which
+// it would report as an error describing the offending type, or produce a
+// schema that cannot be serialized.
+func FuzzGenerate(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x01, 0x08, 0x0a, 0x1d})
+	f.Add([]byte{0x05, 0x09, 0x09, 0x2e, 0x0b, 0x0c})
+	f.Add([]byte{0x07, 0x8d, 0x06, 0x08, 0x3f, 0x09, 0x0a})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) > 64 {
+			return
+		}
+		typ := fuzzType(data)
+		schema, err := GenerateSchemaWithOptions(typ, Options{})
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "Unable to describe") {
+				t.Fatalf("generating %s panicked: %v", typ, err)
+			}
+			return
+		}
+		if _, err := json.Marshal(schema); err != nil {
+			t.Fatalf("the schema of %s cannot be serialized: %v", typ, err)
+		}
+	})
+}
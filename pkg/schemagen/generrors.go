@@ -0,0 +1,99 @@
+package schemagen
+
+import (
+	"bytes"
+	"strings"
+)
+
+// GenerationError is returned in Strict mode, gathering every problem found
+// while walking the types rather than failing on the first one, so that
+// large models can be fixed in one pass.
+type GenerationError struct {
+	Problems []FieldProblem
+}
+
+// FieldProblem is a problem found while generating a schema. Field is the
+// chain of fields leading to it, such as "Build.spec > BuildSpec.done", and
+// is empty for problems of the schema as a whole. The items of arrays and
+// the values of maps are marked with [] and {}, as in
+// "Pod.spec > PodSpec.containers[] > Container.resources".
+type FieldProblem struct {
+	Field   string
+	Message string
+}
+
+// Warning is a problem reported through Options.Warn rather than failing
+// the generation, located like a FieldProblem.
+type Warning struct {
+	Field   string
+	Message string
+}
+
+// Warnings returns the warnings reported so far, in order. The generator
+// passed to Options.Setup can be kept to inspect them once the schema is
+// generated.
+func (gen *Generator) Warnings() []Warning {
+	return append([]Warning{}, gen.g.warnings...)
+}
+
+func (e *GenerationError) Error() string {
+	var b bytes.Buffer
+	for i, p := range e.Problems {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(p.Message)
+	}
+	return b.String()
+}
+
+// problem records a problem failing the generation, such as the ones found
+// in Strict mode, at the current field.
+func (g *schemaGenerator) problem(message string) {
+	g.errors = append(g.errors, FieldProblem{
+		Field:   g.fieldPath(),
+		Message: message,
+	})
+}
+
+// fieldPath returns the chain of fields leading to the value being
+// described, empty outside of any field.
+func (g *schemaGenerator) fieldPath() string {
+	return strings.Join(g.path, " > ")
+}
+
+// element describes with fn an element of the value of the current field,
+// marking the field with suffix in the path meanwhile, "[]" for the items
+// of an array and "{}" for the values of a map.
+func (g *schemaGenerator) element(suffix string, fn func() JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if len(g.path) == 0 {
+		return fn()
+	}
+	i := len(g.path) - 1
+	field := g.path[i]
+	g.path[i] = field + suffix
+	desc := fn()
+	g.path[i] = field
+	return desc
+}
+
+// warningMessages returns the messages of warnings, for
+// JSONSchema.Warnings.
+func warningMessages(warnings []Warning) []string {
+	if len(warnings) == 0 {
+		return nil
+	}
+	messages := make([]string, len(warnings))
+	for i, w := range warnings {
+		messages[i] = w.Message
+	}
+	return messages
+}
+
+// generationError returns the problems found so far, if any.
+func (g *schemaGenerator) generationError() error {
+	if len(g.errors) == 0 {
+		return nil
+	}
+	return &GenerationError{Problems: g.errors}
+}
@@ -0,0 +1,460 @@
+package schemagen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// GoEmitter renders the definitions of a schema as Go types with json
+// tags, for teams treating the schema as the source of truth: a struct for
+// each object definition, whose properties it does not require are
+// omitempty, a string type with constants for string enums and a named
+// type for the others. Optional and nullable structs and nullable scalars
+// are pointers, date-time strings time.Time, and values without a Go
+// equivalent, such as oneOf and anyOf unions, interface{}. allOf
+// compositions embed the definitions they refer to.
+type GoEmitter struct {
+	// Package is the package of the generated file, model if empty.
+	Package string
+	// Root names the struct of the root object, which is left out if it
+	// is empty.
+	Root string
+}
+
+// goInitialisms are the words Go spells upper case in identifiers.
+var goInitialisms = map[string]bool{
+	"api": true, "cpu": true, "css": true, "dns": true, "html": true,
+	"http": true, "https": true, "id": true, "ip": true, "json": true,
+	"tcp": true, "tls": true, "ttl": true, "udp": true, "uid": true,
+	"uri": true, "url": true, "uuid": true, "xml": true, "yaml": true,
+}
+
+// goIdentifier returns name as an exported Go identifier: its words, see
+// nameWords, capitalized and joined, e.g. kubernetes_api_PodIP becomes
+// KubernetesAPIPodIP.
+func goIdentifier(name string) string {
+	cleaned := []rune(name)
+	for i, r := range cleaned {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			cleaned[i] = '_'
+		}
+	}
+	var b bytes.Buffer
+	for _, w := range nameWords(string(cleaned)) {
+		if goInitialisms[strings.ToLower(w)] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	id := b.String()
+	if len(id) == 0 || !unicode.IsLetter([]rune(id)[0]) {
+		id = "X" + id
+	}
+	return id
+}
+
+// goWriter holds the state of a GoEmitter run.
+type goWriter struct {
+	schema *JSONSchema
+	// types are the Go names of the definitions.
+	types   map[string]string
+	imports map[string]bool
+	// current is the definition being written.
+	current string
+}
+
+func (e GoEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	g := &goWriter{
+		schema:  schema,
+		types:   make(map[string]string, len(schema.Definitions)),
+		imports: make(map[string]bool),
+	}
+	owners := make(map[string]string)
+	if len(e.Root) > 0 && schema.JSONObjectDescriptor != nil {
+		owners[goIdentifier(e.Root)] = "the root"
+	}
+	names := sortedDefinitionNames(schema.Definitions)
+	for _, name := range names {
+		id := goIdentifier(name)
+		if owner, ok := owners[id]; ok {
+			return fmt.Errorf("The definition %s and %s are both named %s in Go.", name, owner, id)
+		}
+		owners[id] = "the definition " + name
+		g.types[name] = id
+	}
+
+	var body bytes.Buffer
+	if len(e.Root) > 0 && schema.JSONObjectDescriptor != nil {
+		root := JSONPropertyDescriptor{
+			JSONDescriptor:       &schema.JSONDescriptor,
+			JSONObjectDescriptor: schema.JSONObjectDescriptor,
+		}
+		body.WriteString("\n")
+		writeGoDoc(&body, schema.Description)
+		fmt.Fprintf(&body, "type %s %s\n", goIdentifier(e.Root), g.structType(root))
+	}
+	for _, name := range names {
+		g.current = name
+		def := schema.Definitions[name]
+		id := g.types[name]
+		body.WriteString("\n")
+		if def.JSONDescriptor != nil {
+			writeGoDoc(&body, def.Description)
+		}
+		switch {
+		case goStruct(def):
+			fmt.Fprintf(&body, "type %s %s\n", id, g.structType(def))
+		case def.JSONDescriptor != nil && def.Type == "string" && len(def.Enum) > 0 && def.JSONCombinatorDescriptor == nil:
+			fmt.Fprintf(&body, "type %s string\n", id)
+			g.writeEnumConstants(&body, id, def.Enum)
+		default:
+			fmt.Fprintf(&body, "type %s %s\n", id, g.goType(def, false))
+		}
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by schemagen. DO NOT EDIT.\n\n")
+	pkg := e.Package
+	if len(pkg) == 0 {
+		pkg = "model"
+	}
+	fmt.Fprintf(&src, "package %s\n", pkg)
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		src.WriteString("\nimport (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n")
+	}
+	src.Write(body.Bytes())
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("Unable to format the Go types of the schema: %v", err)
+	}
+	out := bufio.NewWriter(w)
+	out.Write(formatted)
+	return out.Flush()
+}
+
+// goStruct tells whether p is rendered as a struct: an object with
+// properties or an allOf composition.
+func goStruct(p JSONPropertyDescriptor) bool {
+	if p.JSONCombinatorDescriptor != nil {
+		return len(p.AllOf) > 0
+	}
+	return p.JSONObjectDescriptor != nil && len(p.Properties) > 0
+}
+
+// structType returns the struct describing the values of p, embedding the
+// definitions of its allOf and holding a field for each of its properties
+// and of the inline objects of its allOf.
+func (g *goWriter) structType(p JSONPropertyDescriptor) string {
+	var b bytes.Buffer
+	b.WriteString("struct {\n")
+	fields := make(map[string]bool)
+	var parts []JSONPropertyDescriptor
+	if p.JSONCombinatorDescriptor != nil {
+		parts = p.AllOf
+	}
+	for _, part := range parts {
+		if part.JSONReferenceDescriptor == nil {
+			continue
+		}
+		if name, ok := schemamodel.LocalDefinition(part.Reference); ok {
+			if id, ok := g.types[name]; ok {
+				fmt.Fprintf(&b, "%s\n", id)
+				fields[id] = true
+			}
+		}
+	}
+	for _, part := range parts {
+		if part.JSONObjectDescriptor != nil {
+			g.writeFields(&b, part.JSONObjectDescriptor, fields)
+		}
+	}
+	if p.JSONObjectDescriptor != nil {
+		g.writeFields(&b, p.JSONObjectDescriptor, fields)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// writeFields writes a field for each property of obj, in name order,
+// numbering the names already taken by fields.
+func (g *goWriter) writeFields(w io.Writer, obj *JSONObjectDescriptor, fields map[string]bool) {
+	required := make(map[string]bool, len(obj.Required))
+	for _, name := range obj.Required {
+		required[name] = true
+	}
+	for _, name := range sortedPropertyNames(obj.Properties) {
+		prop := obj.Properties[name]
+		field := goIdentifier(name)
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", goIdentifier(name), i)
+		}
+		fields[field] = true
+		if prop.JSONDescriptor != nil {
+			writeGoDoc(w, prop.Description)
+		}
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(w, "%s %s `json:%q`\n", field, g.goType(prop, !required[name]), tag)
+	}
+}
+
+// goType returns the Go type of the values described by p. Structs are
+// pointers if optional is set, so that omitempty leaves them out.
+func (g *goWriter) goType(p JSONPropertyDescriptor, optional bool) string {
+	if p.Boolean != nil {
+		return "interface{}"
+	}
+	nullable := p.JSONDescriptor != nil && p.AllowNull || p.NullableDescriptor != nil && (p.Nullable || p.XNullable)
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		id, defined := g.types[name]
+		if !ok || !defined {
+			return "interface{}"
+		}
+		if goStruct(g.schema.Definitions[name]) && (optional || nullable || name == g.current) {
+			return "*" + id
+		}
+		return id
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		if goStruct(p) {
+			return g.pointer(g.structType(p), optional || nullable)
+		}
+		for _, alts := range [][]JSONPropertyDescriptor{p.OneOf, p.AnyOf} {
+			if len(alts) == 0 {
+				continue
+			}
+			// A union with null only makes its other alternative nullable.
+			var others []JSONPropertyDescriptor
+			for _, alt := range alts {
+				if alt.JSONDescriptor == nil || alt.Type != "null" {
+					others = append(others, alt)
+				}
+			}
+			if len(others) == 1 && len(others) < len(alts) {
+				alt := others[0]
+				if alt.JSONReferenceDescriptor != nil {
+					return g.goType(alt, true)
+				}
+				if alt.JSONDescriptor != nil {
+					desc := *alt.JSONDescriptor
+					desc.AllowNull = true
+					alt.JSONDescriptor = &desc
+				}
+				return g.goType(alt, optional)
+			}
+			return "interface{}"
+		}
+	}
+	if p.JSONDescriptor == nil {
+		return "interface{}"
+	}
+	switch p.Type {
+	case "string":
+		if p.Format == "date-time" {
+			g.imports["time"] = true
+			return g.pointer("time.Time", optional || nullable)
+		}
+		return g.pointer("string", nullable)
+	case "integer":
+		t := "int64"
+		switch p.Format {
+		case "int32", "int16", "int8", "uint32", "uint16", "uint8", "uint64":
+			t = p.Format
+		}
+		return g.pointer(t, nullable)
+	case "number":
+		t := "float64"
+		if p.Format == "float" {
+			t = "float32"
+		}
+		return g.pointer(t, nullable)
+	case "boolean":
+		return g.pointer("bool", nullable)
+	case "array":
+		if p.JSONArrayDescriptor != nil {
+			return "[]" + g.goType(p.Items, false)
+		}
+		return "[]interface{}"
+	case "object":
+		if p.JSONMapDescriptor != nil {
+			return "map[string]" + g.goType(p.MapValueType, false)
+		}
+		if goStruct(p) {
+			return g.pointer(g.structType(p), optional || nullable)
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func (g *goWriter) pointer(t string, pointer bool) string {
+	if pointer {
+		return "*" + t
+	}
+	return t
+}
+
+// writeEnumConstants declares a constant of type id for each of values,
+// named after id and the value.
+func (g *goWriter) writeEnumConstants(w io.Writer, id string, values []interface{}) {
+	names := make(map[string]bool)
+	fmt.Fprintln(w, "\nconst (")
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		name := id + "Empty"
+		if len(s) > 0 {
+			name = id + goIdentifier(s)
+		}
+		base := name
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		names[name] = true
+		fmt.Fprintf(w, "%s %s = %q\n", name, id, s)
+	}
+	fmt.Fprintln(w, ")")
+}
+
+// writeGoDoc writes description as a line comment, if it is not empty.
+func writeGoDoc(w io.Writer, description string) {
+	if len(description) == 0 {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(description, "\n"), "\n") {
+		fmt.Fprintf(w, "// %s\n", strings.TrimRight(line, " \t"))
+	}
+}
+
+// ParseSchema reads a schema document, such as one written by the
+// generator, for GoEmitter and the other emitters to render. Documents of
+// later drafts are read as well: their type arrays holding null become
+// nullable types, their $defs definitions and their numeric exclusive
+// bounds minimum and maximum qualified as exclusive. Keywords the model
+// does not hold are ignored, and tuple items read as unrestricted.
+func ParseSchema(data []byte) (*JSONSchema, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("The schema document is not an object.")
+	}
+	if _, ok := root["id"]; !ok {
+		if id, ok := root["$id"]; ok {
+			root["id"] = id
+		}
+	}
+	b, err := json.Marshal(normalizeSchemaDocument(root))
+	if err != nil {
+		return nil, err
+	}
+	var s JSONSchema
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("Unable to read the schema: %v", err)
+	}
+	return &s, nil
+}
+
+// schemaKeywords are the keywords holding a subschema, and schemaListKeywords
+// and schemaMapKeywords the ones holding lists and maps of subschemas.
+var (
+	schemaKeywords     = []string{"items", "additionalProperties", "propertyNames", "not"}
+	schemaListKeywords = []string{"allOf", "anyOf", "oneOf"}
+	schemaMapKeywords  = []string{"properties", "patternProperties", "definitions"}
+)
+
+// normalizeSchemaDocument rewrites the schema object m, and its
+// subschemas, into the draft-04 dialect of the generator, see ParseSchema.
+func normalizeSchemaDocument(m map[string]interface{}) map[string]interface{} {
+	if types, ok := m["type"].([]interface{}); ok {
+		var others []interface{}
+		for _, t := range types {
+			if t == "null" {
+				m["x-nullable"] = true
+			} else {
+				others = append(others, t)
+			}
+		}
+		delete(m, "type")
+		if len(others) == 1 {
+			m["type"] = others[0]
+		}
+	}
+	if defs, ok := m["$defs"]; ok {
+		if _, ok := m["definitions"]; !ok {
+			m["definitions"] = defs
+		}
+		delete(m, "$defs")
+	}
+	if ref, ok := m["$ref"].(string); ok && strings.HasPrefix(ref, "#/$defs/") {
+		m["$ref"] = schemamodel.DefinitionsPrefix + strings.TrimPrefix(ref, "#/$defs/")
+	}
+	for _, bound := range []string{"Minimum", "Maximum"} {
+		exclusive := "exclusive" + bound
+		if n, ok := m[exclusive].(float64); ok {
+			m[strings.ToLower(bound)] = n
+			m[exclusive] = true
+		}
+	}
+	if _, ok := m["items"].([]interface{}); ok {
+		delete(m, "items")
+	}
+
+	for _, k := range schemaKeywords {
+		if v, ok := m[k]; ok {
+			m[k] = normalizeSubschema(v)
+		}
+	}
+	for _, k := range schemaListKeywords {
+		if list, ok := m[k].([]interface{}); ok {
+			for i, v := range list {
+				list[i] = normalizeSubschema(v)
+			}
+		}
+	}
+	for _, k := range schemaMapKeywords {
+		if subschemas, ok := m[k].(map[string]interface{}); ok {
+			for name, v := range subschemas {
+				subschemas[name] = normalizeSubschema(v)
+			}
+		}
+	}
+	return m
+}
+
+// normalizeSubschema normalizes v, a subschema, boolean ones being kept.
+func normalizeSubschema(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	return normalizeSchemaDocument(m)
+}
@@ -0,0 +1,109 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type goPhase string
+
+type goSpec struct {
+	Replicas int               `json:"replicas"`
+	Size     int32             `json:"size,omitempty"`
+	Ratio    float32           `json:"ratio,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    []uint16          `json:"ports,omitempty"`
+	Created  time.Time         `json:"created"`
+	Phase    goPhase           `json:"phase"`
+	Next     *goSpec           `json:"next,omitempty"`
+	Extra    interface{}       `json:"extra,omitempty"`
+}
+
+type goRoot struct {
+	Spec  goSpec   `json:"spec"`
+	Specs []goSpec `json:"specs,omitempty"`
+}
+
+// TestGoRoundTrip generates a schema, reads it back with ParseSchema,
+// emits it as Go with GoEmitter and checks that the source is formatted
+// and type-checks with the Go types of the original fields.
+func TestGoRoundTrip(t *testing.T) {
+	opts := Options{Required: true, Enums: map[reflect.Type][]string{reflect.TypeOf(goPhase("")): {"Pending", "Running"}}}
+	generated, err := GenerateSchemaWithOptions(reflect.TypeOf(goRoot{}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ParseSchema(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var src bytes.Buffer
+	if err := (GoEmitter{Package: "model", Root: "Root"}).Emit(schema, &src); err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(formatted, src.Bytes()) {
+		t.Errorf("the emitted source is not formatted:\n%s", src.Bytes())
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", src.Bytes(), parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("model", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, src.Bytes())
+	}
+
+	lookup := func(typeName string) *types.Struct {
+		obj := pkg.Scope().Lookup(goIdentifier(definitionName(t, generated, typeName)))
+		if obj == nil {
+			t.Fatalf("no Go type for %s in\n%s", typeName, src.Bytes())
+		}
+		s, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			t.Fatalf("%s is not a struct: %s", typeName, obj.Type())
+		}
+		return s
+	}
+	spec := lookup("goSpec")
+	fields := map[string]string{}
+	for i := 0; i < spec.NumFields(); i++ {
+		fields[spec.Field(i).Name()] = types.TypeString(spec.Field(i).Type(), types.RelativeTo(pkg))
+	}
+	for name, want := range map[string]string{
+		"Replicas": "int64",
+		"Size":     "int32",
+		"Ratio":    "float32",
+		"Labels":   "map[string]string",
+		"Created":  "time.Time",
+		"Phase":    "string",
+		"Next":     "*" + goIdentifier(definitionName(t, generated, "goSpec")),
+		"Extra":    "interface{}",
+	} {
+		if fields[name] != want {
+			t.Errorf("expected %s to be a %s, got %q", name, want, fields[name])
+		}
+	}
+	if pkg.Scope().Lookup("Root") == nil {
+		t.Errorf("no root struct in\n%s", src.Bytes())
+	}
+}
@@ -0,0 +1,20 @@
+package schemagen
+
+// groupVersionKinds returns the descriptor of the resource types of the
+// definition name of the type declared as typeName by the package of
+// pkgDesc, see Options.GroupVersionKinds and PackageDescriptor.Kinds.
+func (g *schemaGenerator) groupVersionKinds(name string, pkgDesc PackageDescriptor, typeName string) *GroupVersionKindDescriptor {
+	gvks := g.opts.GroupVersionKinds[name]
+	if len(gvks) == 0 {
+		for _, kind := range pkgDesc.Kinds {
+			if kind == typeName {
+				gvks = []GroupVersionKind{{Group: pkgDesc.Group, Version: pkgDesc.Version, Kind: kind}}
+				break
+			}
+		}
+	}
+	if len(gvks) == 0 {
+		return nil
+	}
+	return &GroupVersionKindDescriptor{GroupVersionKinds: gvks}
+}
@@ -0,0 +1,74 @@
+package schemagen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// contentAddress renames every definition of s after a short hash of its
+// canonical shape, in which references are replaced by the hashes of the
+// definitions they point to. Structurally identical definitions, including
+// ones generated independently for other roots, get the same name and are
+// merged. References closing a cycle hash to a fixed marker. It returns the
+// new name of every definition.
+func contentAddress(s *JSONSchema) map[string]string {
+	hashes := make(map[string]string)
+	visiting := make(map[string]bool)
+	var hash func(name string) (string, bool)
+	// hash returns the hash of the named definition and whether it was
+	// computed without cutting a cycle, in which case it is cached since
+	// it does not depend on where the walk started.
+	hash = func(name string) (string, bool) {
+		if h, ok := hashes[name]; ok {
+			return h, true
+		}
+		if visiting[name] {
+			return "recursive", false
+		}
+		visiting[name] = true
+		stable := true
+		def := rewriteRefs(s.Definitions[name], func(ref string) string {
+			dep, ok := schemamodel.LocalDefinition(ref)
+			if _, defined := s.Definitions[dep]; !ok || !defined {
+				return ref
+			}
+			h, ok := hash(dep)
+			stable = stable && ok
+			return definitionsPrefix + h
+		})
+		delete(visiting, name)
+		b, _ := json.Marshal(def)
+		sum := sha256.Sum256(b)
+		h := hex.EncodeToString(sum[:])[:12]
+		if stable {
+			hashes[name] = h
+		}
+		return h, stable
+	}
+
+	names := make(map[string]string)
+	for name := range s.Definitions {
+		names[name], _ = hash(name)
+	}
+	mapSchema(s, func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		if p.JSONReferenceDescriptor != nil {
+			if name, ok := schemamodel.LocalDefinition(p.Reference); ok {
+				if h, ok := names[name]; ok {
+					p.JSONReferenceDescriptor = &JSONReferenceDescriptor{
+						Reference: definitionsPrefix + h,
+					}
+				}
+			}
+		}
+		return p
+	})
+	definitions := make(map[string]JSONPropertyDescriptor)
+	for name, def := range s.Definitions {
+		definitions[names[name]] = def
+	}
+	s.Definitions = definitions
+	return names
+}
@@ -0,0 +1,54 @@
+package schemagen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Profile tailors generated schemas to their consumer.
+type Profile int
+
+const (
+	// ProfileJava targets the jsonschema2pojo pipeline generating the Java
+	// model.
+	ProfileJava Profile = iota
+	// ProfileHelm produces the values.schema.json of a Helm chart whose
+	// values are described by the root struct: a draft-07 schema titled
+	// after the root, without Java hints or vendor extensions, in which
+	// references with sibling keywords are wrapped in allOf so that
+	// validators do not ignore the titles, descriptions and defaults set
+	// with struct tags.
+	ProfileHelm
+)
+
+// ParseProfile parses the name of a profile: "java", "helm" or an empty
+// string for the Java one.
+func ParseProfile(s string) (Profile, error) {
+	switch s {
+	case "", "java":
+		return ProfileJava, nil
+	case "helm":
+		return ProfileHelm, nil
+	}
+	return 0, fmt.Errorf("Unknown profile %q.", s)
+}
+
+// profileOptions returns opts adjusted to their profile.
+func profileOptions(opts Options) Options {
+	if opts.Profile == ProfileHelm {
+		opts.StandardOnly = true
+		opts.WrapRefs = true
+	}
+	return opts
+}
+
+// applyProfile adjusts the root of the schema of t to the profile.
+func (g *schemaGenerator) applyProfile(s *JSONSchema, t reflect.Type) {
+	if g.opts.Profile == ProfileHelm {
+		s.ID = ""
+		s.Schema = "http://json-schema.org/draft-07/schema#"
+		if len(s.Title) == 0 {
+			s.Title = t.Name()
+		}
+	}
+}
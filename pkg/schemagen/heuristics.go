@@ -0,0 +1,50 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DefaultFieldFormats are the formats given by Options.FormatHeuristics to
+// the string properties whose name ends with the word, e.g. webhookURL or
+// podIP, unless Options.FieldFormats maps the word.
+var DefaultFieldFormats = map[string]string{
+	"url":      "uri",
+	"uri":      "uri",
+	"host":     "hostname",
+	"hostname": "hostname",
+	"ip":       "ipv4",
+	"email":    "email",
+	"uuid":     "uuid",
+}
+
+// guessFormat gives prop, the property name of t, the format its name
+// suggests under Options.FormatHeuristics. Properties that already have a
+// format, a pattern or an enum, or whose field is tagged with a format,
+// are left as they are.
+func (g *schemaGenerator) guessFormat(prop *JSONPropertyDescriptor, t reflect.Type, name string, tag schemagenTag) {
+	if !g.opts.FormatHeuristics || prop.JSONDescriptor == nil || prop.Type != "string" {
+		return
+	}
+	if len(prop.Format) > 0 || len(prop.Pattern) > 0 || len(prop.Enum) > 0 {
+		return
+	}
+	if _, ok := tag["format"]; ok {
+		return
+	}
+	words := camelWords(name)
+	word := strings.ToLower(words[len(words)-1])
+	format, ok := g.opts.FieldFormats[word]
+	if !ok {
+		format = DefaultFieldFormats[word]
+	}
+	if len(format) == 0 {
+		return
+	}
+	desc := *prop.JSONDescriptor
+	desc.Format = format
+	prop.JSONDescriptor = &desc
+	if g.opts.FormatHeuristicsReport != nil {
+		g.opts.FormatHeuristicsReport(g.qualifiedName(t)+"."+name, format)
+	}
+}
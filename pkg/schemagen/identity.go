@@ -0,0 +1,57 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// vendorDirs are the directories under which copies of packages are
+// vendored, their import path being the part that follows.
+var vendorDirs = []string{"/vendor/", "/Godeps/_workspace/src/"}
+
+// canonicalPkgPath returns the import path identifying the package at
+// path: the one it is an alias of in Options.PackageAliases or, with
+// Options.InternTypes, the path of the original of a vendored copy.
+func (g *schemaGenerator) canonicalPkgPath(path string) string {
+	if alias, ok := g.opts.PackageAliases[path]; ok {
+		return alias
+	}
+	if g.opts.InternTypes {
+		for _, dir := range vendorDirs {
+			if i := strings.LastIndex(path, dir); i >= 0 {
+				path = path[i+len(dir):]
+			}
+		}
+		path = strings.TrimPrefix(path, "vendor/")
+	}
+	return path
+}
+
+// intern returns the type t is identified with: the first type reached
+// with the same canonical package path and name, so that copies of a type
+// loaded through several paths share a single definition.
+func (g *schemaGenerator) intern(t reflect.Type) reflect.Type {
+	if !g.opts.InternTypes && len(g.opts.PackageAliases) == 0 || len(t.Name()) == 0 {
+		return t
+	}
+	key := g.canonicalPkgPath(t.PkgPath()) + "." + t.Name()
+	if first, ok := g.interned[key]; ok {
+		return first
+	}
+	g.interned[key] = t
+	return t
+}
+
+// packageDescriptor returns the descriptor of the package of t, looked up
+// by its canonical path if its own is not described.
+func (g *schemaGenerator) packageDescriptor(t reflect.Type) (PackageDescriptor, bool) {
+	return g.packageByPath(t.PkgPath())
+}
+
+func (g *schemaGenerator) packageByPath(path string) (PackageDescriptor, bool) {
+	if pkgDesc, ok := g.packages[path]; ok {
+		return pkgDesc, true
+	}
+	pkgDesc, ok := g.packages[g.canonicalPkgPath(path)]
+	return pkgDesc, ok
+}
@@ -0,0 +1,57 @@
+package schemagen
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// DefaultBaseURI is the base URI of the ids of generated schemas unless
+// Options.BaseURI is set.
+const DefaultBaseURI = "http://fabric8.io/fabric8/v2/"
+
+// DefaultSchemaURI is the $schema of generated schemas unless
+// Options.SchemaURI is set.
+const DefaultSchemaURI = schemamodel.DefaultSchemaURI
+
+// schemaIDData is the data of the template of schema ids.
+type schemaIDData struct {
+	BaseURI string
+	Name    string
+	Group   string
+	Version string
+}
+
+// schemaID returns the id of the schema document with the given name: the
+// name of the root type, Schema for several roots, the last element of the
+// import path for a package schema or "<group>.<version>" for a split
+// document. The Group and Version of the template are the ones of pkgDesc,
+// the descriptor of the package of the root type or of the split document.
+func schemaID(opts Options, name string, pkgDesc PackageDescriptor) (string, error) {
+	data := schemaIDData{BaseURI: opts.BaseURI, Name: name, Group: pkgDesc.Group, Version: pkgDesc.Version}
+	if len(data.BaseURI) == 0 {
+		data.BaseURI = DefaultBaseURI
+	}
+	if len(opts.IDTemplate) == 0 {
+		return data.BaseURI + name + "#", nil
+	}
+	tmpl, err := template.New("id").Parse(opts.IDTemplate)
+	if err != nil {
+		return "", fmt.Errorf("Invalid id template: %v", err)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("Invalid id template: %v", err)
+	}
+	return b.String(), nil
+}
+
+// schemaURI returns the $schema of generated schemas.
+func schemaURI(opts Options) string {
+	if len(opts.SchemaURI) == 0 {
+		return opts.Draft.URI()
+	}
+	return opts.SchemaURI
+}
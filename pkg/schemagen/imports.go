@@ -0,0 +1,79 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// JavaImports returns, for every definition of the schema, the sorted fully
+// qualified Java classes its properties refer to, including the type
+// arguments of collections such as the values of maps. The class of the
+// definition itself and java.lang classes are left out. It saves Java
+// tooling, such as annotators setting up imports and OSGi package headers,
+// from parsing javaType hints.
+func JavaImports(schema *JSONSchema) map[string][]string {
+	imports := make(map[string][]string)
+	for name, def := range schema.Definitions {
+		self := ""
+		if def.JavaTypeDescriptor != nil {
+			self = def.JavaType
+		}
+		def.JSONDefinitionsDescriptor = nil
+		classes := map[string]bool{}
+		mapDescriptor(def, func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+			if p.JavaTypeDescriptor != nil {
+				for _, class := range javaClasses(p.JavaType) {
+					if class != self && !strings.HasPrefix(class, "java.lang.") {
+						classes[class] = true
+					}
+				}
+			}
+			return p
+		})
+		list := make([]string, 0, len(classes))
+		for class := range classes {
+			list = append(list, class)
+		}
+		sort.Strings(list)
+		imports[name] = list
+	}
+	return imports
+}
+
+// javaClasses returns the qualified class names found in a Java type, e.g.
+// java.util.Map and a.b.C for "java.util.Map<String,a.b.C>".
+func javaClasses(javaType string) []string {
+	var classes []string
+	fields := strings.FieldsFunc(javaType, func(r rune) bool {
+		return strings.ContainsRune("<>,[] ?", r)
+	})
+	for _, f := range fields {
+		if strings.Contains(f, ".") {
+			classes = append(classes, f)
+		}
+	}
+	return classes
+}
+
+// JavaImportsEmitter writes the JavaImports of the schema as a JSON object
+// keyed by definition name, as a sidecar of the schema.
+type JavaImportsEmitter struct {
+	Indent string
+}
+
+func (e JavaImportsEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	var b []byte
+	var err error
+	if len(e.Indent) > 0 {
+		b, err = json.MarshalIndent(JavaImports(schema), "", e.Indent)
+	} else {
+		b, err = json.Marshal(JavaImports(schema))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,90 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// inlineReferences replaces every reference to a definition of s by a copy
+// of the definition, leaving s without definitions, see
+// Options.InlineReferences. A reference met again while its definition is
+// being inlined, i.e. one of a recursive type, becomes a permissive object.
+// References to other documents are kept.
+func inlineReferences(s *JSONSchema) {
+	in := inliner{schema: *s, visiting: make(map[string]bool)}
+	s.JSONObjectDescriptor = mapObjectDescriptor(s.JSONObjectDescriptor, in.inline)
+	s.Definitions = nil
+}
+
+type inliner struct {
+	// schema is the schema before inlining, which references resolve
+	// against.
+	schema JSONSchema
+	// visiting holds the references being inlined, which cannot be
+	// inlined again.
+	visiting map[string]bool
+}
+
+func (in inliner) inline(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if p.JSONReferenceDescriptor == nil || !strings.HasPrefix(p.Reference, "#") {
+		return p
+	}
+	ref := p.Reference
+	def, ok := in.schema.Resolve(ref)
+	if !ok {
+		return p
+	}
+	// The keywords next to the reference, such as its description or
+	// nullability, override the ones of the definition.
+	p.JSONReferenceDescriptor = nil
+	if in.visiting[ref] {
+		return overlay(prunedDescriptor(), p)
+	}
+	in.visiting[ref] = true
+	defer delete(in.visiting, ref)
+	return overlay(mapDescriptor(def, in.inline), p)
+}
+
+// overlay returns a copy of base with the fields of top set, merging the
+// descriptors and extensions both set.
+func overlay(base, top JSONPropertyDescriptor) JSONPropertyDescriptor {
+	b := reflect.ValueOf(&base).Elem()
+	overlayValue(b, reflect.ValueOf(top))
+	return base
+}
+
+func overlayValue(base, top reflect.Value) {
+	for i := 0; i < top.NumField(); i++ {
+		field := top.Field(i)
+		if isZeroValue(field) {
+			continue
+		}
+		target := base.Field(i)
+		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct && !target.IsNil() {
+			merged := reflect.New(field.Elem().Type())
+			merged.Elem().Set(target.Elem())
+			overlayValue(merged.Elem(), field.Elem())
+			target.Set(merged)
+			continue
+		}
+		if field.Kind() == reflect.Map && !target.IsNil() {
+			merged := reflect.MakeMap(field.Type())
+			for _, m := range []reflect.Value{target, field} {
+				for _, k := range m.MapKeys() {
+					merged.SetMapIndex(k, m.MapIndex(k))
+				}
+			}
+			target.Set(merged)
+			continue
+		}
+		target.Set(field)
+	}
+}
+
+func isZeroValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,51 @@
+package schemagen
+
+import "reflect"
+
+// The interfaces below are the stable extension points of the package:
+// their methods will not change, and new capabilities are added through
+// new interfaces instead, so that tools can embed parts of the generator
+// without depending on its internal structures.
+//
+// Emitter, which renders a generated schema, is defined in emit.go.
+
+// Walker walks Go types and describes them as JSON schemas.
+type Walker interface {
+	// Walk generates the schema of the root struct type, with a
+	// definition for every type reached from it.
+	Walk(root reflect.Type) (*JSONSchema, error)
+	// Describe returns the descriptor of a single type, referring to
+	// struct types by reference.
+	Describe(t reflect.Type) JSONPropertyDescriptor
+}
+
+// TypeResolver looks up the compiled type of a type declared in a Go
+// package, for generators working from package sources.
+type TypeResolver interface {
+	Resolve(pkgPath, name string) (reflect.Type, bool)
+}
+
+// TypeResolverFunc adapts a function to the TypeResolver interface.
+type TypeResolverFunc func(pkgPath, name string) (reflect.Type, bool)
+
+func (f TypeResolverFunc) Resolve(pkgPath, name string) (reflect.Type, bool) {
+	return f(pkgPath, name)
+}
+
+// NewWalker returns a Walker generating schemas with the given options.
+// Every walk starts from scratch, so a Walker can be reused.
+func NewWalker(opts Options) Walker {
+	return walker{opts}
+}
+
+type walker struct {
+	opts Options
+}
+
+func (w walker) Walk(root reflect.Type) (*JSONSchema, error) {
+	return GenerateSchemaWithOptions(root, w.opts)
+}
+
+func (w walker) Describe(t reflect.Type) JSONPropertyDescriptor {
+	return DescribeWithOptions(t, w.opts)
+}
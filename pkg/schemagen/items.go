@@ -0,0 +1,104 @@
+package schemagen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// AnyItemsStyle selects how the items of slices and arrays of empty
+// interfaces, such as the objects of a template, are described.
+type AnyItemsStyle int
+
+const (
+	// AnyItemsEmpty describes them as the empty schema, {}.
+	AnyItemsEmpty AnyItemsStyle = iota
+	// AnyItemsTrue describes them as the boolean schema true, which needs
+	// draft-07 or later.
+	AnyItemsTrue
+)
+
+// ParseAnyItemsStyle parses the name of an items style: "{}" or "true". An
+// empty name is AnyItemsEmpty.
+func ParseAnyItemsStyle(s string) (AnyItemsStyle, error) {
+	switch s {
+	case "", "{}":
+		return AnyItemsEmpty, nil
+	case "true":
+		return AnyItemsTrue, nil
+	}
+	return 0, fmt.Errorf("Unknown items style %q.", s)
+}
+
+func trueSchema() JSONPropertyDescriptor {
+	accept := true
+	return JSONPropertyDescriptor{Boolean: &accept}
+}
+
+// itemsDescriptor describes elem, the type of the items of a slice or an
+// array, following Options.AnyItems for empty interfaces.
+func (g *schemaGenerator) itemsDescriptor(elem reflect.Type) JSONPropertyDescriptor {
+	items := g.elementDescriptor("[]", elem)
+	if elem.Kind() != reflect.Interface || elem.NumMethod() > 0 {
+		return items
+	}
+	if _, ok := g.implementations[elem]; !ok && g.opts.AnyItems == AnyItemsTrue && g.opts.Draft != Draft04 {
+		return trueSchema()
+	}
+	return items
+}
+
+// applyItems describes the items of the field of t, whose property is
+// name, as declared by its items tag option or by Options.ItemTypes: "{}",
+// "true" or the types they can have, separated by semicolons and resolved
+// like the ones of the oneOf option, e.g. schemagen:"items=Pod;Service".
+// Only slices and arrays of empty interfaces are affected.
+func (g *schemaGenerator) applyItems(prop *JSONPropertyDescriptor, t reflect.Type, field reflect.StructField, name string, tag schemagenTag) {
+	value, ok := tag["items"]
+	if typed, configured := g.opts.ItemTypes[g.qualifiedName(t)+"."+name]; configured {
+		value, ok = typed, true
+	}
+	if !ok {
+		return
+	}
+	ft := field.Type
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array || ft.Elem().Kind() != reflect.Interface || ft.Elem().NumMethod() > 0 || prop.JSONArrayDescriptor == nil {
+		g.warnf("%s.%s declares its items but is not a slice of empty interfaces.", t.Name(), field.Name)
+		return
+	}
+	array := *prop.JSONArrayDescriptor
+	switch value {
+	case "{}":
+		array.Items = JSONPropertyDescriptor{}
+	case "true":
+		if g.opts.Draft == Draft04 {
+			g.warnf("%s.%s declares items true, which draft-04 lacks, described as {}.", t.Name(), field.Name)
+			array.Items = JSONPropertyDescriptor{}
+		} else {
+			array.Items = trueSchema()
+		}
+	default:
+		types := g.tagUnionTypes(t, field, value, true)
+		if len(types) == 0 {
+			return
+		}
+		alts := make([]JSONPropertyDescriptor, 0, len(types))
+		g.path = append(g.path, t.Name()+"."+name)
+		for _, it := range types {
+			alts = append(alts, g.elementDescriptor("[]", it))
+		}
+		g.path = g.path[:len(g.path)-1]
+		if len(alts) == 1 {
+			array.Items = alts[0]
+			break
+		}
+		array.Items = JSONPropertyDescriptor{
+			JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+				OneOf: alts,
+			},
+		}
+	}
+	prop.JSONArrayDescriptor = &array
+}
@@ -0,0 +1,68 @@
+package schemagen
+
+import "reflect"
+
+// JavaHints are the jsonschema2pojo hints of a single property, see
+// Options.JavaHints. Empty hints are left as generated.
+type JavaHints struct {
+	// JavaType replaces the Java type of the property.
+	JavaType string `yaml:"javaType"`
+	// ExistingJavaType is a class jsonschema2pojo uses without generating
+	// it, e.g. "java.util.Map<String,io.fabric8.kubernetes.api.model.Quantity>".
+	ExistingJavaType string `yaml:"existingJavaType"`
+	// JavaEnumNames names the constants of the Java enum of an enum
+	// property, in the order of its values.
+	JavaEnumNames []string `yaml:"javaEnumNames"`
+	// Annotations are added to the property as customAnnotations, e.g.
+	// Jackson annotations such as
+	// "@com.fasterxml.jackson.annotation.JsonIgnore".
+	Annotations []string `yaml:"annotations"`
+}
+
+// javaHints returns the hints of the field f of t, whose property is name:
+// the ones returned by Options.JavaHintsFunc, overridden by the non-empty
+// ones of Options.JavaHints.
+func (g *schemaGenerator) javaHints(t reflect.Type, f reflect.StructField, name string) (JavaHints, bool) {
+	var hints JavaHints
+	found := false
+	if g.opts.JavaHintsFunc != nil {
+		hints, found = g.opts.JavaHintsFunc(t, f)
+	}
+	if h, ok := g.opts.JavaHints[g.qualifiedName(t)+"."+name]; ok {
+		found = true
+		if len(h.JavaType) > 0 {
+			hints.JavaType = h.JavaType
+		}
+		if len(h.ExistingJavaType) > 0 {
+			hints.ExistingJavaType = h.ExistingJavaType
+		}
+		if len(h.JavaEnumNames) > 0 {
+			hints.JavaEnumNames = h.JavaEnumNames
+		}
+		if len(h.Annotations) > 0 {
+			hints.Annotations = h.Annotations
+		}
+	}
+	return hints, found
+}
+
+// applyJavaHints returns a copy of prop carrying hints.
+func applyJavaHints(prop JSONPropertyDescriptor, hints JavaHints) JSONPropertyDescriptor {
+	if len(hints.JavaType) > 0 {
+		prop.JavaTypeDescriptor = &JavaTypeDescriptor{
+			JavaType: hints.JavaType,
+		}
+	}
+	if len(hints.ExistingJavaType) > 0 || len(hints.JavaEnumNames) > 0 {
+		prop.JavaHintsDescriptor = &JavaHintsDescriptor{
+			ExistingJavaType: hints.ExistingJavaType,
+			JavaEnumNames:    hints.JavaEnumNames,
+		}
+	}
+	if len(hints.Annotations) > 0 {
+		prop.JavaAnnotationsDescriptor = &JavaAnnotationsDescriptor{
+			CustomAnnotations: hints.Annotations,
+		}
+	}
+	return prop
+}
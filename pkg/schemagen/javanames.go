@@ -0,0 +1,56 @@
+package schemagen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// javaName returns the Java field name of the property name under the
+// acronyms of Options.JavaAcronyms, e.g. hostIP for hostIp or apiVersion
+// for APIVersion with IP as acronym, or an empty string if it is name
+// itself. Accessors are named after the field by jsonschema2pojo.
+func (g *schemaGenerator) javaName(name string) string {
+	if len(g.opts.JavaAcronyms) == 0 {
+		return ""
+	}
+	acronyms := make(map[string]string, len(g.opts.JavaAcronyms))
+	for _, a := range g.opts.JavaAcronyms {
+		acronyms[strings.ToUpper(a)] = a
+	}
+	words := camelWords(name)
+	for i, word := range words {
+		upper := strings.ToUpper(word)
+		switch acronym, ok := acronyms[upper]; {
+		case i == 0:
+			// Field names start in lower case, e.g. apiVersion.
+			words[i] = strings.ToLower(word)
+		case ok:
+			words[i] = acronym
+		case word == upper && len(word) > 1:
+			// Capitals not listed are spelled as a word, e.g. podCidr.
+			words[i] = word[:1] + strings.ToLower(word[1:])
+		}
+	}
+	if javaName := strings.Join(words, ""); javaName != name {
+		return javaName
+	}
+	return ""
+}
+
+// camelWords splits a camel case name into words, runs of capitals being
+// words of their own, e.g. host, IP, Address for hostIPAddress.
+func camelWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, r := runes[i-1], runes[i]
+		lowerToUpper := unicode.IsLower(prev) && unicode.IsUpper(r)
+		endOfCapitals := unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || endOfCapitals {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
@@ -0,0 +1,44 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type javaFoo struct {
+	Name string `json:"name"`
+}
+
+type javaFoos []*javaFoo
+
+func TestJavaTypeSignatures(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{javaFoo{}, "javaFoo"},
+		{(*javaFoo)(nil), "javaFoo"},
+		{(**javaFoo)(nil), "javaFoo"},
+		{[]javaFoo{}, "java.util.ArrayList<javaFoo>"},
+		{[]*javaFoo{}, "java.util.ArrayList<javaFoo>"},
+		{[][]*javaFoo{}, "java.util.ArrayList<java.util.ArrayList<javaFoo>>"},
+		{[2]*javaFoo{}, "java.util.ArrayList<javaFoo>"},
+		{(*[]*javaFoo)(nil), "java.util.ArrayList<javaFoo>"},
+		{javaFoos{}, "java.util.ArrayList<javaFoo>"},
+		{map[string]*javaFoo{}, "java.util.Map<String,javaFoo>"},
+		{map[string][]*javaFoo{}, "java.util.Map<String,java.util.ArrayList<javaFoo>>"},
+		{map[string]map[string]*javaFoo{}, "java.util.Map<String,java.util.Map<String,javaFoo>>"},
+		{[]map[string]*javaFoo{}, "java.util.ArrayList<java.util.Map<String,javaFoo>>"},
+		{[]*string{}, "java.util.ArrayList<String>"},
+		{map[string]*int32{}, "java.util.Map<String,Integer>"},
+		{map[int64][]*bool{}, "java.util.Map<Long,java.util.ArrayList<Boolean>>"},
+		{[]interface{}{}, "java.util.ArrayList<Object>"},
+	}
+	g := newSchemaGenerator(Options{})
+	for _, test := range tests {
+		typ := reflect.TypeOf(test.value)
+		if got := g.javaType(typ); got != test.want {
+			t.Errorf("the Java type of %s is %s, want %s", typ, got, test.want)
+		}
+	}
+}
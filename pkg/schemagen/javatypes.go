@@ -0,0 +1,34 @@
+package schemagen
+
+// renameJavaTypes returns a function moving the javaType hint of a
+// descriptor under keyword, or dropping it if keyword is "-". The other
+// jsonschema2pojo class hints become extensions of their own name when
+// renamed, and are dropped along with it otherwise.
+func renameJavaTypes(keyword string) func(JSONPropertyDescriptor) JSONPropertyDescriptor {
+	return func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		java := p.JavaTypeDescriptor
+		if java == nil {
+			return p
+		}
+		p.JavaTypeDescriptor = nil
+		if keyword == "-" {
+			return p
+		}
+		extensions := make(map[string]interface{}, len(p.Extensions)+4)
+		for k, v := range p.Extensions {
+			extensions[k] = v
+		}
+		extensions[keyword] = java.JavaType
+		if len(java.JavaInterfaces) > 0 {
+			extensions["javaInterfaces"] = java.JavaInterfaces
+		}
+		if java.GenerateBuilders {
+			extensions["generateBuilders"] = true
+		}
+		if java.JavaSerializable {
+			extensions["javaSerializable"] = true
+		}
+		p.Extensions = extensions
+		return p
+	}
+}
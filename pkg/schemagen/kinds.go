@@ -0,0 +1,113 @@
+package schemagen
+
+import "reflect"
+
+// Mapping describes how values of a primitive reflect.Kind are rendered.
+// Format, if set, is the format of their JSON type, e.g. int64, and
+// Unsigned adds minimum: 0.
+type Mapping struct {
+	JSONType string
+	JavaType string
+	Format   string
+	Unsigned bool
+}
+
+// DefaultKindMappings returns the mappings used for primitive kinds unless
+// overridden through Options.KindMappings. int and uint are taken as 64 bits
+// wide, as on the platforms Go programs run on, so that no valid value
+// fails validation. The integers which may not fit a Java int are Longs.
+// Complex numbers, which encoding/json cannot marshal, have no mapping and
+// are reported.
+func DefaultKindMappings() map[reflect.Kind]Mapping {
+	int32Mapping := Mapping{JSONType: "integer", JavaType: "int", Format: "int32"}
+	int64Mapping := Mapping{JSONType: "integer", JavaType: "Long", Format: "int64"}
+	uint32Mapping := Mapping{JSONType: "integer", JavaType: "int", Format: "int32", Unsigned: true}
+	uint64Mapping := Mapping{JSONType: "integer", JavaType: "Long", Format: "int64", Unsigned: true}
+	return map[reflect.Kind]Mapping{
+		reflect.Bool:    {JSONType: "boolean", JavaType: "bool"},
+		reflect.Int:     int64Mapping,
+		reflect.Int8:    int32Mapping,
+		reflect.Int16:   int32Mapping,
+		reflect.Int32:   int32Mapping,
+		reflect.Int64:   int64Mapping,
+		reflect.Uint8:   uint32Mapping,
+		reflect.Uint16:  uint32Mapping,
+		reflect.Uint:    uint64Mapping,
+		reflect.Uint32:  uint64Mapping,
+		reflect.Uint64:  uint64Mapping,
+		reflect.Float32: {JSONType: "number", JavaType: "float", Format: "float"},
+		reflect.Float64: {JSONType: "number", JavaType: "double", Format: "double"},
+		reflect.String:  {JSONType: "string", JavaType: "String"},
+	}
+}
+
+func newKindMappings(overrides map[reflect.Kind]Mapping) map[reflect.Kind]Mapping {
+	mappings := DefaultKindMappings()
+	for k, m := range overrides {
+		mappings[k] = m
+	}
+	return mappings
+}
+
+// Generator gives hooks access to the generation in progress.
+type Generator struct {
+	g *schemaGenerator
+}
+
+// OverrideKind emits every value of the primitive kind k, such as
+// reflect.Uint8, with desc instead of the JSON type of its mapping, e.g. to
+// describe bytes encoded as one character strings. The Java type of desc,
+// if any, replaces the one of the mapping. It panics if k is not a
+// primitive kind.
+func (gen *Generator) OverrideKind(k reflect.Kind, desc JSONPropertyDescriptor) {
+	if _, ok := gen.g.kinds[k]; !ok {
+		panic("schemagen: OverrideKind called with non-primitive kind " + k.String())
+	}
+	gen.g.kindDescriptors[k] = desc
+}
+
+// TypeHandler describes the values of a type, taking full control of their
+// rendering: format, javaType, enum values and so on.
+type TypeHandler func(t reflect.Type) JSONPropertyDescriptor
+
+// RegisterTypeHandler describes every value of t, or of a pointer to t, with
+// the descriptor returned by fn instead of walking t, e.g. for
+// resource.Quantity or intstr.IntOrString. It takes precedence over the
+// type map. The descriptor should have a javaType, which is also used for
+// the elements of slices and the values of maps of t; t is described as an
+// Object in those otherwise.
+func (gen *Generator) RegisterTypeHandler(t reflect.Type, fn TypeHandler) {
+	gen.g.handlers[t] = fn
+}
+
+// kindDescriptor returns the descriptor of the primitive kind of t.
+func (g *schemaGenerator) kindDescriptor(t reflect.Type, m Mapping) JSONPropertyDescriptor {
+	if desc, ok := g.kindDescriptors[t.Kind()]; ok {
+		return desc
+	}
+	return mappingDescriptor(m)
+}
+
+// mappingDescriptor describes the values of a primitive kind after their
+// mapping.
+func mappingDescriptor(m Mapping) JSONPropertyDescriptor {
+	desc := JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type:   m.JSONType,
+			Format: m.Format,
+		},
+	}
+	if m.Unsigned {
+		min := 0.0
+		desc.Minimum = &min
+	}
+	return desc
+}
+
+// kindJavaType returns the Java type of the primitive kind of t.
+func (g *schemaGenerator) kindJavaType(t reflect.Type, m Mapping) string {
+	if desc, ok := g.kindDescriptors[t.Kind()]; ok && desc.JavaTypeDescriptor != nil {
+		return desc.JavaType
+	}
+	return m.JavaType
+}
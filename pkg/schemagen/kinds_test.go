@@ -0,0 +1,38 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type integerWidths struct {
+	Int    int    `json:"int"`
+	Uint   uint   `json:"uint"`
+	Int32  int32  `json:"int32"`
+	Uint16 uint16 `json:"uint16"`
+}
+
+// TestIntegerMappings checks the formats of the integers,
+// and that int accepts values not fitting 32 bits.
+func TestIntegerMappings(t *testing.T) {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(integerWidths{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name, format string
+	}{
+		{"int", "int64"},
+		{"uint", "int64"},
+		{"int32", "int32"},
+		{"uint16", "int32"},
+	} {
+		p := schema.Properties[test.name]
+		if p.JSONDescriptor == nil || p.Format != test.format {
+			t.Errorf("%s: expected the %s format, got %+v", test.name, test.format, p.JSONDescriptor)
+		}
+	}
+	if err := Verify(schema, integerWidths{Int: 1 << 40, Uint: 1 << 40}); err != nil {
+		t.Error(err)
+	}
+}
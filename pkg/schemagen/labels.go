@@ -0,0 +1,153 @@
+package schemagen
+
+import "strings"
+
+// labelMarker introduces the labels of a type in its doc comment, e.g.
+// "+schemagen:labels=beta".
+const labelMarker = "+schemagen:labels="
+
+// parseLabelMarkers returns the labels declared by the marker lines of a
+// doc comment and the comment without them.
+func parseLabelMarkers(text string) ([]string, string) {
+	var labels []string
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, labelMarker) {
+			for _, label := range strings.Split(strings.TrimPrefix(trimmed, labelMarker), ",") {
+				if label = strings.TrimSpace(label); len(label) > 0 {
+					labels = append(labels, label)
+				}
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return labels, strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// propertiesMarker declares the properties policy of a type in its doc
+// comment, e.g. "+schemagen:properties=closed".
+const propertiesMarker = "+schemagen:properties="
+
+// parsePropertiesMarker returns the properties policy declared by a marker
+// line of a doc comment and the comment without it.
+func parsePropertiesMarker(text string) (string, bool, string) {
+	var policy string
+	found := false
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, propertiesMarker) {
+			policy, found = strings.TrimSpace(strings.TrimPrefix(trimmed, propertiesMarker)), true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return policy, found, strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// definitionLabels returns the labels of every definition: the ones of
+// Options.Labels and the ones declared in the doc comments of the types.
+func (g *schemaGenerator) definitionLabels() map[string][]string {
+	labels := make(map[string][]string)
+	for name, l := range g.opts.Labels {
+		labels[name] = append(labels[name], l...)
+	}
+	for t := range g.types {
+		if len(t.PkgPath()) == 0 || len(t.Name()) == 0 {
+			continue
+		}
+		if l := g.packageDocs(t).labels[t.Name()]; len(l) > 0 {
+			name := g.qualifiedName(t)
+			labels[name] = append(labels[name], l...)
+		}
+	}
+	return labels
+}
+
+// applyLabelFilter drops the labelled definitions carrying none of the
+// labels of Options.LabelFilter. Definitions without labels, such as
+// shared metadata types, are kept. The properties referring to dropped
+// definitions are removed, and the definitions still referring to them
+// otherwise, e.g. through allOf, are dropped in turn.
+func (g *schemaGenerator) applyLabelFilter(s *JSONSchema) {
+	if len(g.opts.LabelFilter) == 0 {
+		return
+	}
+	wanted := map[string]bool{}
+	for _, label := range g.opts.LabelFilter {
+		wanted[label] = true
+	}
+	dropped := map[string]bool{}
+	for name, labels := range g.definitionLabels() {
+		if _, ok := s.Definitions[name]; !ok || len(labels) == 0 {
+			continue
+		}
+		keep := false
+		for _, label := range labels {
+			keep = keep || wanted[label]
+		}
+		if !keep {
+			dropped[name] = true
+		}
+	}
+	if len(dropped) == 0 {
+		return
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, name := range sortedDefinitionNames(s.Definitions) {
+			if dropped[name] {
+				continue
+			}
+			def := s.Definitions[name]
+			def.JSONObjectDescriptor = withoutDroppedProperties(def.JSONObjectDescriptor, dropped)
+			s.Definitions[name] = def
+			if refersTo(def, dropped) {
+				dropped[name] = true
+				changed = true
+			}
+		}
+	}
+	for name := range dropped {
+		delete(s.Definitions, name)
+	}
+	s.JSONObjectDescriptor = withoutDroppedProperties(s.JSONObjectDescriptor, dropped)
+}
+
+// withoutDroppedProperties returns a copy of o without the properties
+// referring to the dropped definitions.
+func withoutDroppedProperties(o *JSONObjectDescriptor, dropped map[string]bool) *JSONObjectDescriptor {
+	if o == nil {
+		return nil
+	}
+	desc := *o
+	desc.Properties = make(map[string]JSONPropertyDescriptor, len(o.Properties))
+	removed := map[string]bool{}
+	for k, v := range o.Properties {
+		if refersTo(v, dropped) {
+			removed[k] = true
+			continue
+		}
+		desc.Properties[k] = v
+	}
+	if len(removed) == 0 {
+		return o
+	}
+	desc.Required = nil
+	for _, k := range o.Required {
+		if !removed[k] {
+			desc.Required = append(desc.Required, k)
+		}
+	}
+	return &desc
+}
+
+func refersTo(p JSONPropertyDescriptor, names map[string]bool) bool {
+	for _, name := range referencedDefinitions(p) {
+		if names[name] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package schemagen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// Lint rules reported in Finding.Rule.
+const (
+	// LintEmptyDescriptor flags descriptors without any keyword, which
+	// accept any value.
+	LintEmptyDescriptor = "empty-descriptor"
+	// LintBareObject flags objects without properties or value schema.
+	LintBareObject = "bare-object"
+	// LintUnreferenced flags definitions nothing refers to, except
+	// abstract ones.
+	LintUnreferenced = "unreferenced-definition"
+	// LintRefWithoutJavaType flags references without a javaType hint.
+	LintRefWithoutJavaType = "ref-without-java-type"
+	// LintCaseDuplicate flags properties whose names only differ by case.
+	LintCaseDuplicate = "case-duplicate"
+)
+
+// Finding is a problem found in a schema by Lint.
+type Finding struct {
+	Rule string `json:"rule"`
+	// Pointer is the JSON pointer of the offending descriptor.
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+// Lint checks a generated schema for common problems and returns its
+// findings sorted by pointer.
+func Lint(s *JSONSchema) []Finding {
+	var findings []Finding
+	add := func(rule, pointer, format string, args ...interface{}) {
+		findings = append(findings, Finding{Rule: rule, Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var lint func(pointer string, p JSONPropertyDescriptor, javaTyped bool)
+	lintProperties := func(pointer string, props map[string]JSONPropertyDescriptor) {
+		lower := make(map[string]string)
+		for _, name := range sortedPropertyNames(props) {
+			if other, ok := lower[strings.ToLower(name)]; ok {
+				add(LintCaseDuplicate, pointer+"/properties/"+schemamodel.EscapePointer(name), "Property %s only differs by case from %s.", name, other)
+			}
+			lower[strings.ToLower(name)] = name
+			lint(pointer+"/properties/"+schemamodel.EscapePointer(name), props[name], false)
+		}
+	}
+	lint = func(pointer string, p JSONPropertyDescriptor, javaTyped bool) {
+		if reflect.DeepEqual(p, JSONPropertyDescriptor{}) {
+			add(LintEmptyDescriptor, pointer, "Descriptor is empty and accepts any value.")
+			return
+		}
+		javaTyped = javaTyped || p.JavaTypeDescriptor != nil
+		if p.JSONReferenceDescriptor != nil && !javaTyped {
+			add(LintRefWithoutJavaType, pointer, "Reference to %s has no javaType.", p.Reference)
+		}
+		if p.JSONDescriptor != nil && p.Type == "object" && p.JSONMapDescriptor == nil &&
+			(p.JSONObjectDescriptor == nil || len(p.Properties) == 0) && p.EmbeddedResourceDescriptor == nil {
+			add(LintBareObject, pointer, "Object has neither properties nor a value schema.")
+		}
+		if p.JSONObjectDescriptor != nil {
+			lintProperties(pointer, p.Properties)
+		}
+		if p.JSONArrayDescriptor != nil {
+			lint(pointer+"/items", p.Items, false)
+		}
+		if p.JSONMapDescriptor != nil {
+			lint(pointer+"/additionalProperties", p.MapValueType, false)
+		}
+		if p.JSONCombinatorDescriptor != nil {
+			for keyword, alts := range map[string][]JSONPropertyDescriptor{"allOf": p.AllOf, "anyOf": p.AnyOf, "oneOf": p.OneOf} {
+				for i, alt := range alts {
+					lint(fmt.Sprintf("%s/%s/%d", pointer, keyword, i), alt, javaTyped)
+				}
+			}
+		}
+		if p.JSONDefinitionsDescriptor != nil {
+			for _, name := range sortedDefinitionNames(p.Definitions) {
+				lint(pointer+"/definitions/"+schemamodel.EscapePointer(name), p.Definitions[name], false)
+			}
+		}
+	}
+
+	referenced := make(map[string]bool)
+	if s.JSONObjectDescriptor != nil {
+		lintProperties("", s.Properties)
+		for _, name := range referencedDefinitions(JSONPropertyDescriptor{JSONObjectDescriptor: s.JSONObjectDescriptor}) {
+			referenced[name] = true
+		}
+	}
+	for _, name := range sortedDefinitionNames(s.Definitions) {
+		def := s.Definitions[name]
+		lint("/definitions/"+schemamodel.EscapePointer(name), def, false)
+		for _, dep := range referencedDefinitions(def) {
+			if dep != name {
+				referenced[dep] = true
+			}
+		}
+	}
+	for _, name := range sortedDefinitionNames(s.Definitions) {
+		if !referenced[name] && s.Definitions[name].AbstractDescriptor == nil {
+			add(LintUnreferenced, "/definitions/"+schemamodel.EscapePointer(name), "Definition %s is never referenced.", name)
+		}
+	}
+
+	sort.Sort(findingsByPointer(findings))
+	return findings
+}
+
+type findingsByPointer []Finding
+
+func (f findingsByPointer) Len() int      { return len(f) }
+func (f findingsByPointer) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f findingsByPointer) Less(i, j int) bool {
+	if f[i].Pointer != f[j].Pointer {
+		return f[i].Pointer < f[j].Pointer
+	}
+	return f[i].Rule < f[j].Rule
+}
@@ -0,0 +1,111 @@
+package schemagen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PackageStructs type-checks the sources of the Go package with the given
+// import path, like GenerateSourceSchema, and returns the sorted names of
+// the exported struct types it declares.
+func PackageStructs(pkgPath string) ([]string, error) {
+	pkg, err := checkPackage(token.NewFileSet(), pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	return packageStructs(pkg), nil
+}
+
+func packageStructs(pkg *types.Package) []string {
+	var names []string
+	for _, name := range pkg.Scope().Names() {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() || obj.IsAlias() {
+			continue
+		}
+		if _, ok := obj.Type().Underlying().(*types.Struct); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TypeList returns a resolver finding types among the given ones.
+func TypeList(types ...reflect.Type) TypeResolver {
+	index := make(typeList)
+	for _, t := range types {
+		index[typeKey(t)] = t
+	}
+	return index
+}
+
+type typeList map[string]reflect.Type
+
+func (l typeList) Resolve(pkgPath, name string) (reflect.Type, bool) {
+	t, ok := l[pkgPath+"."+name]
+	return t, ok
+}
+
+// GeneratePackageSchema generates a combined schema for every exported
+// struct declared in the package with the given import path, found by
+// type-checking its sources. The root of the schema has one property per
+// struct, named after it. The structs are described from their compiled
+// types when opts.Resolver finds all of them, so that the options keyed by
+// reflect.Type apply. Otherwise, e.g. when a struct was added upstream
+// since the generator was built, the whole package is described from its
+// sources like GenerateSourceSchema, with a warning naming the structs
+// that could not be resolved.
+func GeneratePackageSchema(pkgPath string, opts Options) (*JSONSchema, error) {
+	fset := token.NewFileSet()
+	pkg, err := checkPackage(fset, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	names := packageStructs(pkg)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Package %s declares no exported struct.", pkgPath)
+	}
+	var roots []reflect.Type
+	var missing []string
+	for _, name := range names {
+		var t reflect.Type
+		ok := false
+		if opts.Resolver != nil {
+			t, ok = opts.Resolver.Resolve(pkgPath, name)
+		}
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		roots = append(roots, t)
+	}
+	var s *JSONSchema
+	if len(missing) == 0 {
+		s, err = GenerateSchemas(roots, opts)
+	} else {
+		g := newSourceGenerator(opts)
+		if opts.Resolver != nil {
+			g.warnf("Unable to resolve types of package %s, describing it from its sources: %s.", pkgPath, strings.Join(missing, ", "))
+		}
+		s, err = g.generatePackage(pkg, names)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pkgDesc PackageDescriptor
+	for _, p := range opts.Packages {
+		if p.GoPackage == pkgPath {
+			pkgDesc = p
+		}
+	}
+	if s.ID, err = schemaID(opts, path.Base(pkgPath), pkgDesc); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
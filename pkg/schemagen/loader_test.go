@@ -0,0 +1,57 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const loaderPkg = "github.com/csrwng/origin-schema-generator/pkg/schemagen/testdata/loaderpkg"
+
+type loaderWidget struct {
+	Name string `json:"name"`
+}
+
+func TestPackageStructs(t *testing.T) {
+	names, err := PackageStructs(loaderPkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"Gadget", "Widget"}) {
+		t.Errorf("Unexpected structs: %v", names)
+	}
+}
+
+func TestGeneratePackageSchemaUnresolved(t *testing.T) {
+	resolver := TypeResolverFunc(func(pkgPath, name string) (reflect.Type, bool) {
+		if pkgPath == loaderPkg && name == "Widget" {
+			return reflect.TypeOf(loaderWidget{}), true
+		}
+		return nil, false
+	})
+	for _, test := range []struct {
+		name     string
+		resolver TypeResolver
+		warned   bool
+	}{
+		{"no resolver", nil, false},
+		{"partial resolver", resolver, true},
+	} {
+		schema, err := GeneratePackageSchema(loaderPkg, Options{Resolver: test.resolver})
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		for _, name := range []string{"Gadget", "Widget"} {
+			if _, ok := schema.Properties[name]; !ok {
+				t.Errorf("%s: missing root %s", test.name, name)
+			}
+		}
+		if warned := len(schema.Warnings) > 0 && strings.Contains(schema.Warnings[0], "Gadget"); warned != test.warned {
+			t.Errorf("%s: unexpected warnings %v", test.name, schema.Warnings)
+		}
+	}
+
+	if _, err := GeneratePackageSchema(loaderPkg, Options{Resolver: resolver, Strict: true}); err == nil {
+		t.Errorf("Expected unresolved structs to fail strict generation")
+	}
+}
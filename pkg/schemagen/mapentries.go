@@ -0,0 +1,125 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// syntheticType names a definition synthesized for a Go type that has no
+// definition of its own, such as the entries of a map.
+type syntheticType struct {
+	name     string
+	javaType string
+
+	// label and pkgPath are the unprefixed name and the package of the
+	// anonymous struct types named by registerAnonymousStruct.
+	label   string
+	pkgPath string
+}
+
+// usesMapEntries reports whether the map type t is emitted as an array of
+// key/value entries.
+func (g *schemaGenerator) usesMapEntries(t reflect.Type) bool {
+	return g.opts.MapEntries && t.Key().Kind() != reflect.String
+}
+
+// typeLabel returns a capitalized name for t usable in synthesized names.
+func typeLabel(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := t.Name()
+	switch {
+	case len(name) > 0:
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "ListOf" + typeLabel(t.Elem())
+	case t.Kind() == reflect.Map:
+		return typeLabel(t.Key()) + "To" + typeLabel(t.Elem())
+	default:
+		name = t.Kind().String()
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// registerMapEntry names the entry definition of the map type t after its
+// key and value types, in the package of the first one having a descriptor.
+func (g *schemaGenerator) registerMapEntry(t reflect.Type) {
+	if _, ok := g.synthetic[t]; !ok {
+		name := typeLabel(t.Key()) + typeLabel(t.Elem()) + "Entry"
+		synthetic := syntheticType{name: name, javaType: name}
+		for _, p := range []reflect.Type{elemType(t.Elem()), elemType(t.Key())} {
+			if pkgDesc, ok := g.packageDescriptor(p); ok {
+				synthetic.name = pkgDesc.Prefix + name
+				synthetic.javaType = pkgDesc.JavaPackage + "." + name
+				break
+			}
+		}
+		g.synthetic[t] = synthetic
+		delete(g.javaTypes, t)
+	}
+}
+
+// mapEntriesDescriptor describes a map as an array of entry objects with a
+// key and a value property, defined once per map type.
+func (g *schemaGenerator) mapEntriesDescriptor(t reflect.Type) JSONPropertyDescriptor {
+	g.registerMapEntry(t)
+	entry := g.defineType(t, func() JSONPropertyDescriptor {
+		return JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "object",
+			},
+			JSONObjectDescriptor: &JSONObjectDescriptor{
+				Properties: map[string]JSONPropertyDescriptor{
+					"key":   g.getPropertyDescriptor(t.Key()),
+					"value": g.getPropertyDescriptor(t.Elem()),
+				},
+				AdditionalProperties: true,
+			},
+			JavaTypeDescriptor: &JavaTypeDescriptor{
+				JavaType: g.javaType(t),
+			},
+		}
+	})
+	return JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type: "array",
+		},
+		JSONArrayDescriptor: &JSONArrayDescriptor{
+			Items: entry,
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: "java.util.ArrayList<" + g.javaType(t) + ">",
+		},
+	}
+}
+
+// registerAnonymousStruct names the anonymous struct type of field, the
+// field of parent, after both in the package of parent, e.g. WidgetInner
+// for the field Inner of Widget, so that the Java class of its object has
+// a name of its own. Identical anonymous structs are the same type and
+// are named after the first field reached.
+func (g *schemaGenerator) registerAnonymousStruct(parent reflect.Type, field reflect.StructField) {
+	t := elemType(field.Type)
+	if t.Kind() != reflect.Struct || len(t.Name()) > 0 || t.NumField() == 0 {
+		return
+	}
+	if _, ok := g.synthetic[t]; ok {
+		return
+	}
+	label, pkgPath := parent.Name(), parent.PkgPath()
+	if outer, ok := g.synthetic[parent]; ok {
+		label, pkgPath = outer.label, outer.pkgPath
+	}
+	label += field.Name
+	synthetic := syntheticType{
+		name:     g.packageDefinitionName(pkgPath, label),
+		javaType: label,
+		label:    label,
+		pkgPath:  pkgPath,
+	}
+	if pkgDesc, ok := g.packageByPath(pkgPath); ok {
+		synthetic.javaType = pkgDesc.JavaPackage + "." + label
+	}
+	g.synthetic[t] = synthetic
+	delete(g.javaTypes, t)
+}
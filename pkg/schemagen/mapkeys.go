@@ -0,0 +1,101 @@
+package schemagen
+
+import (
+	"encoding"
+	"reflect"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// boxedJavaTypes are the Java classes of primitive types, usable as type
+// arguments.
+var boxedJavaTypes = map[string]string{
+	"boolean": "Boolean",
+	"bool":    "Boolean",
+	"byte":    "Byte",
+	"short":   "Short",
+	"int":     "Integer",
+	"long":    "Long",
+	"float":   "Float",
+	"double":  "Double",
+}
+
+// typeArgument returns javaType, boxed if it is primitive, for use as a
+// type argument such as the values of a java.util.Map.
+func typeArgument(javaType string) string {
+	if boxed, ok := boxedJavaTypes[javaType]; ok {
+		return boxed
+	}
+	return javaType
+}
+
+// mapKeyJavaType returns the Java type of the keys of the map type t: the
+// enum generated for registered enum keys, else the boxed integer type or
+// String. encoding/json marshals integer keys as decimal strings and the
+// other keys, including types implementing encoding.TextMarshaler, as
+// strings.
+func (g *schemaGenerator) mapKeyJavaType(t reflect.Type) string {
+	key := t.Key()
+	if _, ok := g.enum(key); ok {
+		return g.javaType(key)
+	}
+	if !key.Implements(textMarshalerType) && isInteger(key.Kind()) {
+		return typeArgument(g.kindJavaType(key, g.kinds[key.Kind()]))
+	}
+	return "String"
+}
+
+// mapKeyNames returns the constraint on the property names of the map type
+// t, if any: the enum values of its keys, or a pattern for integer keys.
+// Keys encoding/json cannot marshal are reported.
+func (g *schemaGenerator) mapKeyNames(t reflect.Type) *JSONPropertyDescriptor {
+	key := t.Key()
+	if e, ok := g.enum(key); ok {
+		return &JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Enum: enumValues(e.values),
+			},
+		}
+	}
+	switch {
+	case key.Kind() == reflect.String || key.Implements(textMarshalerType):
+		return nil
+	case isInteger(key.Kind()):
+		pattern := "^-?[0-9]+$"
+		if key.Kind() >= reflect.Uint && key.Kind() <= reflect.Uintptr {
+			pattern = "^[0-9]+$"
+		}
+		return &JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Pattern: pattern,
+			},
+		}
+	}
+	g.warnf("%s of type %s has keys of type %s, which encoding/json cannot marshal.", g.location(t), t, key)
+	return nil
+}
+
+func isInteger(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Uintptr
+}
+
+// patternKeys moves the key pattern of the map described by prop from its
+// propertyNames to its patternProperties, keeping its other key rules, and
+// reports whether it had one. See Options.PatternProperties.
+func patternKeys(prop *JSONPropertyDescriptor) bool {
+	if prop.JSONMapDescriptor == nil || prop.PropertyNames == nil || prop.PropertyNames.JSONDescriptor == nil || len(prop.PropertyNames.Pattern) == 0 {
+		return false
+	}
+	m := *prop.JSONMapDescriptor
+	names := *m.PropertyNames
+	desc := *names.JSONDescriptor
+	m.PatternProperties = map[string]JSONPropertyDescriptor{desc.Pattern: m.MapValueType}
+	desc.Pattern = ""
+	names.JSONDescriptor = &desc
+	m.PropertyNames = &names
+	if reflect.DeepEqual(names, JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{}}) {
+		m.PropertyNames = nil
+	}
+	prop.JSONMapDescriptor = &m
+	return true
+}
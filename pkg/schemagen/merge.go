@@ -0,0 +1,180 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MergePolicy selects which of several differing definitions of the same
+// name MergeSchemasWithPolicy keeps.
+type MergePolicy int
+
+const (
+	// FailOnConflict fails the merge, listing the conflicting definitions.
+	FailOnConflict MergePolicy = iota
+	// FirstWins keeps the definition of the first schema defining it.
+	FirstWins
+	// LastWins keeps the definition of the last schema defining it.
+	LastWins
+)
+
+// ParseMergePolicy parses the name of a merge policy: "fail", "first" or
+// "last". An empty name is FailOnConflict.
+func ParseMergePolicy(s string) (MergePolicy, error) {
+	switch s {
+	case "", "fail":
+		return FailOnConflict, nil
+	case "first":
+		return FirstWins, nil
+	case "last":
+		return LastWins, nil
+	}
+	return 0, fmt.Errorf("Unknown merge policy %q.", s)
+}
+
+// MergeSchemas merges independently generated schemas, such as the ones of
+// the Kubernetes, OpenShift and custom resource types, into one, failing
+// if two of them define the same name differently, see
+// MergeSchemasWithPolicy.
+func MergeSchemas(schemas ...*JSONSchema) (*JSONSchema, error) {
+	return MergeSchemasWithPolicy(FailOnConflict, schemas...)
+}
+
+// MergeSchemasWithPolicy returns the union of the definitions and of the
+// root properties of schemas, the id, $schema and other root keywords
+// being the ones of the first schema. Definitions of the same name are
+// identical if their shapes are, their descriptions and titles aside.
+// Differing ones are kept according to policy, each conflict resolved
+// being reported in the Warnings of the result. The schemas must share
+// their draft and must not have been mirrored into components yet.
+func MergeSchemasWithPolicy(policy MergePolicy, schemas ...*JSONSchema) (*JSONSchema, error) {
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("At least one schema is required.")
+	}
+	for _, s := range schemas {
+		if s.Draft != schemas[0].Draft {
+			return nil, fmt.Errorf("Schemas of different drafts cannot be merged.")
+		}
+		if s.Components != nil {
+			return nil, fmt.Errorf("Schemas with components cannot be merged, merge them before mirroring their definitions.")
+		}
+	}
+	merged := *schemas[0]
+	merged.Definitions = nil
+	merged.Warnings = nil
+	m := merger{policy: policy}
+	var properties map[string]JSONPropertyDescriptor
+	required := make(map[string]bool)
+	for i, s := range schemas {
+		merged.Warnings = append(merged.Warnings, s.Warnings...)
+		if len(s.Definitions) > 0 && merged.Definitions == nil {
+			merged.Definitions = make(map[string]JSONPropertyDescriptor)
+		}
+		if err := m.merge("definition", merged.Definitions, s.Definitions, i); err != nil {
+			return nil, err
+		}
+		if s.JSONObjectDescriptor == nil {
+			continue
+		}
+		if properties == nil {
+			properties = make(map[string]JSONPropertyDescriptor)
+		}
+		if err := m.merge("root property", properties, s.Properties, i); err != nil {
+			return nil, err
+		}
+		for _, name := range s.Required {
+			required[name] = true
+		}
+	}
+	if len(m.conflicts) > 0 {
+		return nil, fmt.Errorf("Conflicting schemas: %s.", strings.Join(m.conflicts, ", "))
+	}
+	merged.Warnings = append(merged.Warnings, m.warnings...)
+	if properties != nil {
+		obj := JSONObjectDescriptor{AdditionalProperties: true}
+		if merged.JSONObjectDescriptor != nil {
+			obj = *merged.JSONObjectDescriptor
+		}
+		obj.Properties = properties
+		obj.Required = nil
+		for name := range required {
+			obj.Required = append(obj.Required, name)
+		}
+		sort.Strings(obj.Required)
+		merged.JSONObjectDescriptor = &obj
+	}
+	return &merged, nil
+}
+
+// merger accumulates the conflicts met while merging schemas.
+type merger struct {
+	policy    MergePolicy
+	conflicts []string
+	warnings  []string
+	// origins records the index of the schema each kept descriptor comes
+	// from, keyed by kind and name.
+	origins map[string]int
+}
+
+// merge adds the descriptors of from, taken from schema index, to into.
+func (m *merger) merge(kind string, into, from map[string]JSONPropertyDescriptor, index int) error {
+	if m.origins == nil {
+		m.origins = make(map[string]int)
+	}
+	for _, name := range sortedPropertyNames(from) {
+		p := from[name]
+		key := kind + " " + name
+		kept, ok := into[name]
+		if !ok {
+			into[name] = p
+			m.origins[key] = index
+			continue
+		}
+		same, err := sameShape(kept, p)
+		if err != nil {
+			return err
+		}
+		if same {
+			continue
+		}
+		origin := m.origins[key]
+		switch m.policy {
+		case FirstWins:
+			m.warnings = append(m.warnings, fmt.Sprintf("The %s %s of schema %d differs from the one of schema %d, which is kept.", kind, name, index+1, origin+1))
+		case LastWins:
+			m.warnings = append(m.warnings, fmt.Sprintf("The %s %s of schema %d differs from the one of schema %d, which is replaced.", kind, name, index+1, origin+1))
+			into[name] = p
+			m.origins[key] = index
+		default:
+			m.conflicts = append(m.conflicts, fmt.Sprintf("%s %s (schemas %d and %d)", kind, name, origin+1, index+1))
+		}
+	}
+	return nil
+}
+
+// sameShape tells whether a and b describe the same values, ignoring their
+// descriptions and titles.
+func sameShape(a, b JSONPropertyDescriptor) (bool, error) {
+	ja, err := json.Marshal(mapDescriptor(a, withoutDocs))
+	if err != nil {
+		return false, err
+	}
+	jb, err := json.Marshal(mapDescriptor(b, withoutDocs))
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ja, jb), nil
+}
+
+func withoutDocs(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if p.JSONDescriptor != nil {
+		desc := *p.JSONDescriptor
+		desc.Description = ""
+		desc.Title = ""
+		p.JSONDescriptor = &desc
+	}
+	return p
+}
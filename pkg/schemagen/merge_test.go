@@ -0,0 +1,138 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mergeDescriptor(t, description string) JSONPropertyDescriptor {
+	return JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: t, Description: description}}
+}
+
+func mergeSchema(defs map[string]JSONPropertyDescriptor, props map[string]JSONPropertyDescriptor, required ...string) *JSONSchema {
+	s := &JSONSchema{Definitions: defs}
+	if props != nil {
+		s.JSONObjectDescriptor = &JSONObjectDescriptor{Properties: props, Required: required}
+	}
+	return s
+}
+
+func TestMergeSchemasPolicies(t *testing.T) {
+	first := mergeSchema(map[string]JSONPropertyDescriptor{
+		"Pod":    mergeDescriptor("object", "A pod."),
+		"Status": mergeDescriptor("string", ""),
+	}, map[string]JSONPropertyDescriptor{"pod": mergeDescriptor("object", "")}, "pod")
+	second := mergeSchema(map[string]JSONPropertyDescriptor{
+		"Pod":    mergeDescriptor("object", "Another description."),
+		"Status": mergeDescriptor("integer", ""),
+	}, map[string]JSONPropertyDescriptor{"pod": mergeDescriptor("object", ""), "node": mergeDescriptor("string", "")}, "node")
+	third := mergeSchema(map[string]JSONPropertyDescriptor{
+		"Status": mergeDescriptor("boolean", ""),
+	}, nil)
+
+	for _, test := range []struct {
+		name     string
+		policy   MergePolicy
+		schemas  []*JSONSchema
+		err      string
+		status   string
+		warnings int
+	}{
+		{"identical but for docs", FailOnConflict, []*JSONSchema{first, first}, "", "string", 0},
+		{"fail", FailOnConflict, []*JSONSchema{first, second}, "definition Status (schemas 1 and 2)", "", 0},
+		{"fail on three", FailOnConflict, []*JSONSchema{first, second, third}, "definition Status (schemas 1 and 3)", "", 0},
+		{"first wins", FirstWins, []*JSONSchema{first, second, third}, "", "string", 2},
+		{"last wins", LastWins, []*JSONSchema{first, second, third}, "", "boolean", 2},
+	} {
+		merged, err := MergeSchemasWithPolicy(test.policy, test.schemas...)
+		if len(test.err) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected an error about %s, got %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if status := merged.Definitions["Status"]; status.Type != test.status {
+			t.Errorf("%s: expected the %s Status, got %s", test.name, test.status, status.Type)
+		}
+		// Definitions differing in their docs only are the same, the
+		// first one being kept.
+		if pod := merged.Definitions["Pod"]; pod.Description != "A pod." {
+			t.Errorf("%s: unexpected Pod %+v", test.name, pod.JSONDescriptor)
+		}
+		if len(merged.Warnings) != test.warnings {
+			t.Errorf("%s: expected %d warnings, got %v", test.name, test.warnings, merged.Warnings)
+		}
+	}
+
+	merged, err := MergeSchemas(first, second)
+	if err == nil {
+		t.Fatalf("expected the conflict to fail the merge, got %+v", merged)
+	}
+	merged, err = MergeSchemasWithPolicy(FirstWins, first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(merged.Required, []string{"node", "pod"}) || len(merged.Properties) != 2 {
+		t.Errorf("unexpected root %+v", merged.JSONObjectDescriptor)
+	}
+}
+
+func TestMergeSchemasDocsInsensitive(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		a, b JSONPropertyDescriptor
+		same bool
+	}{
+		{"description", mergeDescriptor("string", "a"), mergeDescriptor("string", "b"), true},
+		{"title", JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: "string", Title: "A"}}, mergeDescriptor("string", ""), true},
+		{"nested description", JSONPropertyDescriptor{
+			JSONDescriptor:       &JSONDescriptor{Type: "object"},
+			JSONObjectDescriptor: &JSONObjectDescriptor{Properties: map[string]JSONPropertyDescriptor{"name": mergeDescriptor("string", "The name.")}},
+		}, JSONPropertyDescriptor{
+			JSONDescriptor:       &JSONDescriptor{Type: "object"},
+			JSONObjectDescriptor: &JSONObjectDescriptor{Properties: map[string]JSONPropertyDescriptor{"name": mergeDescriptor("string", "")}},
+		}, true},
+		{"type", mergeDescriptor("string", "a"), mergeDescriptor("integer", "a"), false},
+		{"format", JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: "integer", Format: "int32"}}, mergeDescriptor("integer", ""), false},
+	} {
+		same, err := sameShape(test.a, test.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if same != test.same {
+			t.Errorf("%s: expected sameShape to be %v", test.name, test.same)
+		}
+	}
+}
+
+func TestMergeSchemasErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		schemas []*JSONSchema
+		err     string
+	}{
+		{"none", nil, "At least one schema"},
+		{"drafts", []*JSONSchema{{}, {Draft: Draft2020}}, "different drafts"},
+		{"components", []*JSONSchema{{}, {Components: &OpenAPIComponents{}}}, "components"},
+	} {
+		if _, err := MergeSchemas(test.schemas...); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error about %s, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+func TestParseMergePolicy(t *testing.T) {
+	for s, want := range map[string]MergePolicy{"": FailOnConflict, "fail": FailOnConflict, "first": FirstWins, "last": LastWins} {
+		if policy, err := ParseMergePolicy(s); err != nil || policy != want {
+			t.Errorf("%q: expected %v, got %v, %v", s, want, policy, err)
+		}
+	}
+	if _, err := ParseMergePolicy("newest"); err == nil {
+		t.Error("expected an unknown policy to fail")
+	}
+}
@@ -0,0 +1,59 @@
+package schemagen
+
+import "github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+
+// The schema model lives in package schemamodel, which tools consuming
+// generated schemas can import without the generator. Its types are
+// aliased here so that generator users need a single import.
+type (
+	JSONSchema                 = schemamodel.JSONSchema
+	JSONPropertyDescriptor     = schemamodel.JSONPropertyDescriptor
+	JSONDescriptor             = schemamodel.JSONDescriptor
+	JSONObjectDescriptor       = schemamodel.JSONObjectDescriptor
+	JSONArrayDescriptor        = schemamodel.JSONArrayDescriptor
+	JSONReferenceDescriptor    = schemamodel.JSONReferenceDescriptor
+	JSONMapDescriptor          = schemamodel.JSONMapDescriptor
+	JSONCombinatorDescriptor   = schemamodel.JSONCombinatorDescriptor
+	JSONDefinitionsDescriptor  = schemamodel.JSONDefinitionsDescriptor
+	Discriminator              = schemamodel.Discriminator
+	JavaTypeDescriptor         = schemamodel.JavaTypeDescriptor
+	JavaAnnotationsDescriptor  = schemamodel.JavaAnnotationsDescriptor
+	JavaHintsDescriptor        = schemamodel.JavaHintsDescriptor
+	JavaNameDescriptor         = schemamodel.JavaNameDescriptor
+	FormatHintDescriptor       = schemamodel.FormatHintDescriptor
+	NullableDescriptor         = schemamodel.NullableDescriptor
+	EmbeddedResourceDescriptor = schemamodel.EmbeddedResourceDescriptor
+	AbstractDescriptor         = schemamodel.AbstractDescriptor
+	GroupVersionKind           = schemamodel.GroupVersionKind
+	GroupVersionKindDescriptor = schemamodel.GroupVersionKindDescriptor
+	GoPackageDescriptor        = schemamodel.GoPackageDescriptor
+	ProtobufDescriptor         = schemamodel.ProtobufDescriptor
+	DeprecatedValuesDescriptor = schemamodel.DeprecatedValuesDescriptor
+	DebugDescriptor            = schemamodel.DebugDescriptor
+	GenerationStamp            = schemamodel.GenerationStamp
+	OpenAPIComponents          = schemamodel.OpenAPIComponents
+	Draft                      = schemamodel.Draft
+)
+
+const (
+	Draft04   = schemamodel.Draft04
+	Draft07   = schemamodel.Draft07
+	Draft2020 = schemamodel.Draft2020
+)
+
+// ParseDraft parses the name of a draft, see schemamodel.ParseDraft.
+func ParseDraft(s string) (Draft, error) {
+	return schemamodel.ParseDraft(s)
+}
+
+// Canonicalize re-encodes a JSON document in canonical form, see
+// schemamodel.Canonicalize.
+func Canonicalize(data []byte) ([]byte, error) {
+	return schemamodel.Canonicalize(data)
+}
+
+// CompareJSON lists the differences between two JSON documents, see
+// schemamodel.CompareJSON.
+func CompareJSON(expected, actual []byte) ([]string, error) {
+	return schemamodel.CompareJSON(expected, actual)
+}
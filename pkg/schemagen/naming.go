@@ -0,0 +1,152 @@
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy selects how the definitions of the types of packages
+// without a PackageDescriptor are named.
+type NamingStrategy int
+
+const (
+	// PathNames names definitions after the full import path of their
+	// package, e.g. github_com_foo_bar_Baz.
+	PathNames NamingStrategy = iota
+	// ShortNames names definitions after their type alone, e.g. Baz, the
+	// types reached later under a name already given getting a numeric
+	// suffix, e.g. Baz2.
+	ShortNames
+	// LastSegmentsNames names definitions after the last two segments of
+	// the import path of their package, e.g. foo_bar_Baz.
+	LastSegmentsNames
+)
+
+// ParseNamingStrategy parses the name of a naming strategy: "path",
+// "short" or "lastSegments". An empty name is PathNames.
+func ParseNamingStrategy(s string) (NamingStrategy, error) {
+	switch s {
+	case "", "path":
+		return PathNames, nil
+	case "short":
+		return ShortNames, nil
+	case "lastSegments":
+		return LastSegmentsNames, nil
+	}
+	return 0, fmt.Errorf("Unknown naming strategy %q.", s)
+}
+
+// undescribedDefinitionName names the definition of the type declared as
+// name by the package with the given import path, which has no
+// PackageDescriptor, after Options.NameFunc or Options.Naming.
+func (g *schemaGenerator) undescribedDefinitionName(pkgPath, name string) string {
+	if len(pkgPath) == 0 {
+		return pathDefinitionName(pkgPath, name)
+	}
+	if g.opts.NameFunc != nil {
+		return g.opts.NameFunc(pkgPath, name)
+	}
+	switch g.opts.Naming {
+	case ShortNames:
+		return name
+	case LastSegmentsNames:
+		segments := strings.Split(pkgPath, "/")
+		if len(segments) > 2 {
+			segments = segments[len(segments)-2:]
+		}
+		return pathDefinitionName(strings.Join(segments, "/"), name)
+	}
+	return pathDefinitionName(pkgPath, name)
+}
+
+// PropertyNaming selects how the names of properties are derived from the
+// names of their Go fields.
+type PropertyNaming int
+
+const (
+	// GoPropertyNames keeps the Go names of the fields without a name in
+	// their json tag, like encoding/json does.
+	GoPropertyNames PropertyNaming = iota
+	// CamelCaseProperties names them in camelCase, e.g. podIP.
+	CamelCaseProperties
+	// SnakeCaseProperties names them in snake_case, e.g. pod_ip.
+	SnakeCaseProperties
+	// KebabCaseProperties names them in kebab-case, e.g. pod-ip.
+	KebabCaseProperties
+)
+
+// ParsePropertyNaming parses the name of a property naming convention:
+// "go", "camelCase", "snake_case" or "kebab-case". An empty name is
+// GoPropertyNames.
+func ParsePropertyNaming(s string) (PropertyNaming, error) {
+	switch s {
+	case "", "go":
+		return GoPropertyNames, nil
+	case "camelCase":
+		return CamelCaseProperties, nil
+	case "snake_case":
+		return SnakeCaseProperties, nil
+	case "kebab-case":
+		return KebabCaseProperties, nil
+	}
+	return 0, fmt.Errorf("Unknown property naming %q.", s)
+}
+
+// convert returns name following the convention. The words of name are
+// delimited by underscores, dashes and changes of case, acronyms such as
+// HTTP being one word, which camelCase keeps upper case but for the first
+// word, e.g. HTTPServerIP becomes httpServerIP, http_server_ip or
+// http-server-ip.
+func (n PropertyNaming) convert(name string) string {
+	if n == GoPropertyNames {
+		return name
+	}
+	words := nameWords(name)
+	if len(words) == 0 {
+		return name
+	}
+	switch n {
+	case SnakeCaseProperties:
+		return strings.ToLower(strings.Join(words, "_"))
+	case KebabCaseProperties:
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else if w != strings.ToUpper(w) {
+			r := []rune(w)
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// nameWords splits name into words, see PropertyNaming.convert.
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-':
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
@@ -0,0 +1,110 @@
+package schemagen
+
+import "github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+
+// nestDefinitions moves every definition referenced by exactly one other
+// definition, and not by the root, under the definitions of that parent,
+// e.g. #/definitions/os_Pod/definitions/os_PodSpec. This keeps the top
+// level definitions small and signals ownership. It returns the top level
+// definition now holding each nested definition.
+func nestDefinitions(s *JSONSchema) map[string]string {
+	parents := make(map[string]map[string]bool)
+	for name, def := range s.Definitions {
+		for _, dep := range referencedDefinitions(def) {
+			if parents[dep] == nil {
+				parents[dep] = make(map[string]bool)
+			}
+			parents[dep][name] = true
+		}
+	}
+	fromRoot := map[string]bool{}
+	if s.JSONObjectDescriptor != nil {
+		root := JSONPropertyDescriptor{JSONObjectDescriptor: s.JSONObjectDescriptor}
+		for _, dep := range referencedDefinitions(root) {
+			fromRoot[dep] = true
+		}
+	}
+
+	owners := make(map[string]string)
+	for name := range s.Definitions {
+		if fromRoot[name] || len(parents[name]) != 1 {
+			continue
+		}
+		for parent := range parents[name] {
+			if _, ok := s.Definitions[parent]; ok && parent != name {
+				owners[name] = parent
+			}
+		}
+	}
+	// Definitions owning each other in a cycle stay at the top level.
+	for name := range owners {
+		seen := map[string]bool{name: true}
+		for owner, ok := owners[name]; ok; owner, ok = owners[owner] {
+			if seen[owner] {
+				delete(owners, name)
+				break
+			}
+			seen[owner] = true
+		}
+	}
+
+	var path func(name string) string
+	path = func(name string) string {
+		if owner, ok := owners[name]; ok {
+			return path(owner) + "/definitions/" + name
+		}
+		return definitionsPrefix + name
+	}
+	relocate := func(ref string) string {
+		if name, ok := schemamodel.LocalDefinition(ref); ok {
+			if _, ok := s.Definitions[name]; ok {
+				return path(name)
+			}
+		}
+		return ref
+	}
+	mapSchema(s, func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		if p.JSONReferenceDescriptor != nil {
+			p.JSONReferenceDescriptor = &JSONReferenceDescriptor{
+				Reference: relocate(p.Reference),
+			}
+		}
+		return p
+	})
+
+	children := make(map[string][]string)
+	for name, owner := range owners {
+		children[owner] = append(children[owner], name)
+	}
+	var nest func(name string) JSONPropertyDescriptor
+	nest = func(name string) JSONPropertyDescriptor {
+		def := s.Definitions[name]
+		if len(children[name]) > 0 {
+			nested := make(map[string]JSONPropertyDescriptor)
+			for _, child := range children[name] {
+				nested[child] = nest(child)
+			}
+			def.JSONDefinitionsDescriptor = &JSONDefinitionsDescriptor{
+				Definitions: nested,
+			}
+		}
+		return def
+	}
+	top := make(map[string]JSONPropertyDescriptor)
+	for name := range s.Definitions {
+		if _, ok := owners[name]; !ok {
+			top[name] = nest(name)
+		}
+	}
+	s.Definitions = top
+
+	roots := make(map[string]string)
+	for name := range owners {
+		root := owners[name]
+		for owner, ok := owners[root]; ok; owner, ok = owners[root] {
+			root = owner
+		}
+		roots[name] = root
+	}
+	return roots
+}
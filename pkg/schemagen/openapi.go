@@ -0,0 +1,141 @@
+package schemagen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// OpenAPIVersion selects the flavor of the documents built by
+// GenerateOpenAPI.
+type OpenAPIVersion int
+
+const (
+	// OpenAPI3 holds the schemas under components.schemas.
+	OpenAPI3 OpenAPIVersion = iota
+	// Swagger2 holds the schemas under definitions.
+	Swagger2
+)
+
+// ParseOpenAPIVersion parses an OpenAPI version: "3", "3.0", "2" or "2.0".
+func ParseOpenAPIVersion(s string) (OpenAPIVersion, error) {
+	switch s {
+	case "3", "3.0":
+		return OpenAPI3, nil
+	case "2", "2.0":
+		return Swagger2, nil
+	}
+	return 0, fmt.Errorf("Unknown OpenAPI version %q.", s)
+}
+
+// OpenAPIDocument is an OpenAPI document without operations, holding the
+// schemas of a generation, for API servers to serve.
+type OpenAPIDocument struct {
+	OpenAPI     string                            `json:"openapi,omitempty"`
+	Swagger     string                            `json:"swagger,omitempty"`
+	Info        OpenAPIInfo                       `json:"info"`
+	Paths       map[string]interface{}            `json:"paths"`
+	Components  *OpenAPIComponents                `json:"components,omitempty"`
+	Definitions map[string]JSONPropertyDescriptor `json:"definitions,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// GenerateOpenAPI walks t like GenerateSchemaWithOptions and returns an
+// OpenAPI document holding its definitions, plus the root struct under its
+// own definition name, with references in the style of the version, e.g.
+// #/components/schemas/os_route_Route. Java hints are left out since
+// OpenAPI schemas only allow x- prefixed extensions.
+func GenerateOpenAPI(t reflect.Type, opts Options, version OpenAPIVersion) (*OpenAPIDocument, error) {
+	g := newSchemaGenerator(opts)
+	s, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "#/components/schemas/"
+	if version == Swagger2 {
+		prefix = definitionsPrefix
+	}
+	schemas := make(map[string]JSONPropertyDescriptor, len(s.Definitions)+1)
+	for name, def := range s.Definitions {
+		schemas[name] = openAPISchema(def, prefix, version)
+	}
+	root := JSONPropertyDescriptor{
+		JSONDescriptor:       &s.JSONDescriptor,
+		JSONObjectDescriptor: s.JSONObjectDescriptor,
+	}
+	if len(s.GroupVersionKinds) > 0 {
+		root.GroupVersionKindDescriptor = &GroupVersionKindDescriptor{
+			GroupVersionKinds: s.GroupVersionKinds,
+		}
+	}
+	schemas[g.qualifiedName(t)] = openAPISchema(root, prefix, version)
+
+	doc := &OpenAPIDocument{
+		Info:  OpenAPIInfo{Title: t.Name(), Version: "1.0"},
+		Paths: map[string]interface{}{},
+	}
+	if version == Swagger2 {
+		doc.Swagger = "2.0"
+		doc.Definitions = schemas
+	} else {
+		doc.OpenAPI = "3.0.3"
+		doc.Components = &OpenAPIComponents{Schemas: schemas}
+	}
+	return doc, nil
+}
+
+// openAPISchema returns a copy of p without Java hints, in which local
+// definition references start with prefix and types allowing null are
+// marked with the nullable keyword of version.
+func openAPISchema(p JSONPropertyDescriptor, prefix string, version OpenAPIVersion) JSONPropertyDescriptor {
+	return mapDescriptor(p, func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		if ref, ok := nullableRefTarget(p); ok {
+			// OpenAPI knows no null type, nullable references are
+			// wrapped in an allOf instead.
+			p.JSONCombinatorDescriptor = &JSONCombinatorDescriptor{
+				AllOf: []JSONPropertyDescriptor{ref},
+			}
+			p.NullableDescriptor = openAPINullable(version)
+		}
+		if p.JSONDescriptor != nil && p.AllowNull {
+			desc := *p.JSONDescriptor
+			desc.AllowNull = false
+			p.JSONDescriptor = &desc
+			p.NullableDescriptor = openAPINullable(version)
+		}
+		p.JavaTypeDescriptor = nil
+		p.JavaAnnotationsDescriptor = nil
+		p.JavaHintsDescriptor = nil
+		p.JavaNameDescriptor = nil
+		if p.JSONReferenceDescriptor != nil && strings.HasPrefix(p.Reference, definitionsPrefix) {
+			p.JSONReferenceDescriptor = &JSONReferenceDescriptor{
+				Reference: prefix + strings.TrimPrefix(p.Reference, definitionsPrefix),
+			}
+		}
+		return p
+	})
+}
+
+// nullableRefTarget returns the reference of a property built by
+// nullableRef.
+func nullableRefTarget(p JSONPropertyDescriptor) (JSONPropertyDescriptor, bool) {
+	if p.JSONCombinatorDescriptor == nil || len(p.OneOf) != 2 || len(p.AllOf) > 0 || len(p.AnyOf) > 0 {
+		return JSONPropertyDescriptor{}, false
+	}
+	null, ref := p.OneOf[0], p.OneOf[1]
+	if null.JSONDescriptor == nil || null.Type != "null" || ref.JSONReferenceDescriptor == nil {
+		return JSONPropertyDescriptor{}, false
+	}
+	return ref, true
+}
+
+func openAPINullable(version OpenAPIVersion) *NullableDescriptor {
+	if version == Swagger2 {
+		return &NullableDescriptor{XNullable: true}
+	}
+	return &NullableDescriptor{Nullable: true}
+}
@@ -0,0 +1,38 @@
+package schemagen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// applyDefinitionOverrides replaces generated definitions with the
+// handwritten ones from the options. Each override must replace an existing
+// definition and may only refer to definitions present in the schema.
+func (g *schemaGenerator) applyDefinitionOverrides(s *JSONSchema) error {
+	names := make([]string, 0, len(g.opts.DefinitionOverrides))
+	for name := range g.opts.DefinitionOverrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := s.Definitions[name]; !ok {
+			return fmt.Errorf("Override for unknown definition %s.", name)
+		}
+		override := g.opts.DefinitionOverrides[name]
+		var missing []string
+		rewriteRefs(override, func(ref string) string {
+			if strings.HasPrefix(ref, definitionsPrefix) {
+				if _, ok := s.Definitions[strings.TrimPrefix(ref, definitionsPrefix)]; !ok {
+					missing = append(missing, ref)
+				}
+			}
+			return ref
+		})
+		if len(missing) > 0 {
+			return fmt.Errorf("Override for definition %s refers to unknown definitions: %s.", name, strings.Join(missing, ", "))
+		}
+		s.Definitions[name] = override
+	}
+	return nil
+}
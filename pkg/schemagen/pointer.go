@@ -0,0 +1,169 @@
+package schemagen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// PropertyOverride tweaks the descriptor found at a JSON pointer of the
+// schema, see Options.Overrides.
+type PropertyOverride struct {
+	Description string `yaml:"description"`
+	Pattern     string `yaml:"pattern"`
+	// Required, if set, adds the property to, or removes it from, the
+	// required keyword of its object.
+	Required *bool `yaml:"required"`
+}
+
+// applyOverrides applies the overrides of the options to s, in pointer
+// order. Every pointer must address a descriptor of the schema.
+func (g *schemaGenerator) applyOverrides(s *JSONSchema) error {
+	pointers := make([]string, 0, len(g.opts.Overrides))
+	for pointer := range g.opts.Overrides {
+		pointers = append(pointers, pointer)
+	}
+	sort.Strings(pointers)
+	for _, pointer := range pointers {
+		o := g.opts.Overrides[pointer]
+		tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+		for i, t := range tokens {
+			tokens[i] = schemamodel.UnescapePointer(t)
+		}
+		var err error
+		switch {
+		case len(tokens) >= 2 && tokens[0] == "definitions":
+			def, ok := s.Definitions[tokens[1]]
+			if !ok {
+				return fmt.Errorf("Override for unknown pointer %s.", pointer)
+			}
+			if def, err = overrideAt(def, tokens[2:], o); err == nil {
+				s.Definitions[tokens[1]] = def
+			}
+		default:
+			root := JSONPropertyDescriptor{JSONObjectDescriptor: s.JSONObjectDescriptor}
+			if root, err = overrideAt(root, tokens, o); err == nil {
+				s.JSONObjectDescriptor = root.JSONObjectDescriptor
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("Override for unknown pointer %s.", pointer)
+		}
+	}
+	return nil
+}
+
+// overrideAt returns a copy of p in which the override has been applied to
+// the descriptor addressed by the pointer tokens, relative to p.
+func overrideAt(p JSONPropertyDescriptor, tokens []string, o PropertyOverride) (JSONPropertyDescriptor, error) {
+	if len(tokens) == 0 {
+		return applyOverride(p, o), nil
+	}
+	missing := fmt.Errorf("no %s", tokens[0])
+	switch tokens[0] {
+	case "properties":
+		if len(tokens) < 2 || p.JSONObjectDescriptor == nil {
+			return p, missing
+		}
+		name := tokens[1]
+		prop, ok := p.Properties[name]
+		if !ok {
+			return p, missing
+		}
+		prop, err := overrideAt(prop, tokens[2:], o)
+		if err != nil {
+			return p, err
+		}
+		obj := *p.JSONObjectDescriptor
+		obj.Properties = make(map[string]JSONPropertyDescriptor, len(p.Properties))
+		for k, v := range p.Properties {
+			obj.Properties[k] = v
+		}
+		obj.Properties[name] = prop
+		if len(tokens) == 2 && o.Required != nil {
+			obj.Required = setRequired(obj.Required, name, *o.Required)
+		}
+		p.JSONObjectDescriptor = &obj
+		return p, nil
+	case "items":
+		if p.JSONArrayDescriptor == nil {
+			return p, missing
+		}
+		items, err := overrideAt(p.Items, tokens[1:], o)
+		if err != nil {
+			return p, err
+		}
+		array := *p.JSONArrayDescriptor
+		array.Items = items
+		p.JSONArrayDescriptor = &array
+		return p, nil
+	case "additionalProperty", "additionalProperties":
+		if p.JSONMapDescriptor == nil {
+			return p, missing
+		}
+		value, err := overrideAt(p.MapValueType, tokens[1:], o)
+		if err != nil {
+			return p, err
+		}
+		m := *p.JSONMapDescriptor
+		m.MapValueType = value
+		p.JSONMapDescriptor = &m
+		return p, nil
+	case "allOf", "anyOf", "oneOf":
+		if len(tokens) < 2 || p.JSONCombinatorDescriptor == nil {
+			return p, missing
+		}
+		c := *p.JSONCombinatorDescriptor
+		alts := map[string]*[]JSONPropertyDescriptor{"allOf": &c.AllOf, "anyOf": &c.AnyOf, "oneOf": &c.OneOf}[tokens[0]]
+		i, err := strconv.Atoi(tokens[1])
+		if err != nil || i < 0 || i >= len(*alts) {
+			return p, missing
+		}
+		alt, err := overrideAt((*alts)[i], tokens[2:], o)
+		if err != nil {
+			return p, err
+		}
+		*alts = append([]JSONPropertyDescriptor{}, *alts...)
+		(*alts)[i] = alt
+		p.JSONCombinatorDescriptor = &c
+		return p, nil
+	}
+	return p, missing
+}
+
+func applyOverride(p JSONPropertyDescriptor, o PropertyOverride) JSONPropertyDescriptor {
+	if len(o.Description) == 0 && len(o.Pattern) == 0 {
+		return p
+	}
+	desc := JSONDescriptor{}
+	if p.JSONDescriptor != nil {
+		desc = *p.JSONDescriptor
+	}
+	if len(o.Description) > 0 {
+		desc.Description = o.Description
+	}
+	if len(o.Pattern) > 0 {
+		desc.Pattern = o.Pattern
+	}
+	p.JSONDescriptor = &desc
+	return p
+}
+
+// setRequired returns a sorted copy of required with name added or
+// removed.
+func setRequired(required []string, name string, add bool) []string {
+	var result []string
+	for _, r := range required {
+		if r != name {
+			result = append(result, r)
+		}
+	}
+	if add {
+		result = append(result, name)
+		sort.Strings(result)
+	}
+	return result
+}
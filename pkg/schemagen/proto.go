@@ -0,0 +1,303 @@
+package schemagen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// ProtoNumbers records the field numbers ProtoEmitter gave to the
+// properties of each message, keyed by message then property name. It is
+// committed along the .proto file so that numbers stay stable across
+// regenerations. The numbers of removed properties stay recorded and are
+// reserved, so that they are never reused.
+type ProtoNumbers struct {
+	Messages map[string]map[string]int `json:"messages"`
+}
+
+// LoadProtoNumbers reads the numbering file at path. A missing file yields
+// an empty numbering, in which every field gets a new number.
+func LoadProtoNumbers(path string) (*ProtoNumbers, error) {
+	numbers := &ProtoNumbers{Messages: make(map[string]map[string]int)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return numbers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, numbers); err != nil {
+		return nil, fmt.Errorf("Unable to parse numbering file %s: %v", path, err)
+	}
+	if numbers.Messages == nil {
+		numbers.Messages = make(map[string]map[string]int)
+	}
+	return numbers, nil
+}
+
+// ProtoEmitter renders the definitions of a schema as a proto3 file: a
+// message for each object definition, repeated fields for arrays and map
+// fields for maps. Values without a protobuf equivalent, such as free-form
+// objects or unions, are google.protobuf.Value or Struct fields. Fields
+// keep their JSON name through the json_name option.
+type ProtoEmitter struct {
+	// Package is the package of the file, left out if empty.
+	Package string
+	// Root names the message of the root object, which is left out if it
+	// is empty.
+	Root string
+	// Numbers holds the field numbers given by previous runs and records
+	// the ones given to new fields. New fields of a message are numbered
+	// after the highest number it ever used, or after their protobuf
+	// struct tag with Options.ProtoFields. Fields are numbered afresh if
+	// it is nil.
+	Numbers *ProtoNumbers
+}
+
+// protoReservedFirst and protoReservedLast bound the field numbers
+// reserved by protobuf.
+const (
+	protoReservedFirst = 19000
+	protoReservedLast  = 19999
+)
+
+// protoIdentifier returns name with the characters protobuf identifiers
+// cannot hold replaced by underscores.
+func protoIdentifier(name string) string {
+	id := []rune(name)
+	for i, r := range id {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			id[i] = '_'
+		}
+	}
+	return string(id)
+}
+
+// protoFieldName returns the snake case field name of the property name,
+// e.g. pod_ip for podIP.
+func protoFieldName(name string) string {
+	words := camelWords(name)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return protoIdentifier(strings.Join(words, "_"))
+}
+
+// isMessage tells whether the definition p is emitted as a message.
+func isMessage(p JSONPropertyDescriptor) bool {
+	return p.JSONObjectDescriptor != nil && p.JSONMapDescriptor == nil && len(p.Properties) > 0
+}
+
+// protoTypes resolves the protobuf types of the descriptors of a schema.
+type protoTypes struct {
+	schema *JSONSchema
+	// wellKnown is set when a google.protobuf type is used.
+	wellKnown bool
+	// resolving holds the definitions being resolved, to stop at
+	// references to themselves.
+	resolving map[string]bool
+}
+
+// fieldType returns the type of a field holding the values of p, with the
+// repeated label for arrays.
+func (r *protoTypes) fieldType(p JSONPropertyDescriptor) string {
+	if items, ok := r.arrayItems(p); ok {
+		if _, nested := r.arrayItems(items); nested {
+			return "repeated " + r.value()
+		}
+		if t := r.singular(items); !strings.HasPrefix(t, "map<") {
+			return "repeated " + t
+		}
+		return "repeated " + r.structValue()
+	}
+	return r.singular(p)
+}
+
+// arrayItems returns the items of p if it is an array, possibly through a
+// reference to an array definition.
+func (r *protoTypes) arrayItems(p JSONPropertyDescriptor) (JSONPropertyDescriptor, bool) {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		def, defined := r.schema.Definitions[name]
+		if !ok || !defined || isMessage(def) || r.resolving[name] {
+			return JSONPropertyDescriptor{}, false
+		}
+		r.resolving[name] = true
+		defer delete(r.resolving, name)
+		return r.arrayItems(def)
+	}
+	if p.JSONArrayDescriptor != nil {
+		return p.Items, true
+	}
+	return JSONPropertyDescriptor{}, false
+}
+
+// singular returns the type of a single value of p, which is not an array.
+func (r *protoTypes) singular(p JSONPropertyDescriptor) string {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		def, defined := r.schema.Definitions[name]
+		if !ok || !defined {
+			return r.value()
+		}
+		if isMessage(def) {
+			return protoIdentifier(name)
+		}
+		if r.resolving[name] {
+			return r.value()
+		}
+		r.resolving[name] = true
+		defer delete(r.resolving, name)
+		return r.singular(def)
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		if len(p.AllOf) > 0 {
+			return r.singular(p.AllOf[0])
+		}
+		return r.value()
+	}
+	if p.JSONDescriptor == nil {
+		return r.value()
+	}
+	switch p.Type {
+	case "string":
+		if p.Format == "byte" {
+			return "bytes"
+		}
+		return "string"
+	case "integer":
+		if p.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "double"
+	case "boolean":
+		return "bool"
+	case "object":
+		if p.JSONMapDescriptor != nil {
+			if _, ok := r.arrayItems(p.MapValueType); !ok {
+				if v := r.singular(p.MapValueType); !strings.HasPrefix(v, "map<") {
+					return "map<string, " + v + ">"
+				}
+			}
+			return "map<string, " + r.value() + ">"
+		}
+		return r.structValue()
+	}
+	return r.value()
+}
+
+func (r *protoTypes) value() string {
+	r.wellKnown = true
+	return "google.protobuf.Value"
+}
+
+func (r *protoTypes) structValue() string {
+	r.wellKnown = true
+	return "google.protobuf.Struct"
+}
+
+// number returns the number of the field name of message, recording a new
+// one after the highest number of the message if it has none yet.
+func (n *ProtoNumbers) number(message, name string, tagged int) int {
+	fields, ok := n.Messages[message]
+	if !ok {
+		fields = make(map[string]int)
+		n.Messages[message] = fields
+	}
+	if number, ok := fields[name]; ok {
+		return number
+	}
+	used := make(map[int]bool, len(fields))
+	next := 1
+	for _, number := range fields {
+		used[number] = true
+		if number >= next {
+			next = number + 1
+		}
+	}
+	number := next
+	if tagged > 0 && !used[tagged] && (tagged < protoReservedFirst || tagged > protoReservedLast) {
+		number = tagged
+	} else if number >= protoReservedFirst && number <= protoReservedLast {
+		number = protoReservedLast + 1
+	}
+	fields[name] = number
+	return number
+}
+
+// writeMessage writes the message describing the properties of obj,
+// numbering its fields.
+func (e ProtoEmitter) writeMessage(w io.Writer, message string, obj *JSONObjectDescriptor, numbers *ProtoNumbers, types *protoTypes) {
+	props := sortedPropertyNames(obj.Properties)
+	// Number the tagged fields first, so that they get their tag.
+	fields := make(map[string]int, len(props))
+	for _, p := range props {
+		if proto := obj.Properties[p].ProtobufDescriptor; proto != nil {
+			fields[p] = numbers.number(message, p, proto.FieldNumber)
+		}
+	}
+	for _, p := range props {
+		if _, ok := fields[p]; !ok {
+			fields[p] = numbers.number(message, p, 0)
+		}
+	}
+	fmt.Fprintf(w, "\nmessage %s {\n", message)
+	var reserved []int
+	for p, number := range numbers.Messages[message] {
+		if _, ok := obj.Properties[p]; !ok {
+			reserved = append(reserved, number)
+		}
+	}
+	if len(reserved) > 0 {
+		sort.Ints(reserved)
+		values := make([]string, len(reserved))
+		for i, number := range reserved {
+			values[i] = fmt.Sprint(number)
+		}
+		fmt.Fprintf(w, "  reserved %s;\n", strings.Join(values, ", "))
+	}
+	for _, p := range props {
+		fmt.Fprintf(w, "  %s %s = %d [json_name = %q];\n", types.fieldType(obj.Properties[p]), protoFieldName(p), fields[p], p)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func (e ProtoEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	numbers := e.Numbers
+	if numbers == nil {
+		numbers = &ProtoNumbers{Messages: make(map[string]map[string]int)}
+	}
+	types := &protoTypes{schema: schema, resolving: map[string]bool{}}
+	var body bytes.Buffer
+	if len(e.Root) > 0 && schema.JSONObjectDescriptor != nil {
+		e.writeMessage(&body, e.Root, schema.JSONObjectDescriptor, numbers, types)
+	}
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		if def := schema.Definitions[name]; isMessage(def) {
+			e.writeMessage(&body, protoIdentifier(name), def.JSONObjectDescriptor, numbers, types)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "// Code generated by schemagen. DO NOT EDIT.")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, `syntax = "proto3";`)
+	if len(e.Package) > 0 {
+		fmt.Fprintf(out, "\npackage %s;\n", e.Package)
+	}
+	if types.wellKnown {
+		fmt.Fprintf(out, "\nimport %q;\n", "google/protobuf/struct.proto")
+	}
+	out.Write(body.Bytes())
+	return out.Flush()
+}
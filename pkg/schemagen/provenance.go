@@ -0,0 +1,124 @@
+package schemagen
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/v1/yaml"
+)
+
+// Version is the version of the generator recorded in provenance lock
+// files. It changes with every release that may generate different schemas
+// from the same inputs.
+const Version = "0.1.0"
+
+// Provenance records what a schema is generated from: the version of the
+// generator, a hash of the configuration and a hash of every named type
+// reachable from the roots, covering its structure, tags, doc comments and
+// constants. It is written to a lock file committed along the published
+// schemas, so that release engineering can tell which inputs produced them
+// and refuse to regenerate them from unrecorded ones.
+type Provenance struct {
+	GeneratorVersion string `json:"generatorVersion"`
+	OptionsHash      string `json:"optionsHash"`
+	// Types maps the types, as "<package path>.<name>", to their hashes.
+	Types map[string]string `json:"types"`
+}
+
+// NewProvenance records the provenance of the schema of roots generated
+// with cfg.
+func NewProvenance(cfg *Config, roots ...reflect.Type) (*Provenance, error) {
+	config, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := NewTypeSnapshot(roots...)
+	if err != nil {
+		return nil, err
+	}
+	p := &Provenance{
+		GeneratorVersion: Version,
+		OptionsHash:      provenanceHash(config),
+		Types:            make(map[string]string),
+	}
+	for pkgPath, pkg := range snapshot.Packages {
+		for name, t := range pkg.Types {
+			input := struct {
+				Type      *SnapshotNamed    `json:"type"`
+				Doc       string            `json:"doc,omitempty"`
+				FieldDocs map[string]string `json:"fieldDocs,omitempty"`
+			}{Type: t, Doc: pkg.Docs[name]}
+			for field, doc := range pkg.FieldDocs {
+				if strings.HasPrefix(field, name+".") {
+					if input.FieldDocs == nil {
+						input.FieldDocs = make(map[string]string)
+					}
+					input.FieldDocs[field] = doc
+				}
+			}
+			b, err := json.Marshal(input)
+			if err != nil {
+				return nil, err
+			}
+			p.Types[pkgPath+"."+name] = provenanceHash(b)
+		}
+	}
+	return p, nil
+}
+
+func provenanceHash(b []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(b))
+}
+
+// LoadProvenance reads the provenance lock file at path. A missing file
+// yields an empty provenance, which records no input.
+func LoadProvenance(path string) (*Provenance, error) {
+	p := &Provenance{Types: make(map[string]string)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("Unable to parse provenance lock file %s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Changes lists how current differs from the recorded provenance p: a
+// different generator version or configuration, and the types added,
+// removed or changed since.
+func (p *Provenance) Changes(current *Provenance) []string {
+	var changes []string
+	if p.GeneratorVersion != current.GeneratorVersion {
+		changes = append(changes, fmt.Sprintf("generator version %q is now %q", p.GeneratorVersion, current.GeneratorVersion))
+	}
+	if p.OptionsHash != current.OptionsHash {
+		changes = append(changes, "options changed")
+	}
+	var types []string
+	for name, hash := range current.Types {
+		recorded, ok := p.Types[name]
+		switch {
+		case !ok:
+			types = append(types, fmt.Sprintf("type %s was added", name))
+		case recorded != hash:
+			types = append(types, fmt.Sprintf("type %s changed", name))
+		}
+	}
+	for name := range p.Types {
+		if _, ok := current.Types[name]; !ok {
+			types = append(types, fmt.Sprintf("type %s was removed", name))
+		}
+	}
+	sort.Strings(types)
+	return append(changes, types...)
+}
@@ -0,0 +1,57 @@
+package schemagen
+
+import "reflect"
+
+// elemType strips pointers and container types from t, returning the type
+// of the values ultimately held by it. Container types holding themselves,
+// such as type Tree map[string]Tree, are returned as is.
+func elemType(t reflect.Type) reflect.Type {
+	seen := map[reflect.Type]bool{}
+	for !seen[t] {
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+	return t
+}
+
+// prunedTypes walks the types reachable from t like the generator, through
+// the type map and the fields it describes, and returns the struct types of
+// fields tagged schemagen:"prune" that are not reached through any other
+// field. The walk does not follow pruned fields, and the generator never
+// expands the returned types, so the subtrees they drag in are not visited
+// at all. A type pruned by one field but used by another is described as
+// usual.
+func (g *schemaGenerator) prunedTypes(t reflect.Type) map[reflect.Type]bool {
+	pruned := map[reflect.Type]bool{}
+	saved := g.pruned
+	g.pruned = nil
+	walked := g.walkReachable(t, func(reflect.Type) {}, func(ft reflect.Type) {
+		if ft.Kind() == reflect.Struct {
+			pruned[ft] = true
+		}
+	})
+	g.pruned = saved
+	for pt := range pruned {
+		if walked[pt] {
+			delete(pruned, pt)
+		}
+	}
+	return pruned
+}
+
+// prunedDescriptor describes a value of a pruned type as a free-form object.
+func prunedDescriptor() JSONPropertyDescriptor {
+	return JSONPropertyDescriptor{
+		JSONDescriptor: &JSONDescriptor{
+			Type: "object",
+		},
+		JSONObjectDescriptor: &JSONObjectDescriptor{
+			AdditionalProperties: true,
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type pruneLeaf struct {
+	Value string `json:"value"`
+}
+
+type pruneHuge struct {
+	Leaf pruneLeaf `json:"leaf"`
+}
+
+type pruneShared struct {
+	Name string `json:"name"`
+}
+
+type pruneRoot struct {
+	Huge     *pruneHuge    `json:"huge" schemagen:"prune"`
+	Pruned   pruneShared   `json:"pruned" schemagen:"prune"`
+	Shared   pruneShared   `json:"shared"`
+	Elements []pruneShared `json:"elements"`
+}
+
+func TestPruneSubtree(t *testing.T) {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(pruneRoot{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"huge", "pruned"} {
+		if _, ok := schema.Properties[name]; ok {
+			t.Errorf("pruned field %s is described", name)
+		}
+	}
+	for _, typeName := range []string{"pruneHuge", "pruneLeaf"} {
+		if names := FindDefinitions(schema, typeName); len(names) > 0 {
+			t.Errorf("pruned type %s is defined as %v", typeName, names)
+		}
+	}
+	// The type of a pruned field used by another field is described as
+	// usual, rather than as a free-form object.
+	if p := property(t, schema, "pruneShared", "name"); p.JSONDescriptor == nil || p.Type != "string" {
+		t.Errorf("unexpected name of pruneShared: %#v", p)
+	}
+	if len(schema.Warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", schema.Warnings)
+	}
+}
+
+type pruneMapped struct {
+	Name string `json:"name"`
+}
+
+type pruneOriginal struct {
+	Name string `json:"name"`
+}
+
+type pruneFilteredRoot struct {
+	Pruned   pruneShared `json:"pruned" schemagen:"prune"`
+	Ignored  pruneShared `json:"-"`
+	internal pruneShared
+	Mapped   pruneMapped   `json:"mapped" schemagen:"prune"`
+	Original pruneOriginal `json:"original"`
+}
+
+// TestPruneReachability checks that pruned types are looked up among the
+// fields the generator describes, through the type map.
+func TestPruneReachability(t *testing.T) {
+	opts := Options{TypeMap: map[reflect.Type]reflect.Type{
+		reflect.TypeOf(pruneOriginal{}): reflect.TypeOf(pruneMapped{}),
+	}}
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(pruneFilteredRoot{}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields left out by the generator do not keep a type from being
+	// pruned.
+	pruned := newSchemaGenerator(opts).prunedTypes(reflect.TypeOf(pruneFilteredRoot{}))
+	if !pruned[reflect.TypeOf(pruneShared{})] || pruned[reflect.TypeOf(pruneMapped{})] {
+		t.Errorf("unexpected pruned types %v", pruned)
+	}
+	// A field mapped to a pruned type uses it.
+	if p := property(t, schema, "pruneMapped", "name"); p.JSONDescriptor == nil || p.Type != "string" {
+		t.Errorf("unexpected name of pruneMapped: %#v", p)
+	}
+	if len(schema.Warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", schema.Warnings)
+	}
+}
@@ -0,0 +1,332 @@
+package schemagen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"regexp/syntax"
+	"time"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// maxRandomDepth is the depth of nested objects below which random
+// instances only hold required properties, so that recursive types end.
+const maxRandomDepth = 4
+
+// GenerateRandomInstance builds a random document of the definition with
+// the given name that validates against schema, for property-based tests of
+// servers and of Java deserialization round-trips. Required properties are
+// always filled in and the others at random, enums pick one of their
+// values, and strings match their pattern when it can be generated from.
+// The same seed always yields the same document.
+func GenerateRandomInstance(schema *JSONSchema, definition string, seed int64) (interface{}, error) {
+	def, ok := schema.Definitions[definition]
+	if !ok {
+		return nil, fmt.Errorf("Unknown definition %s.", definition)
+	}
+	r := randomInstances{schema: schema, rand: rand.New(rand.NewSource(seed))}
+	return r.value(def, 0), nil
+}
+
+// randomInstances builds random values of the descriptors of a schema.
+type randomInstances struct {
+	schema *JSONSchema
+	rand   *rand.Rand
+}
+
+// value returns a random value of p, nested depth objects deep.
+func (r randomInstances) value(p JSONPropertyDescriptor, depth int) interface{} {
+	if p.JSONReferenceDescriptor != nil {
+		name, ok := schemamodel.LocalDefinition(p.Reference)
+		def, defined := r.schema.Definitions[name]
+		if !ok || !defined {
+			return nil
+		}
+		return r.value(def, depth)
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		if len(p.AllOf) > 0 {
+			return r.allOf(p, depth)
+		}
+		for _, alts := range [][]JSONPropertyDescriptor{p.OneOf, p.AnyOf} {
+			if len(alts) > 0 {
+				return r.value(alts[r.rand.Intn(len(alts))], depth)
+			}
+		}
+	}
+	if p.JSONDescriptor == nil {
+		return nil
+	}
+	if p.AllowNull && r.rand.Intn(4) == 0 {
+		return nil
+	}
+	if len(p.Enum) > 0 {
+		return p.Enum[r.rand.Intn(len(p.Enum))]
+	}
+	switch p.Type {
+	case "object":
+		return r.object(p, depth)
+	case "array":
+		return r.array(p, depth)
+	case "string":
+		return r.string(p.JSONDescriptor)
+	case "integer":
+		return int64(math.Floor(r.number(p.JSONDescriptor, 1000)))
+	case "number":
+		return r.number(p.JSONDescriptor, 1000)
+	case "boolean":
+		return r.rand.Intn(2) == 0
+	}
+	return nil
+}
+
+// allOf merges the random values of the objects p is composed of.
+func (r randomInstances) allOf(p JSONPropertyDescriptor, depth int) interface{} {
+	obj := make(map[string]interface{})
+	parts := append([]JSONPropertyDescriptor{}, p.AllOf...)
+	if p.JSONDescriptor != nil || p.JSONObjectDescriptor != nil {
+		own := p
+		own.JSONCombinatorDescriptor = nil
+		parts = append(parts, own)
+	}
+	for _, part := range parts {
+		if v, ok := r.value(part, depth).(map[string]interface{}); ok {
+			for k, v := range v {
+				obj[k] = v
+			}
+		}
+	}
+	return obj
+}
+
+func (r randomInstances) object(p JSONPropertyDescriptor, depth int) interface{} {
+	obj := make(map[string]interface{})
+	if p.JSONObjectDescriptor != nil {
+		required := make(map[string]bool, len(p.Required))
+		for _, name := range p.Required {
+			required[name] = true
+		}
+		for _, name := range sortedPropertyNames(p.Properties) {
+			if !required[name] && (depth >= maxRandomDepth || r.rand.Intn(2) == 0) {
+				continue
+			}
+			if v := r.value(p.Properties[name], depth+1); v != nil || required[name] {
+				obj[name] = v
+			}
+		}
+	}
+	if p.JSONMapDescriptor != nil && depth < maxRandomDepth {
+		n := r.count(p.MinProperties, p.MaxProperties)
+		for i := 0; len(obj) < n && i < 4*n; i++ {
+			if key, value, ok := r.key(p, i); ok {
+				obj[key] = r.value(value, depth+1)
+			}
+		}
+	}
+	return obj
+}
+
+// key returns a random key of the map p and the descriptor of its value.
+// Keys follow propertyNames, which has no type as it only applies to
+// strings, and one of the patternProperties, if any. It returns false if
+// no key could be generated.
+func (r randomInstances) key(p JSONPropertyDescriptor, i int) (string, JSONPropertyDescriptor, bool) {
+	names := JSONDescriptor{}
+	if p.PropertyNames != nil && p.PropertyNames.JSONDescriptor != nil {
+		names = *p.PropertyNames.JSONDescriptor
+	}
+	value := p.MapValueType
+	if len(p.PatternProperties) > 0 {
+		patterns := sortedPropertyNames(p.PatternProperties)
+		names.Pattern = patterns[r.rand.Intn(len(patterns))]
+		value = p.PatternProperties[names.Pattern]
+	}
+	if len(names.Enum) > 0 {
+		key, ok := names.Enum[r.rand.Intn(len(names.Enum))].(string)
+		return key, value, ok
+	}
+	if len(names.Pattern) == 0 && names.MinLength == nil && names.MaxLength == nil && len(names.Format) == 0 {
+		return fmt.Sprintf("key%d", i), value, true
+	}
+	key := r.string(&names)
+	if len(names.Pattern) > 0 {
+		if re, err := regexp.Compile(names.Pattern); err != nil || !re.MatchString(key) {
+			return "", value, false
+		}
+	}
+	return key, value, fitsLength(key, &names)
+}
+
+func (r randomInstances) array(p JSONPropertyDescriptor, depth int) interface{} {
+	items := []interface{}{}
+	if p.JSONArrayDescriptor == nil {
+		return items
+	}
+	n := r.count(p.MinItems, p.MaxItems)
+	if depth >= maxRandomDepth && p.MinItems == nil {
+		n = 0
+	}
+	for i := 0; i < n; i++ {
+		items = append(items, r.value(p.Items, depth+1))
+	}
+	return items
+}
+
+// count returns a random number of entries between min and max, from 0 to
+// 3 if unbounded.
+func (r randomInstances) count(min, max *int) int {
+	lo, hi := 0, 3
+	if min != nil {
+		lo = *min
+		if hi < lo {
+			hi = lo
+		}
+	}
+	if max != nil && *max < hi {
+		hi = *max
+	}
+	if hi <= lo {
+		return lo
+	}
+	return lo + r.rand.Intn(hi-lo+1)
+}
+
+// number returns a random number within the bounds of d, or from 0 to
+// span if unbounded.
+func (r randomInstances) number(d *JSONDescriptor, span float64) float64 {
+	lo, hi := 0.0, span
+	switch {
+	case d.Minimum != nil && d.Maximum != nil:
+		lo, hi = *d.Minimum, *d.Maximum
+	case d.Minimum != nil:
+		lo, hi = *d.Minimum, *d.Minimum+span
+	case d.Maximum != nil:
+		lo, hi = *d.Maximum-span, *d.Maximum
+	}
+	if d.Type == "integer" {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+		if d.ExclusiveMinimum {
+			lo++
+		}
+		if d.ExclusiveMaximum {
+			hi--
+		}
+		if hi <= lo {
+			return lo
+		}
+		return lo + float64(r.rand.Int63n(int64(hi-lo)+1))
+	}
+	v := lo + r.rand.Float64()*(hi-lo)
+	if d.ExclusiveMinimum && v == lo {
+		v = (lo + hi) / 2
+	}
+	return v
+}
+
+// randomLetters are the runes of unconstrained random strings.
+const randomLetters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// string returns a random string of the format, pattern and length bounds
+// of d, falling back to a sample string if no generated value fits.
+func (r randomInstances) string(d *JSONDescriptor) string {
+	switch d.Format {
+	case "date-time":
+		return time.Unix(r.rand.Int63n(2000000000), 0).UTC().Format(time.RFC3339)
+	case "byte":
+		b := make([]byte, r.rand.Intn(16))
+		r.rand.Read(b)
+		return base64.StdEncoding.EncodeToString(b)
+	}
+	if len(d.Pattern) > 0 {
+		re, err := regexp.Compile(d.Pattern)
+		parsed, perr := syntax.Parse(d.Pattern, syntax.Perl)
+		if err == nil && perr == nil {
+			for i := 0; i < 10; i++ {
+				var b []rune
+				r.match(&b, parsed.Simplify())
+				if s := string(b); re.MatchString(s) && fitsLength(s, d) {
+					return s
+				}
+			}
+		}
+		return sampleString(d)
+	}
+	lo, hi := 1, 12
+	if d.MinLength != nil {
+		lo = *d.MinLength
+		if hi < lo {
+			hi = lo
+		}
+	}
+	if d.MaxLength != nil && *d.MaxLength < hi {
+		hi = *d.MaxLength
+	}
+	n := lo
+	if hi > lo {
+		n += r.rand.Intn(hi - lo + 1)
+	}
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randomLetters[r.rand.Intn(len(randomLetters))]
+	}
+	return string(b)
+}
+
+func fitsLength(s string, d *JSONDescriptor) bool {
+	n := len([]rune(s))
+	return (d.MinLength == nil || n >= *d.MinLength) && (d.MaxLength == nil || n <= *d.MaxLength)
+}
+
+// match appends to b a random string matched by re. Repetitions are
+// bounded to a few occurrences, and assertions such as anchors match
+// nothing.
+func (r randomInstances) match(b *[]rune, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		*b = append(*b, re.Rune...)
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return
+		}
+		i := r.rand.Intn(len(re.Rune)/2) * 2
+		lo, hi := re.Rune[i], re.Rune[i+1]
+		// Keep to printable ASCII when the range allows it.
+		if lo < 0x7f && hi > 0x7e {
+			hi = 0x7e
+		}
+		if lo < ' ' && hi >= ' ' {
+			lo = ' '
+		}
+		*b = append(*b, lo+rune(r.rand.Intn(int(hi-lo)+1)))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		*b = append(*b, rune(randomLetters[r.rand.Intn(len(randomLetters))]))
+	case syntax.OpCapture:
+		r.match(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			r.match(b, sub)
+		}
+	case syntax.OpAlternate:
+		r.match(b, re.Sub[r.rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, 3
+		case syntax.OpPlus:
+			min, max = 1, 3
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 || max > min+3 {
+			max = min + 3
+		}
+		n := min + r.rand.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			r.match(b, re.Sub[0])
+		}
+	}
+}
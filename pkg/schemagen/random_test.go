@@ -0,0 +1,59 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type randomPhase string
+
+type randomItem struct {
+	Name  string  `json:"name" schemagen:"pattern=^[a-z]{1,8}$"`
+	Ratio float64 `json:"ratio" schemagen:"exclusiveMinimum=0,maximum=1"`
+	Count uint8   `json:"count"`
+}
+
+type randomDocument struct {
+	ByPort   map[int]randomItem         `json:"byPort"`
+	ByPhase  map[randomPhase]int64      `json:"byPhase"`
+	Labels   map[string]string          `json:"labels" schemagen:"keys=qualifiedName,maxProperties=4"`
+	Names    map[string]bool            `json:"names" schemagen:"keyPattern=^[a-z]{2,5}-[0-9]$"`
+	Patterns map[string]randomItem      `json:"patterns" schemagen:"keyPattern=^x[0-9]+$,patternProperties"`
+	Nested   map[int32]map[int64]string `json:"nested"`
+	Items    []randomItem               `json:"items"`
+	Next     *randomDocument            `json:"next,omitempty"`
+}
+
+type randomRoot struct {
+	Document randomDocument `json:"document"`
+}
+
+// TestRandomInstancesVerify checks that the random instances of many seeds
+// validate against the schema they are generated from.
+func TestRandomInstancesVerify(t *testing.T) {
+	for _, opts := range []Options{
+		{Enums: map[reflect.Type][]string{reflect.TypeOf(randomPhase("")): {"Pending", "Running"}}},
+		{PatternProperties: true, Draft: Draft2020},
+	} {
+		schema, err := GenerateSchemaWithOptions(reflect.TypeOf(randomRoot{}), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := definitionName(t, schema, "randomDocument")
+		failed := 0
+		for seed := int64(0); seed < 300; seed++ {
+			document, err := GenerateRandomInstance(schema, name, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(schema, map[string]interface{}{"document": document}); err != nil {
+				if failed++; failed <= 3 {
+					t.Errorf("seed %d: %v", seed, err)
+				}
+			}
+		}
+		if failed > 0 {
+			t.Errorf("%d of 300 instances are invalid", failed)
+		}
+	}
+}
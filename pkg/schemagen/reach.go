@@ -0,0 +1,171 @@
+package schemagen
+
+import "reflect"
+
+// TypeInfo describes a type that would be defined by a generation.
+type TypeInfo struct {
+	Type reflect.Type
+	// Package is the Go package path of the type.
+	Package string
+	// Name is the definition name of the type.
+	Name     string
+	JavaType string
+	// Abstract tells whether the definition is listed in
+	// Options.Abstract.
+	Abstract bool
+}
+
+// ListReachableTypes performs only the walk of a generation for root and
+// reports every type that would be defined, in the order it is reached.
+func ListReachableTypes(root reflect.Type, opts Options) []TypeInfo {
+	g := newSchemaGenerator(opts)
+	g.pruned = g.prunedTypes(root)
+	var infos []TypeInfo
+	for _, t := range g.reachableTypes(root) {
+		infos = append(infos, TypeInfo{
+			Type:     t,
+			Package:  t.PkgPath(),
+			Name:     g.qualifiedName(t),
+			JavaType: g.javaType(t),
+			Abstract: opts.Abstract[g.qualifiedName(t)],
+		})
+	}
+	return infos
+}
+
+// reachableTypes returns, in the order they are first reached, the types
+// the generator defines when walking the fields of root. It follows the
+// same rules as getPropertyDescriptor without building any descriptor, so
+// it can be used to size a generation up front.
+func (g *schemaGenerator) reachableTypes(root reflect.Type) []reflect.Type {
+	var types []reflect.Type
+	g.walkReachable(root, func(t reflect.Type) {
+		types = append(types, t)
+	}, nil)
+	return types
+}
+
+// walkReachable walks the fields of root like reachableTypes, calling
+// define for every type to define and, if set, prune for the type held by
+// every field tagged schemagen:"prune", which is not followed. Types are
+// then only reached through the types of fields, not through unions nor
+// interface implementations, which the generator describes as free-form
+// objects when pruned. It returns the struct types whose fields were
+// walked.
+func (g *schemaGenerator) walkReachable(root reflect.Type, define, prune func(t reflect.Type)) map[reflect.Type]bool {
+	defined := map[reflect.Type]bool{}
+	walked := map[reflect.Type]bool{}
+
+	var walkFields func(t reflect.Type)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		t = g.mappedType(t)
+		if _, ok := g.handlers[t]; ok {
+			return
+		}
+		if _, ok := g.embeddedObjectDescriptor(t); ok {
+			return
+		}
+		if _, ok := g.unionTypeDescriptor(t); ok {
+			return
+		}
+		if _, ok := g.formatDescriptor(t); ok {
+			return
+		}
+		if _, ok := g.kinds[t.Kind()]; ok {
+			if g.primitiveStyle(t) == PrimitiveDefinition && !defined[t] {
+				defined[t] = true
+				define(t)
+			}
+			return
+		}
+		if isRecursive(t) {
+			if walked[t] {
+				return
+			}
+			walked[t] = true
+		}
+		switch t.Kind() {
+		case reflect.Interface:
+			if prune != nil {
+				return
+			}
+			for _, impl := range g.implementations[t] {
+				walk(impl)
+			}
+		case reflect.Slice, reflect.Array:
+			walk(t.Elem())
+		case reflect.Map:
+			if g.usesMapEntries(t) {
+				g.registerMapEntry(t)
+				if !defined[t] {
+					defined[t] = true
+					define(t)
+				}
+				walk(t.Key())
+			}
+			walk(t.Elem())
+		case reflect.Struct:
+			if g.pruned[t] {
+				return
+			}
+			if !defined[t] && len(t.Name()) > 0 {
+				defined[t] = true
+				define(t)
+			}
+			walkFields(t)
+		}
+	}
+	walkFields = func(t reflect.Type) {
+		if walked[t] {
+			return
+		}
+		walked[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			tag := getSchemagenTag(field)
+			if g.isIgnored(field) && !g.isCatchAll(field) {
+				continue
+			}
+			if _, ok := tag["prune"]; ok {
+				if prune != nil {
+					prune(g.mappedType(elemType(field.Type)))
+				}
+				continue
+			}
+			if _, ok := tag["any"]; ok {
+				continue
+			}
+			if rule := g.fieldRule(t, g.getFieldName(field)); rule.Exclude || rule.Opaque {
+				continue
+			}
+			if value, ok := tag["oneOf"]; ok && prune == nil {
+				if union := g.tagUnionTypes(t, field, value, false); len(union) > 0 {
+					for _, u := range union {
+						walk(u)
+					}
+					continue
+				}
+			}
+			walk(fieldType(field, tag))
+		}
+	}
+	walkFields(root)
+	return walked
+}
+
+// mappedType returns the type the generator describes for t, dereferenced,
+// interned and looked up in the type map.
+func (g *schemaGenerator) mappedType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	t = g.intern(t)
+	if tt, ok := g.typeMap[t]; ok {
+		t = tt
+	}
+	return t
+}
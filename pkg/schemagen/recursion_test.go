@@ -0,0 +1,122 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type recursiveNode struct {
+	Children []recursiveNode          `json:"children"`
+	ByName   map[string]recursiveNode `json:"byName"`
+}
+
+type recursiveLink struct {
+	Next *recursiveLink `json:"next"`
+}
+
+type recursiveParent struct {
+	Child *recursiveChild `json:"child"`
+}
+
+type recursiveChild struct {
+	Parents []recursiveParent `json:"parents"`
+}
+
+type recursiveTree map[string]recursiveTree
+
+type recursiveRoot struct {
+	Node   recursiveNode   `json:"node"`
+	Link   recursiveLink   `json:"link"`
+	Parent recursiveParent `json:"parent"`
+	Tree   recursiveTree   `json:"tree"`
+}
+
+func recursiveSchema(t *testing.T) *JSONSchema {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(recursiveRoot{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// definitionName returns the name of the definition of the type typeName
+// in schema.
+func definitionName(t *testing.T, schema *JSONSchema, typeName string) string {
+	names := FindDefinitions(schema, typeName)
+	if len(names) != 1 {
+		t.Fatalf("%d definitions of %s", len(names), typeName)
+	}
+	return names[0]
+}
+
+// property returns the property name of the definition of the type
+// typeName in schema.
+func property(t *testing.T, schema *JSONSchema, typeName, name string) JSONPropertyDescriptor {
+	d := schema.Definitions[definitionName(t, schema, typeName)]
+	if d.JSONObjectDescriptor == nil {
+		t.Fatalf("the definition of %s is not an object", typeName)
+	}
+	p, ok := d.Properties[name]
+	if !ok {
+		t.Fatalf("no property %s in %s", name, typeName)
+	}
+	return p
+}
+
+func TestRecursiveTypes(t *testing.T) {
+	schema := recursiveSchema(t)
+	tests := []struct {
+		name     string
+		def      string
+		property string
+		ref      func(JSONPropertyDescriptor) JSONPropertyDescriptor
+		target   string
+	}{
+		{
+			name: "direct through a slice", def: "recursiveNode", property: "children",
+			ref:    func(p JSONPropertyDescriptor) JSONPropertyDescriptor { return p.Items },
+			target: "recursiveNode",
+		},
+		{
+			name: "direct through a map", def: "recursiveNode", property: "byName",
+			ref:    func(p JSONPropertyDescriptor) JSONPropertyDescriptor { return p.MapValueType },
+			target: "recursiveNode",
+		},
+		{
+			name: "pointer", def: "recursiveLink", property: "next",
+			ref:    func(p JSONPropertyDescriptor) JSONPropertyDescriptor { return p },
+			target: "recursiveLink",
+		},
+		{
+			name: "indirect", def: "recursiveParent", property: "child",
+			ref:    func(p JSONPropertyDescriptor) JSONPropertyDescriptor { return p },
+			target: "recursiveChild",
+		},
+		{
+			name: "indirect back", def: "recursiveChild", property: "parents",
+			ref:    func(p JSONPropertyDescriptor) JSONPropertyDescriptor { return p.Items },
+			target: "recursiveParent",
+		},
+	}
+	for _, test := range tests {
+		ref := test.ref(property(t, schema, test.def, test.property))
+		want := "#/definitions/" + definitionName(t, schema, test.target)
+		if ref.JSONReferenceDescriptor == nil || ref.Reference != want {
+			t.Errorf("%s: %s.%s does not refer to %s", test.name, test.def, test.property, want)
+		}
+	}
+}
+
+func TestRecursiveContainerJavaType(t *testing.T) {
+	schema := recursiveSchema(t)
+	tree := schema.Properties["tree"]
+	if tree.JSONMapDescriptor == nil || !reflect.DeepEqual(tree.MapValueType, JSONPropertyDescriptor{}) {
+		t.Fatalf("the values of the tree are not free-form: %#v", tree.MapValueType)
+	}
+	if want := "java.util.Map<String,Object>"; tree.JavaTypeDescriptor == nil || tree.JavaType != want {
+		t.Errorf("the Java type of the tree is %v, want %s", tree.JavaTypeDescriptor, want)
+	}
+	if len(schema.Warnings) == 0 {
+		t.Errorf("no warning about the tree referring to itself")
+	}
+}
@@ -0,0 +1,166 @@
+package schemagen
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// mapDescriptor returns a copy of p in which fn has been applied to every
+// nested descriptor (properties, array items, map values, combined schemas
+// and nested definitions) and then to the copy itself. Descriptors are
+// copied rather than modified since generated schemas share them between
+// definitions.
+func mapDescriptor(p JSONPropertyDescriptor, fn func(JSONPropertyDescriptor) JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if p.JSONObjectDescriptor != nil {
+		p.JSONObjectDescriptor = mapObjectDescriptor(p.JSONObjectDescriptor, fn)
+	}
+	if p.JSONArrayDescriptor != nil {
+		array := *p.JSONArrayDescriptor
+		array.Items = mapDescriptor(array.Items, fn)
+		p.JSONArrayDescriptor = &array
+	}
+	if p.JSONMapDescriptor != nil {
+		m := *p.JSONMapDescriptor
+		m.MapValueType = mapDescriptor(m.MapValueType, fn)
+		if m.PropertyNames != nil {
+			names := mapDescriptor(*m.PropertyNames, fn)
+			m.PropertyNames = &names
+		}
+		if m.PatternProperties != nil {
+			patterns := make(map[string]JSONPropertyDescriptor, len(m.PatternProperties))
+			for k, v := range m.PatternProperties {
+				patterns[k] = mapDescriptor(v, fn)
+			}
+			m.PatternProperties = patterns
+		}
+		p.JSONMapDescriptor = &m
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		c := *p.JSONCombinatorDescriptor
+		c.AllOf = mapDescriptors(c.AllOf, fn)
+		c.AnyOf = mapDescriptors(c.AnyOf, fn)
+		c.OneOf = mapDescriptors(c.OneOf, fn)
+		if c.Discriminator != nil {
+			c.Discriminator = remapDiscriminator(c.Discriminator, p.OneOf, c.OneOf)
+		}
+		p.JSONCombinatorDescriptor = &c
+	}
+	if p.JSONDefinitionsDescriptor != nil {
+		defs := make(map[string]JSONPropertyDescriptor, len(p.Definitions))
+		for k, v := range p.Definitions {
+			defs[k] = mapDescriptor(v, fn)
+		}
+		p.JSONDefinitionsDescriptor = &JSONDefinitionsDescriptor{
+			Definitions: defs,
+		}
+	}
+	return fn(p)
+}
+
+func mapDescriptors(ps []JSONPropertyDescriptor, fn func(JSONPropertyDescriptor) JSONPropertyDescriptor) []JSONPropertyDescriptor {
+	if ps == nil {
+		return nil
+	}
+	result := make([]JSONPropertyDescriptor, len(ps))
+	for i, p := range ps {
+		result[i] = mapDescriptor(p, fn)
+	}
+	return result
+}
+
+func mapObjectDescriptor(o *JSONObjectDescriptor, fn func(JSONPropertyDescriptor) JSONPropertyDescriptor) *JSONObjectDescriptor {
+	if o == nil {
+		return nil
+	}
+	desc := *o
+	if additional, ok := o.AdditionalProperties.(JSONPropertyDescriptor); ok {
+		desc.AdditionalProperties = mapDescriptor(additional, fn)
+	}
+	if o.Properties != nil {
+		desc.Properties = make(map[string]JSONPropertyDescriptor, len(o.Properties))
+		for k, v := range o.Properties {
+			desc.Properties[k] = mapDescriptor(v, fn)
+		}
+	}
+	return &desc
+}
+
+// mapSchema applies mapDescriptor to the root properties and definitions of
+// s, replacing them with the mapped copies.
+func mapSchema(s *JSONSchema, fn func(JSONPropertyDescriptor) JSONPropertyDescriptor) {
+	s.JSONObjectDescriptor = mapObjectDescriptor(s.JSONObjectDescriptor, fn)
+	for name, def := range s.Definitions {
+		s.Definitions[name] = mapDescriptor(def, fn)
+	}
+}
+
+// RewriteRefs replaces every $ref of s, in its root properties, in its
+// definitions and in its OpenAPI components, including nested descriptors,
+// by fn(ref). It relocates a schema, e.g. under the base URI of a registry
+// it is published to.
+func RewriteRefs(s *JSONSchema, fn func(old string) string) {
+	mapSchema(s, refRewriter(fn))
+	if s.Components != nil {
+		for name, schema := range s.Components.Schemas {
+			s.Components.Schemas[name] = rewriteRefs(schema, fn)
+		}
+	}
+}
+
+// rewriteRefs returns a copy of p in which every $ref, including the ones in
+// nested descriptors, is replaced by fn(ref).
+func rewriteRefs(p JSONPropertyDescriptor, fn func(string) string) JSONPropertyDescriptor {
+	return mapDescriptor(p, refRewriter(fn))
+}
+
+func refRewriter(fn func(string) string) func(JSONPropertyDescriptor) JSONPropertyDescriptor {
+	return func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		if p.JSONReferenceDescriptor != nil {
+			p.JSONReferenceDescriptor = &JSONReferenceDescriptor{
+				Reference: fn(p.Reference),
+			}
+		}
+		return p
+	}
+}
+
+// referencedDefinitions returns the sorted names of the local definitions
+// referenced by p.
+func referencedDefinitions(p JSONPropertyDescriptor) []string {
+	deps := map[string]bool{}
+	rewriteRefs(p, func(ref string) string {
+		if name, ok := schemamodel.LocalDefinition(ref); ok {
+			deps[name] = true
+		}
+		return ref
+	})
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// wrapRef moves the $ref of p into an allOf if p has sibling keywords,
+// which draft-04 validators ignore and some tools reject.
+func wrapRef(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	if p.JSONReferenceDescriptor == nil {
+		return p
+	}
+	ref := p.JSONReferenceDescriptor
+	p.JSONReferenceDescriptor = nil
+	if reflect.DeepEqual(p, JSONPropertyDescriptor{}) {
+		p.JSONReferenceDescriptor = ref
+		return p
+	}
+	c := JSONCombinatorDescriptor{}
+	if p.JSONCombinatorDescriptor != nil {
+		c = *p.JSONCombinatorDescriptor
+	}
+	c.AllOf = append([]JSONPropertyDescriptor{{JSONReferenceDescriptor: ref}}, c.AllOf...)
+	p.JSONCombinatorDescriptor = &c
+	return p
+}
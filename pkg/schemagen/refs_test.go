@@ -0,0 +1,47 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type refsInner struct {
+	Name string `json:"name"`
+}
+
+type refsOuter struct {
+	Inner refsInner `json:"inner"`
+}
+
+type refsRoot struct {
+	Outer refsOuter `json:"outer"`
+}
+
+// TestRewriteRefsComponents checks that the references of the OpenAPI
+// components are rewritten along the ones of the definitions.
+func TestRewriteRefsComponents(t *testing.T) {
+	for _, mode := range []ComponentsMode{DefinitionRefs, ComponentRefs} {
+		schema, err := GenerateSchemaWithOptions(reflect.TypeOf(refsRoot{}), Options{Components: mode})
+		if err != nil {
+			t.Fatal(err)
+		}
+		const base = "https://schemas.example.com/v1.json"
+		RewriteRefs(schema, func(ref string) string {
+			return base + ref
+		})
+		var refs int
+		for name, component := range schema.Components.Schemas {
+			rewriteRefs(component, func(ref string) string {
+				refs++
+				if !strings.HasPrefix(ref, base+"#/") {
+					t.Errorf("%v: component %s refers to %s", mode, name, ref)
+				}
+				return ref
+			})
+		}
+		if refs == 0 {
+			t.Errorf("%v: expected references in the components", mode)
+		}
+	}
+}
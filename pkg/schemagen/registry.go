@@ -0,0 +1,62 @@
+package schemagen
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// registry holds the types registered by name, so that binaries can
+// generate schemas of their API types by name where their sources are not
+// available.
+var registry = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: make(map[string]reflect.Type)}
+
+// Register makes t available under name to Lookup, typically from the init
+// function of the package declaring it. It panics if name is already
+// registered for another type.
+func Register(name string, t reflect.Type) {
+	registry.Lock()
+	defer registry.Unlock()
+	if other, ok := registry.types[name]; ok && other != t {
+		panic("schemagen: Register called twice for " + name)
+	}
+	registry.types[name] = t
+}
+
+// Lookup returns the type registered under name.
+func Lookup(name string) (reflect.Type, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.types[name]
+	return t, ok
+}
+
+// RegisteredNames returns the sorted names of the registered types.
+func RegisteredNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.types))
+	for name := range registry.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Registered returns a resolver finding types among the registered ones,
+// for generators working from package sources.
+func Registered() TypeResolver {
+	return TypeResolverFunc(func(pkgPath, name string) (reflect.Type, bool) {
+		registry.RLock()
+		defer registry.RUnlock()
+		for _, t := range registry.types {
+			if t.PkgPath() == pkgPath && t.Name() == name {
+				return t, true
+			}
+		}
+		return nil, false
+	})
+}
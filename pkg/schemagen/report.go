@@ -0,0 +1,154 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Report summarizes the size of a schema, to track schema bloat over
+// releases and decide what to prune.
+type Report struct {
+	// Definitions is the number of definitions.
+	Definitions int
+	// Properties is the number of properties of each definition.
+	Properties map[string]int
+	// MaxDepth is the longest chain of nested definitions below the root.
+	MaxDepth int
+	// Subtrees is the number of definitions reachable from each
+	// definition, itself excluded.
+	Subtrees map[string]int
+	// Size is the size of the serialized schema in bytes.
+	Size int
+}
+
+// NewReport computes the report of a schema.
+func NewReport(schema *JSONSchema) (*Report, error) {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{
+		Definitions: len(schema.Definitions),
+		Properties:  make(map[string]int),
+		Subtrees:    make(map[string]int),
+		Size:        len(b),
+	}
+	deps := make(map[string][]string)
+	for name, def := range schema.Definitions {
+		if def.JSONObjectDescriptor != nil {
+			r.Properties[name] = len(def.Properties)
+		}
+		deps[name] = referencedDefinitions(def)
+	}
+
+	depths := map[string]int{}
+	visiting := map[string]bool{}
+	var depth func(name string) int
+	depth = func(name string) int {
+		if d, ok := depths[name]; ok {
+			return d
+		}
+		if visiting[name] {
+			return 0
+		}
+		visiting[name] = true
+		max := 0
+		for _, dep := range deps[name] {
+			if d := depth(dep); d > max {
+				max = d
+			}
+		}
+		visiting[name] = false
+		depths[name] = max + 1
+		return max + 1
+	}
+	for name := range schema.Definitions {
+		reached := map[string]bool{}
+		var reach func(name string)
+		reach = func(name string) {
+			for _, dep := range deps[name] {
+				if !reached[dep] {
+					reached[dep] = true
+					reach(dep)
+				}
+			}
+		}
+		reach(name)
+		delete(reached, name)
+		r.Subtrees[name] = len(reached)
+	}
+	if schema.JSONObjectDescriptor != nil {
+		root := JSONPropertyDescriptor{JSONObjectDescriptor: schema.JSONObjectDescriptor}
+		for _, dep := range referencedDefinitions(root) {
+			if d := depth(dep); d > r.MaxDepth {
+				r.MaxDepth = d
+			}
+		}
+	}
+	return r, nil
+}
+
+type namedCount struct {
+	name  string
+	count int
+}
+
+type byCount []namedCount
+
+func (c byCount) Len() int      { return len(c) }
+func (c byCount) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c byCount) Less(i, j int) bool {
+	if c[i].count != c[j].count {
+		return c[i].count > c[j].count
+	}
+	return c[i].name < c[j].name
+}
+
+// top returns the n entries of m with the highest counts.
+func top(m map[string]int, n int) []namedCount {
+	counts := make([]namedCount, 0, len(m))
+	for name, count := range m {
+		counts = append(counts, namedCount{name, count})
+	}
+	sort.Sort(byCount(counts))
+	if len(counts) > n {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// ReportEmitter writes a plain text size report of the schema, listing
+// the Top definitions with the largest subtrees and property counts.
+type ReportEmitter struct {
+	Top int
+}
+
+func (e ReportEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	r, err := NewReport(schema)
+	if err != nil {
+		return err
+	}
+	n := e.Top
+	if n == 0 {
+		n = 10
+	}
+	total := 0
+	for _, count := range r.Properties {
+		total += count
+	}
+	fmt.Fprintf(w, "Definitions:      %d\n", r.Definitions)
+	fmt.Fprintf(w, "Properties:       %d\n", total)
+	fmt.Fprintf(w, "Max depth:        %d\n", r.MaxDepth)
+	fmt.Fprintf(w, "Serialized size:  %d bytes\n", r.Size)
+	fmt.Fprintf(w, "\nLargest subtrees:\n")
+	for _, c := range top(r.Subtrees, n) {
+		fmt.Fprintf(w, "  %6d  %s\n", c.count, c.name)
+	}
+	fmt.Fprintf(w, "\nMost properties:\n")
+	for _, c := range top(r.Properties, n) {
+		fmt.Fprintf(w, "  %6d  %s\n", c.count, c.name)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package schemagen
+
+import "fmt"
+
+// Retention selects which definitions a generated schema keeps.
+type Retention int
+
+const (
+	// RetainAll keeps every definition, including the ones nothing
+	// reachable from the root refers to, such as the definitions of
+	// flattened embedded structs, for forward compatibility.
+	RetainAll Retention = iota
+	// RetainReachable drops the definitions not reachable from the
+	// properties of the root.
+	RetainReachable
+)
+
+// ParseRetention parses the name of a retention policy: "all",
+// "reachable" or an empty string for keeping all definitions.
+func ParseRetention(s string) (Retention, error) {
+	switch s {
+	case "", "all":
+		return RetainAll, nil
+	case "reachable":
+		return RetainReachable, nil
+	}
+	return 0, fmt.Errorf("Unknown retention policy %q.", s)
+}
+
+// UnreachableDefinitions returns the sorted names of the definitions of s
+// that no reference reachable from the properties of its root leads to,
+// directly or through other definitions.
+func UnreachableDefinitions(s *JSONSchema) []string {
+	reachable := reachableDefinitions(s)
+	var names []string
+	for _, name := range sortedDefinitionNames(s.Definitions) {
+		if !reachable[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PruneDefinitions drops the unreachable definitions of s, e.g. of a schema
+// assembled or edited after its generation, and returns their names.
+func PruneDefinitions(s *JSONSchema) []string {
+	names := UnreachableDefinitions(s)
+	for _, name := range names {
+		delete(s.Definitions, name)
+	}
+	return names
+}
+
+// reachableDefinitions returns the names of the definitions of s reachable
+// from the properties of its root.
+func reachableDefinitions(s *JSONSchema) map[string]bool {
+	reachable := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		def, ok := s.Definitions[name]
+		if !ok || reachable[name] {
+			return
+		}
+		reachable[name] = true
+		for _, dep := range referencedDefinitions(def) {
+			visit(dep)
+		}
+	}
+	if s.JSONObjectDescriptor != nil {
+		for _, name := range referencedDefinitions(JSONPropertyDescriptor{JSONObjectDescriptor: s.JSONObjectDescriptor}) {
+			visit(name)
+		}
+	}
+	return reachable
+}
+
+// applyRetention drops the unreachable definitions of s under the
+// RetainReachable policy, reporting the decision taken for every definition
+// to Options.RetentionReport.
+func (g *schemaGenerator) applyRetention(s *JSONSchema) {
+	reachable := reachableDefinitions(s)
+	for _, name := range sortedDefinitionNames(s.Definitions) {
+		kept := reachable[name] || g.opts.Retention == RetainAll
+		if !kept {
+			delete(s.Definitions, name)
+		}
+		if g.opts.RetentionReport != nil {
+			g.opts.RetentionReport(name, reachable[name], kept)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SharedRoot returns a copy of the root of s without definitions, in which
+// every reference to a definition points to the shared definitions
+// document named shared instead. It is used to regenerate the schema of a
+// single root type against a committed definitions file, see
+// MergeDefinitions.
+func SharedRoot(s *JSONSchema, shared string) *JSONSchema {
+	root := *s
+	root.Definitions = nil
+	root.JSONObjectDescriptor = mapObjectDescriptor(s.JSONObjectDescriptor, func(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+		if p.JSONReferenceDescriptor != nil && strings.HasPrefix(p.Reference, definitionsPrefix) {
+			p.JSONReferenceDescriptor = &JSONReferenceDescriptor{
+				Reference: shared + p.Reference,
+			}
+		}
+		return p
+	})
+	return &root
+}
+
+// MergeDefinitions adds the serialized definitions to the definitions of
+// the shared document existing, which may be empty, and returns the
+// updated document. Definitions missing from the update are kept as is and
+// existing definitions equivalent to their update are reused, so
+// regenerating one root type only touches the types reachable from it.
+// existing is returned unchanged if no definition differs.
+func MergeDefinitions(existing []byte, definitions map[string][]byte) ([]byte, error) {
+	doc := make(map[string]json.RawMessage)
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &doc); err != nil {
+			return nil, err
+		}
+	}
+	defs := make(map[string]json.RawMessage)
+	if raw, ok := doc["definitions"]; ok {
+		if err := json.Unmarshal(raw, &defs); err != nil {
+			return nil, err
+		}
+	}
+
+	changed := len(existing) == 0
+	for name, def := range definitions {
+		if old, ok := defs[name]; ok {
+			diffs, err := CompareJSON(old, def)
+			if err != nil {
+				return nil, err
+			}
+			if len(diffs) == 0 {
+				continue
+			}
+		}
+		defs[name] = json.RawMessage(def)
+		changed = true
+	}
+	if !changed {
+		return existing, nil
+	}
+	raw, err := json.Marshal(defs)
+	if err != nil {
+		return nil, err
+	}
+	doc["definitions"] = raw
+	return json.Marshal(doc)
+}
@@ -0,0 +1,387 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TypeSnapshot is a portable record of the type graph reachable from root
+// types, along with the doc comments and string constants of their
+// packages. It is written as JSON by a program compiling the types and read
+// by GenerateSnapshotSchema, which regenerates their schema without them,
+// e.g. with a toolchain that can no longer build the API packages.
+type TypeSnapshot struct {
+	// Roots are the root types, as "<package path>.<name>".
+	Roots []string `json:"roots"`
+	// Packages holds the named types reached, keyed by package path.
+	Packages map[string]*SnapshotPackage `json:"packages"`
+}
+
+// SnapshotPackage records the named types of a package reached from the
+// roots and the doc comments read from its sources, if they were found.
+type SnapshotPackage struct {
+	Types      map[string]*SnapshotNamed `json:"types"`
+	Docs       map[string]string         `json:"docs,omitempty"`
+	FieldDocs  map[string]string         `json:"fieldDocs,omitempty"`
+	Labels     map[string][]string       `json:"labels,omitempty"`
+	Properties map[string]string         `json:"properties,omitempty"`
+	Deprecated []string                  `json:"deprecated,omitempty"`
+
+	// constants holds the constants read from the sources, keyed by type
+	// name, until the types are described.
+	constants map[string][]enumConstant
+}
+
+// SnapshotNamed is a named type, described by its underlying type, and the
+// exported string constants of that type, in declaration order.
+type SnapshotNamed struct {
+	Underlying SnapshotType       `json:"underlying"`
+	Constants  []SnapshotConstant `json:"constants,omitempty"`
+}
+
+type SnapshotConstant struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// SnapshotType describes a type. Named types are referred to by PkgPath and
+// Name and described once in their package; the others are described in
+// place by their reflect kind, e.g. "slice" or "int64".
+type SnapshotType struct {
+	PkgPath string          `json:"pkgPath,omitempty"`
+	Name    string          `json:"name,omitempty"`
+	Kind    string          `json:"kind,omitempty"`
+	Key     *SnapshotType   `json:"key,omitempty"`
+	Elem    *SnapshotType   `json:"elem,omitempty"`
+	Len     int             `json:"len,omitempty"`
+	Fields  []SnapshotField `json:"fields,omitempty"`
+}
+
+// SnapshotField is a field of a struct. Unexported fields are only kept
+// when they are embedded.
+type SnapshotField struct {
+	Name     string       `json:"name"`
+	Type     SnapshotType `json:"type"`
+	Tag      string       `json:"tag,omitempty"`
+	Embedded bool         `json:"embedded,omitempty"`
+}
+
+// NewTypeSnapshot walks the types reachable from roots and records them,
+// with the doc comments and constants of the packages whose sources are
+// found through go/build.
+func NewTypeSnapshot(roots ...reflect.Type) (*TypeSnapshot, error) {
+	s := &TypeSnapshot{Packages: make(map[string]*SnapshotPackage)}
+	for _, t := range roots {
+		if t.Kind() != reflect.Struct || len(t.PkgPath()) == 0 {
+			return nil, fmt.Errorf("Root type %s is not a named struct.", t)
+		}
+		s.Roots = append(s.Roots, typeKey(t))
+		s.describe(t)
+	}
+	for _, pkg := range s.Packages {
+		pkg.prune()
+	}
+	return s, nil
+}
+
+// LoadTypeSnapshot reads the snapshot written at path.
+func LoadTypeSnapshot(path string) (*TypeSnapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &TypeSnapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("Unable to parse snapshot %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Write writes the snapshot to w as indented JSON.
+func (s *TypeSnapshot) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// describe records t, and the named types it refers to, and returns its
+// description.
+func (s *TypeSnapshot) describe(t reflect.Type) SnapshotType {
+	if len(t.PkgPath()) == 0 || len(t.Name()) == 0 {
+		return s.describeUnderlying(t)
+	}
+	ref := SnapshotType{PkgPath: t.PkgPath(), Name: t.Name()}
+	pkg := s.Packages[t.PkgPath()]
+	if pkg == nil {
+		pkg = newSnapshotPackage(t.PkgPath())
+		s.Packages[t.PkgPath()] = pkg
+	}
+	if _, ok := pkg.Types[t.Name()]; ok {
+		return ref
+	}
+	named := &SnapshotNamed{}
+	for _, c := range pkg.constants[t.Name()] {
+		named.Constants = append(named.Constants, SnapshotConstant{Name: c.name, Value: c.value})
+	}
+	pkg.Types[t.Name()] = named
+	named.Underlying = s.describeUnderlying(t)
+	return ref
+}
+
+// describeUnderlying returns the description of the structure of t,
+// ignoring its name.
+func (s *TypeSnapshot) describeUnderlying(t reflect.Type) SnapshotType {
+	desc := SnapshotType{Kind: t.Kind().String()}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		elem := s.describe(t.Elem())
+		desc.Elem = &elem
+	case reflect.Array:
+		elem := s.describe(t.Elem())
+		desc.Elem = &elem
+		desc.Len = t.Len()
+	case reflect.Map:
+		key, elem := s.describe(t.Key()), s.describe(t.Elem())
+		desc.Key, desc.Elem = &key, &elem
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if len(f.PkgPath) > 0 && !f.Anonymous {
+				continue
+			}
+			desc.Fields = append(desc.Fields, SnapshotField{
+				Name:     f.Name,
+				Type:     s.describe(f.Type),
+				Tag:      string(f.Tag),
+				Embedded: f.Anonymous,
+			})
+		}
+	}
+	return desc
+}
+
+// newSnapshotPackage returns the record of the package with the given
+// path, holding the doc comments and constants of its sources, if found.
+func newSnapshotPackage(pkgPath string) *SnapshotPackage {
+	pkg := &SnapshotPackage{Types: make(map[string]*SnapshotNamed)}
+	docs, err := loadPackageDocs(pkgPath)
+	if err != nil {
+		return pkg
+	}
+	pkg.Docs, pkg.FieldDocs = docs.types, docs.fields
+	pkg.Labels, pkg.Properties = docs.labels, docs.properties
+	for name := range docs.deprecated {
+		pkg.Deprecated = append(pkg.Deprecated, name)
+	}
+	sort.Strings(pkg.Deprecated)
+	pkg.constants = docs.constants
+	return pkg
+}
+
+// prune drops the doc comments of the types and constants that were not
+// reached, and the empty ones.
+func (pkg *SnapshotPackage) prune() {
+	for name, text := range pkg.Docs {
+		if pkg.Types[name] == nil || len(text) == 0 {
+			delete(pkg.Docs, name)
+		}
+	}
+	for name, labels := range pkg.Labels {
+		if pkg.Types[name] == nil || len(labels) == 0 {
+			delete(pkg.Labels, name)
+		}
+	}
+	for name := range pkg.Properties {
+		if pkg.Types[name] == nil {
+			delete(pkg.Properties, name)
+		}
+	}
+	for name, text := range pkg.FieldDocs {
+		if i := strings.Index(name, "."); i < 0 || pkg.Types[name[:i]] == nil || len(text) == 0 {
+			delete(pkg.FieldDocs, name)
+		}
+	}
+	constants := make(map[string]bool)
+	for _, t := range pkg.Types {
+		for _, c := range t.Constants {
+			constants[c.Name] = true
+		}
+	}
+	var deprecated []string
+	for _, name := range pkg.Deprecated {
+		if constants[name] {
+			deprecated = append(deprecated, name)
+		}
+	}
+	pkg.Deprecated = deprecated
+}
+
+// GenerateSnapshotSchema generates the schema of the roots of a snapshot
+// written by NewTypeSnapshot, without the Go types it was taken from. Its
+// types are rebuilt as go/types types and walked by the source front-end,
+// so the same options apply as to GenerateSourceSchema, and doc comments
+// and enum constants are read from the snapshot rather than from sources.
+func GenerateSnapshotSchema(s *TypeSnapshot, opts Options) (*JSONSchema, error) {
+	if len(s.Roots) == 0 {
+		return nil, fmt.Errorf("At least one root type is required.")
+	}
+	b := &snapshotBuilder{
+		snapshot: s,
+		packages: make(map[string]*types.Package),
+		named:    make(map[string]*types.Named),
+	}
+	var roots []*types.TypeName
+	for _, root := range s.Roots {
+		pkgPath, name := SplitTypeName(root)
+		t, err := b.lookup(pkgPath, name)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, t.Obj())
+	}
+	g := newSourceGenerator(opts)
+	for pkgPath, pkg := range s.Packages {
+		g.docs[pkgPath] = pkg.packageDocs()
+	}
+	return g.generate(roots)
+}
+
+// packageDocs returns the doc comments recorded for the package.
+func (pkg *SnapshotPackage) packageDocs() *packageDocs {
+	docs := &packageDocs{
+		types:      pkg.Docs,
+		fields:     pkg.FieldDocs,
+		labels:     pkg.Labels,
+		properties: pkg.Properties,
+		deprecated: make(map[string]bool, len(pkg.Deprecated)),
+	}
+	for _, name := range pkg.Deprecated {
+		docs.deprecated[name] = true
+	}
+	return docs
+}
+
+// snapshotBuilder rebuilds the types of a snapshot as go/types types.
+type snapshotBuilder struct {
+	snapshot *TypeSnapshot
+	packages map[string]*types.Package
+	named    map[string]*types.Named
+}
+
+// kindsByName maps the names of the reflect kinds to the kinds.
+var kindsByName = func() map[string]reflect.Kind {
+	kinds := make(map[string]reflect.Kind)
+	for k := reflect.Invalid; k <= reflect.UnsafePointer; k++ {
+		kinds[k.String()] = k
+	}
+	return kinds
+}()
+
+// lookup returns the named type name of the package pkgPath, building it
+// and its constants on first use.
+func (b *snapshotBuilder) lookup(pkgPath, name string) (*types.Named, error) {
+	key := pkgPath + "." + name
+	if t, ok := b.named[key]; ok {
+		return t, nil
+	}
+	var desc *SnapshotNamed
+	if pkg := b.snapshot.Packages[pkgPath]; pkg != nil {
+		desc = pkg.Types[name]
+	}
+	if desc == nil {
+		return nil, fmt.Errorf("Type %s is missing from the snapshot.", key)
+	}
+	pkg, ok := b.packages[pkgPath]
+	if !ok {
+		pkg = types.NewPackage(pkgPath, path.Base(pkgPath))
+		b.packages[pkgPath] = pkg
+	}
+	obj := types.NewTypeName(token.NoPos, pkg, name, nil)
+	t := types.NewNamed(obj, nil, nil)
+	pkg.Scope().Insert(obj)
+	b.named[key] = t
+	underlying, err := b.build(desc.Underlying)
+	if err != nil {
+		return nil, err
+	}
+	t.SetUnderlying(underlying.Underlying())
+	// The positions keep the declaration order of the constants.
+	for i, c := range desc.Constants {
+		pkg.Scope().Insert(types.NewConst(token.Pos(i+1), pkg, c.Name, t, constant.MakeString(c.Value)))
+	}
+	return t, nil
+}
+
+// build returns the go/types type described by desc.
+func (b *snapshotBuilder) build(desc SnapshotType) (types.Type, error) {
+	if len(desc.Name) > 0 && len(desc.PkgPath) > 0 {
+		return b.lookup(desc.PkgPath, desc.Name)
+	}
+	kind, ok := kindsByName[desc.Kind]
+	if !ok {
+		return nil, fmt.Errorf("Unknown kind %q in the snapshot.", desc.Kind)
+	}
+	for basic, k := range basicKinds {
+		if k == kind {
+			return types.Typ[basic], nil
+		}
+	}
+	switch kind {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		if desc.Elem == nil {
+			return nil, fmt.Errorf("The %s in the snapshot has no element type.", desc.Kind)
+		}
+		elem, err := b.build(*desc.Elem)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case reflect.Ptr:
+			return types.NewPointer(elem), nil
+		case reflect.Slice:
+			return types.NewSlice(elem), nil
+		}
+		return types.NewArray(elem, int64(desc.Len)), nil
+	case reflect.Map:
+		if desc.Key == nil || desc.Elem == nil {
+			return nil, fmt.Errorf("The map in the snapshot has no key or element type.")
+		}
+		key, err := b.build(*desc.Key)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := b.build(*desc.Elem)
+		if err != nil {
+			return nil, err
+		}
+		return types.NewMap(key, elem), nil
+	case reflect.Struct:
+		fields := make([]*types.Var, len(desc.Fields))
+		tags := make([]string, len(desc.Fields))
+		for i, f := range desc.Fields {
+			t, err := b.build(f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = types.NewField(token.NoPos, nil, f.Name, t, f.Embedded)
+			tags[i] = f.Tag
+		}
+		return types.NewStruct(fields, tags), nil
+	case reflect.Interface:
+		return types.NewInterfaceType(nil, nil).Complete(), nil
+	}
+	// Channels, functions and unsafe pointers have no JSON representation.
+	return types.Typ[types.Invalid], nil
+}
@@ -0,0 +1,70 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen/testdata/paritypkg"
+)
+
+// TestSnapshotParity checks that the schema regenerated from a snapshot
+// written to disk matches the one generated from the compiled types.
+func TestSnapshotParity(t *testing.T) {
+	root := reflect.TypeOf(paritypkg.Widget{})
+	snapshot, err := NewTypeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.Write(f); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTypeSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []Options{
+		{},
+		{DocComments: true, EnumConstants: true, Required: true},
+		{PatternProperties: true, Draft: Draft2020},
+		{Packages: []PackageDescriptor{{GoPackage: parityPkg, JavaPackage: "io.example.parity", Prefix: "parity_"}}},
+	} {
+		compiled, err := GenerateSchemaWithOptions(root, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		regenerated, err := GenerateSnapshotSchema(loaded, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.MarshalIndent(compiled, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.MarshalIndent(regenerated, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			diffs, _ := CompareJSON(want, got)
+			t.Errorf("%+v: the snapshot schema differs from the compiled one: %v", opts, diffs)
+		}
+	}
+}
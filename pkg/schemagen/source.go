@@ -0,0 +1,801 @@
+package schemagen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// GenerateSourceSchema generates the schema of the named types of the Go
+// package with the given import path from its sources, type-checked with
+// go/types, rather than from types compiled into the generator. The package
+// and its dependencies are located through go/build and type-checked with
+// the source importer of go/types, as golang.org/x/tools/go/packages is not
+// vendored. A single binary can thus describe several releases of an API,
+// e.g. by pointing GOPATH at each, and doc comments and constants are read
+// from the same sources. Several names make a combined schema like
+// GenerateSchemas.
+//
+// The source front-end honors package descriptors, json tags, tag options,
+// kind mappings, time.Time and big numbers, ByteArraysAsStrings, Required
+// and RequiredOverrides, DocComments, EnumConstants, Retention,
+// StandardOnly, JavaTypeKeyword and InlineReferences. The options and hooks
+// keyed by reflect.Type, such as TypeMap, Formats or type handlers, do not
+// apply.
+func GenerateSourceSchema(pkgPath string, names []string, opts Options) (*JSONSchema, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("At least one root type is required.")
+	}
+	fset := token.NewFileSet()
+	pkg, err := checkPackage(fset, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	return newSourceGenerator(opts).generatePackage(pkg, names)
+}
+
+func newSourceGenerator(opts Options) *sourceGenerator {
+	return &sourceGenerator{
+		schemaGenerator: newSchemaGenerator(opts),
+		defs:            make(map[*types.TypeName]*JSONPropertyDescriptor),
+		enums:           make(map[*types.TypeName]*enumType),
+	}
+}
+
+// generatePackage generates the schema of the named types of pkg.
+func (g *sourceGenerator) generatePackage(pkg *types.Package, names []string) (*JSONSchema, error) {
+	var roots []*types.TypeName
+	for _, name := range names {
+		obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() {
+			return nil, fmt.Errorf("%s is not an exported type of package %s.", name, pkg.Path())
+		}
+		roots = append(roots, obj)
+	}
+	return g.generate(roots)
+}
+
+// checkPackage parses and type-checks the package with the given import
+// path. Its imports are type-checked from their sources as well.
+func checkPackage(fset *token.FileSet, pkgPath string) (*types.Package, error) {
+	bp, err := build.Import(pkgPath, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, file := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(bp.Dir, file), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(bp.ImportPath, fset, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to type-check package %s: %v", pkgPath, err)
+	}
+	return pkg, nil
+}
+
+// sourceGenerator walks go/types types. It shares the settings, naming and
+// problem reporting of the reflect-based generator.
+type sourceGenerator struct {
+	*schemaGenerator
+	defs  map[*types.TypeName]*JSONPropertyDescriptor
+	enums map[*types.TypeName]*enumType
+
+	// anonymous names the anonymous struct types of fields, like the
+	// synthetic types of the reflect-based generator.
+	anonymous map[*types.Struct]syntheticType
+}
+
+// basicKinds are the kinds of the mappings of the basic types.
+var basicKinds = map[types.BasicKind]reflect.Kind{
+	types.Bool:       reflect.Bool,
+	types.Int:        reflect.Int,
+	types.Int8:       reflect.Int8,
+	types.Int16:      reflect.Int16,
+	types.Int32:      reflect.Int32,
+	types.Int64:      reflect.Int64,
+	types.Uint:       reflect.Uint,
+	types.Uint8:      reflect.Uint8,
+	types.Uint16:     reflect.Uint16,
+	types.Uint32:     reflect.Uint32,
+	types.Uint64:     reflect.Uint64,
+	types.Float32:    reflect.Float32,
+	types.Float64:    reflect.Float64,
+	types.Complex64:  reflect.Complex64,
+	types.Complex128: reflect.Complex128,
+	types.String:     reflect.String,
+}
+
+func (g *sourceGenerator) generate(roots []*types.TypeName) (*JSONSchema, error) {
+	name := "Schema"
+	var pkgDesc PackageDescriptor
+	if len(roots) == 1 {
+		name = roots[0].Name()
+		pkgDesc, _ = g.packageByPath(roots[0].Pkg().Path())
+	}
+	id, err := schemaID(g.opts, name, pkgDesc)
+	if err != nil {
+		return nil, err
+	}
+	s := JSONSchema{
+		ID:      id,
+		Schema:  schemaURI(g.opts),
+		Draft:   g.opts.Draft,
+		License: g.opts.License,
+		JSONDescriptor: JSONDescriptor{
+			Type: "object",
+		},
+	}
+	if len(roots) == 1 {
+		st, ok := roots[0].Type().Underlying().(*types.Struct)
+		if !ok {
+			return nil, fmt.Errorf("Root type %s is not a struct.", roots[0].Name())
+		}
+		if gvks := g.groupVersionKinds(g.typeName(roots[0]), pkgDesc, roots[0].Name()); gvks != nil {
+			s.GroupVersionKinds = gvks.GroupVersionKinds
+		}
+		s.JSONObjectDescriptor = g.objectDescriptor(roots[0], st)
+	} else {
+		s.JSONObjectDescriptor = &JSONObjectDescriptor{
+			Properties:           make(map[string]JSONPropertyDescriptor),
+			AdditionalProperties: true,
+		}
+		for _, root := range roots {
+			s.Properties[root.Name()] = g.descriptor(root.Type())
+		}
+	}
+	if err := g.generationError(); err != nil {
+		return nil, err
+	}
+	if len(g.defs) > 0 {
+		s.Definitions = make(map[string]JSONPropertyDescriptor)
+		for obj, def := range g.defs {
+			name := g.typeName(obj)
+			value := *def
+			pkgDesc, ok := g.packageByPath(obj.Pkg().Path())
+			if ok {
+				applyJavaClassHints(&value, pkgDesc)
+			}
+			value.GroupVersionKindDescriptor = g.groupVersionKinds(name, pkgDesc, obj.Name())
+			if value.JSONObjectDescriptor != nil {
+				g.applyTitle(&value, obj.Name())
+			}
+			s.Definitions[name] = value
+		}
+	}
+	g.checkFieldRules(&s)
+	g.applyRetention(&s)
+	if g.opts.StandardOnly {
+		mapSchema(&s, stripExtensions)
+	}
+	if len(g.opts.JavaTypeKeyword) > 0 && g.opts.JavaTypeKeyword != "javaType" {
+		mapSchema(&s, renameJavaTypes(g.opts.JavaTypeKeyword))
+	}
+	if g.opts.InlineReferences {
+		inlineReferences(&s)
+	}
+	s.Warnings = warningMessages(g.warnings)
+	return &s, nil
+}
+
+func (g *sourceGenerator) typeName(obj *types.TypeName) string {
+	return g.definitionName(obj.Pkg().Path(), obj.Name())
+}
+
+// elementDescriptor describes t, the type of the items or values of the
+// current field, like schemaGenerator.elementDescriptor.
+func (g *sourceGenerator) elementDescriptor(suffix string, t types.Type) JSONPropertyDescriptor {
+	return g.element(suffix, func() JSONPropertyDescriptor {
+		return g.descriptor(t)
+	})
+}
+
+// descriptor describes the values of t, like getPropertyDescriptor.
+func (g *sourceGenerator) descriptor(t types.Type) JSONPropertyDescriptor {
+	if isRawJSON(t) {
+		// Raw JSON, of any type.
+		return JSONPropertyDescriptor{}
+	}
+	switch t := types.Unalias(t).(type) {
+	case *types.Pointer:
+		return g.descriptor(t.Elem())
+	case *types.Named:
+		obj := t.Obj()
+		if desc, ok := g.formatDescriptor(obj); ok {
+			return desc
+		}
+		switch u := t.Underlying().(type) {
+		case *types.Struct:
+			return g.define(obj, func() JSONPropertyDescriptor {
+				def := JSONPropertyDescriptor{
+					JSONDescriptor: &JSONDescriptor{
+						Type: "object",
+					},
+					JSONObjectDescriptor: g.objectDescriptor(obj, u),
+					JavaTypeDescriptor: &JavaTypeDescriptor{
+						JavaType: g.javaType(t),
+					},
+				}
+				if g.opts.DocComments {
+					applyDescription(&def, g.pathDocs(obj.Pkg().Path()).types[obj.Name()])
+				}
+				return def
+			})
+		case *types.Basic:
+			desc := g.descriptor(u)
+			if e, ok := g.enum(obj); ok && desc.JSONDescriptor != nil {
+				desc.Enum = enumValues(e.values)
+				desc.JavaTypeDescriptor = &JavaTypeDescriptor{
+					JavaType: g.javaType(t),
+				}
+				applyEnumHints(&desc, e)
+			}
+			return desc
+		}
+		return g.descriptor(t.Underlying())
+	case *types.Basic:
+		if m, ok := g.kinds[basicKinds[t.Kind()]]; ok {
+			if desc, ok := g.kindDescriptors[basicKinds[t.Kind()]]; ok {
+				return desc
+			}
+			return mappingDescriptor(m)
+		}
+		if t.Info()&types.IsComplex != 0 {
+			g.problem(fmt.Sprintf("%s of type %s is a complex number, which encoding/json cannot marshal.", g.fieldPath(), t))
+			return JSONPropertyDescriptor{}
+		}
+	case *types.Array:
+		if g.opts.ByteArraysAsStrings && isByteType(t.Elem()) {
+			return JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type: "string",
+				},
+			}
+		}
+		length := int(t.Len())
+		return JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "array",
+			},
+			JSONArrayDescriptor: &JSONArrayDescriptor{
+				Items:    g.elementDescriptor("[]", t.Elem()),
+				MinItems: &length,
+				MaxItems: &length,
+			},
+		}
+	case *types.Slice:
+		if isByteType(t.Elem()) {
+			return JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Type:   "string",
+					Format: "byte",
+				},
+			}
+		}
+		return JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "array",
+			},
+			JSONArrayDescriptor: &JSONArrayDescriptor{
+				Items: g.elementDescriptor("[]", t.Elem()),
+			},
+		}
+	case *types.Map:
+		desc := JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "object",
+			},
+			JSONMapDescriptor: &JSONMapDescriptor{
+				MapValueType: g.elementDescriptor("{}", t.Elem()),
+			},
+			JavaTypeDescriptor: &JavaTypeDescriptor{
+				JavaType: g.javaType(t),
+			},
+		}
+		desc.PropertyNames = g.mapKeyNames(t)
+		if g.opts.PatternProperties {
+			patternKeys(&desc)
+		}
+		return desc
+	case *types.Struct:
+		return JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "object",
+			},
+			JSONObjectDescriptor: g.objectDescriptor(nil, t),
+			JavaTypeDescriptor: &JavaTypeDescriptor{
+				JavaType: g.javaType(t),
+			},
+		}
+	case *types.Interface:
+		return JSONPropertyDescriptor{}
+	}
+	g.warnf("%s of type %s has no JSON representation, described as a free-form value.", g.fieldPath(), t)
+	return JSONPropertyDescriptor{}
+}
+
+// define adds the definition of obj built by fn unless it is already
+// defined, like defineType, and returns a reference to it.
+func (g *sourceGenerator) define(obj *types.TypeName, fn func() JSONPropertyDescriptor) JSONPropertyDescriptor {
+	pkgDesc, _ := g.packageByPath(obj.Pkg().Path())
+	if _, ok := g.defs[obj]; !ok && len(pkgDesc.SchemaURL) == 0 {
+		g.defs[obj] = &JSONPropertyDescriptor{JSONObjectDescriptor: &JSONObjectDescriptor{}}
+		definition := fn()
+		g.defs[obj] = &definition
+	}
+	return JSONPropertyDescriptor{
+		JSONReferenceDescriptor: &JSONReferenceDescriptor{
+			Reference: pkgDesc.SchemaURL + definitionsPrefix + g.typeName(obj),
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: g.javaType(obj.Type()),
+		},
+	}
+}
+
+// formatDescriptor describes time.Time and its wrappers, big.Int,
+// big.Float and the types of Options.TypeFormats and DefaultTypeFormats
+// like the reflect-based generator.
+func (g *sourceGenerator) formatDescriptor(obj *types.TypeName) (JSONPropertyDescriptor, bool) {
+	if obj.Pkg() == nil {
+		return JSONPropertyDescriptor{}, false
+	}
+	key := obj.Pkg().Path() + "." + obj.Name()
+	if format, ok := g.typeFormat(key); ok {
+		return formatString(format), true
+	}
+	var t reflect.Type
+	switch key {
+	case "time.Time":
+		t = timeType
+	case "math/big.Int":
+		t = bigIntType
+	case "math/big.Float":
+		t = bigFloatType
+	default:
+		if !isSourceTimeWrapper(obj) {
+			return JSONPropertyDescriptor{}, false
+		}
+		t = timeType
+	}
+	return g.schemaGenerator.formatDescriptor(t)
+}
+
+// objectDescriptor describes the fields of st, declared by obj unless it
+// is an anonymous struct.
+func (g *sourceGenerator) objectDescriptor(obj *types.TypeName, st *types.Struct) *JSONObjectDescriptor {
+	desc := JSONObjectDescriptor{AdditionalProperties: true}
+	desc.Properties, desc.Required = g.properties(obj, st)
+	return &desc
+}
+
+// properties returns the properties of the fields of st and the sorted
+// names of the required ones. The fields of embedded and inlined structs
+// are flattened unless a field of st has the same name.
+func (g *sourceGenerator) properties(obj *types.TypeName, st *types.Struct) (map[string]JSONPropertyDescriptor, []string) {
+	props := make(map[string]JSONPropertyDescriptor)
+	required := make(map[string]bool)
+	embedded := make(map[string]JSONPropertyDescriptor)
+	embeddedRequired := make(map[string]bool)
+	// embeddedFrom maps the flattened properties to the fields embedding
+	// them, to report collisions.
+	embeddedFrom := make(map[string]string)
+	owner := ""
+	label, pkgPath := "", ""
+	if obj != nil {
+		owner = obj.Name()
+		label, pkgPath = owner, obj.Pkg().Path()
+	} else if outer, ok := g.anonymous[st]; ok {
+		label, pkgPath = outer.label, outer.pkgPath
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		field := reflect.StructField{
+			Name:      v.Name(),
+			Tag:       reflect.StructTag(st.Tag(i)),
+			Anonymous: v.Embedded(),
+		}
+		if !v.Exported() && !v.Embedded() || g.isIgnored(field) {
+			continue
+		}
+		name := g.getFieldName(field)
+		var rule FieldRule
+		if obj != nil && len(g.opts.FieldRules) > 0 {
+			rule = g.namedFieldRule(g.typeName(obj) + "." + name)
+		}
+		if rule.Exclude {
+			continue
+		}
+		if len(rule.Name) > 0 {
+			name = rule.Name
+		}
+		if inner, ok := structOf(v.Type()); ok && g.isInline(field) && !rule.Opaque {
+			var innerProps map[string]JSONPropertyDescriptor
+			var innerRequired []string
+			if named, ok := derefType(v.Type()).(*types.Named); ok {
+				// Embedded named structs are defined as well, like with
+				// reflection; their properties are walked again while
+				// their definition is being built.
+				g.path = append(g.path, owner+"."+v.Name())
+				g.descriptor(named)
+				g.path = g.path[:len(g.path)-1]
+				if def, ok := g.defs[named.Obj()]; ok && def.Properties != nil {
+					innerProps, innerRequired = def.Properties, def.Required
+				} else {
+					innerProps, innerRequired = g.properties(named.Obj(), inner)
+				}
+			} else {
+				innerProps, innerRequired = g.properties(nil, inner)
+			}
+			for k, p := range innerProps {
+				if other, ok := embeddedFrom[k]; ok {
+					g.warnf("%s embeds %s and %s which both have a field named %s.", owner, other, v.Name(), k)
+				}
+				embeddedFrom[k] = v.Name()
+				embedded[k] = p
+				embeddedRequired[k] = false
+			}
+			// The fields of a nil embedded pointer are all left out.
+			if _, ok := v.Type().(*types.Pointer); !ok {
+				for _, k := range innerRequired {
+					embeddedRequired[k] = true
+				}
+			}
+			continue
+		}
+		if !v.Exported() {
+			continue
+		}
+		if len(label) > 0 {
+			g.registerAnonymousStruct(v, label, pkgPath)
+		}
+		var prop JSONPropertyDescriptor
+		if rule.Opaque {
+			prop = prunedDescriptor()
+		} else {
+			g.path = append(g.path, owner+"."+name)
+			prop = g.descriptor(v.Type())
+			g.path = g.path[:len(g.path)-1]
+		}
+		if len(field.Tag.Get("default")) == 0 {
+			applyDocTags(&prop, field)
+		}
+		if g.hasJSONOption(field, "string") && !applyStringOption(&prop) {
+			g.warnf("%s.%s is tagged string but is not described as a number, a boolean or a string.", owner, v.Name())
+		}
+		g.applyTitle(&prop, v.Name())
+		if g.opts.DocComments && obj != nil {
+			applyDescription(&prop, g.pathDocs(obj.Pkg().Path()).fields[owner+"."+v.Name()])
+		}
+		if javaName := g.javaName(name); len(javaName) > 0 {
+			prop.JavaNameDescriptor = &JavaNameDescriptor{
+				JavaName: javaName,
+			}
+		}
+		optional := g.isOptionalField(v, field)
+		if optional {
+			if _, ok := v.Type().(*types.Pointer); ok {
+				prop = g.nullablePointer(prop)
+			}
+			prop.NullableDescriptor = g.nullableDescriptor()
+		}
+		props[name] = prop
+		required[name] = g.isRequiredField(obj, field, name, optional)
+	}
+	for k, p := range embedded {
+		if _, ok := props[k]; !ok {
+			props[k] = p
+			required[k] = embeddedRequired[k]
+		}
+	}
+	var requiredNames []string
+	for k, ok := range required {
+		if ok {
+			requiredNames = append(requiredNames, k)
+		}
+	}
+	sort.Strings(requiredNames)
+	return props, requiredNames
+}
+
+// isOptionalField tells whether the field v may be left out, like
+// isOptional.
+func (g *sourceGenerator) isOptionalField(v *types.Var, f reflect.StructField) bool {
+	if _, ok := v.Type().(*types.Pointer); ok {
+		return true
+	}
+	for _, effect := range g.tagOptions(f) {
+		if effect.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequiredField tells whether the field f of obj is required, like
+// isRequired.
+func (g *sourceGenerator) isRequiredField(obj *types.TypeName, f reflect.StructField, name string, optional bool) bool {
+	if obj != nil {
+		if required, ok := g.opts.RequiredOverrides[g.typeName(obj)+"."+name]; ok {
+			return required
+		}
+	}
+	switch f.Tag.Get("required") {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return g.opts.Required && !optional
+}
+
+// enum returns the values of the string type obj, read from the exported
+// constants of its package with Options.EnumConstants. Unlike the parsed
+// doc comments, type-checking also evaluates constants whose values are
+// computed, e.g. Prefix + "Pending".
+func (g *sourceGenerator) enum(obj *types.TypeName) (*enumType, bool) {
+	if e, ok := g.enums[obj]; ok {
+		return e, e != nil
+	}
+	var e *enumType
+	basic, isBasic := obj.Type().Underlying().(*types.Basic)
+	if g.opts.EnumConstants && isBasic && basic.Kind() == types.String && obj.Pkg() != nil {
+		var constants constantsByPos
+		scope := obj.Pkg().Scope()
+		for _, name := range scope.Names() {
+			c, ok := scope.Lookup(name).(*types.Const)
+			if ok && c.Exported() && types.Identical(c.Type(), obj.Type()) && c.Val().Kind() == constant.String {
+				constants = append(constants, c)
+			}
+		}
+		// Declaration order, as with doc comments.
+		sort.Sort(constants)
+		deprecated := g.pathDocs(obj.Pkg().Path()).deprecated
+		for _, c := range constants {
+			if e == nil {
+				e = &enumType{}
+			}
+			value := constant.StringVal(c.Val())
+			e.values = append(e.values, value)
+			e.names = append(e.names, c.Name())
+			if deprecated[c.Name()] {
+				e.deprecated = append(e.deprecated, value)
+			}
+		}
+	}
+	g.enums[obj] = e
+	return e, e != nil
+}
+
+type constantsByPos []*types.Const
+
+func (c constantsByPos) Len() int           { return len(c) }
+func (c constantsByPos) Less(i, j int) bool { return c[i].Pos() < c[j].Pos() }
+func (c constantsByPos) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// javaType returns the Java type of t, like the reflect-based javaType.
+func (g *sourceGenerator) javaType(t types.Type) string {
+	for p, ok := types.Unalias(t).(*types.Pointer); ok; p, ok = types.Unalias(t).(*types.Pointer) {
+		t = p.Elem()
+	}
+	if isRawJSON(t) {
+		return "Object"
+	}
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if desc, ok := g.formatDescriptor(obj); ok {
+			if desc.JavaTypeDescriptor != nil {
+				return desc.JavaType
+			}
+			return "String"
+		}
+		pkgDesc, known := g.packageByPath(obj.Pkg().Path())
+		switch t.Underlying().(type) {
+		case *types.Struct:
+			if known {
+				return pkgDesc.JavaPackage + "." + obj.Name()
+			}
+			return obj.Name()
+		case *types.Basic:
+			if _, ok := g.enum(obj); ok && known {
+				return pkgDesc.JavaPackage + "." + obj.Name()
+			}
+		}
+		return g.javaType(t.Underlying())
+	case *types.Basic:
+		kind := basicKinds[t.Kind()]
+		if m, ok := g.kinds[kind]; ok {
+			if desc, ok := g.kindDescriptors[kind]; ok && desc.JavaTypeDescriptor != nil {
+				return desc.JavaType
+			}
+			return m.JavaType
+		}
+	case *types.Array:
+		if g.opts.ByteArraysAsStrings && isByteType(t.Elem()) {
+			return "String"
+		}
+		return "java.util.ArrayList<" + typeArgument(g.javaType(t.Elem())) + ">"
+	case *types.Slice:
+		if isByteType(t.Elem()) {
+			return "String"
+		}
+		return "java.util.ArrayList<" + typeArgument(g.javaType(t.Elem())) + ">"
+	case *types.Map:
+		return "java.util.Map<" + g.mapKeyJavaType(t) + "," + typeArgument(g.javaType(t.Elem())) + ">"
+	case *types.Struct:
+		if synthetic, ok := g.anonymous[t]; ok {
+			return synthetic.javaType
+		}
+	}
+	return "Object"
+}
+
+// mapKeyJavaType returns the Java type of the keys of the map type t, like
+// the reflect-based mapKeyJavaType.
+func (g *sourceGenerator) mapKeyJavaType(t *types.Map) string {
+	key := t.Key()
+	if named, ok := types.Unalias(key).(*types.Named); ok {
+		if _, ok := g.enum(named.Obj()); ok {
+			return g.javaType(named)
+		}
+	}
+	if !isSourceTextMarshaler(key) && isSourceInteger(key) {
+		return typeArgument(g.javaType(key))
+	}
+	return "String"
+}
+
+// mapKeyNames returns the constraint on the property names of the map type
+// t, if any, like the reflect-based mapKeyNames.
+func (g *sourceGenerator) mapKeyNames(t *types.Map) *JSONPropertyDescriptor {
+	key := t.Key()
+	if named, ok := types.Unalias(key).(*types.Named); ok {
+		if e, ok := g.enum(named.Obj()); ok {
+			return &JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{
+					Enum: enumValues(e.values),
+				},
+			}
+		}
+	}
+	basic, _ := key.Underlying().(*types.Basic)
+	switch {
+	case basic != nil && basic.Info()&types.IsString != 0 || isSourceTextMarshaler(key):
+		return nil
+	case isSourceInteger(key):
+		pattern := "^-?[0-9]+$"
+		if basic.Info()&types.IsUnsigned != 0 {
+			pattern = "^[0-9]+$"
+		}
+		return &JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Pattern: pattern,
+			},
+		}
+	}
+	g.warnf("%s of type %s has keys of type %s, which encoding/json cannot marshal.", g.fieldPath(), t, key)
+	return nil
+}
+
+// isSourceInteger tells whether t is an integer type.
+func isSourceInteger(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+// isSourceTextMarshaler tells whether values of t have a MarshalText
+// method, like the types implementing encoding.TextMarshaler.
+func isSourceTextMarshaler(t types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, "MarshalText")
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// registerAnonymousStruct names the anonymous struct type of the field v,
+// if any, after label, the label of the struct declaring v, in the package
+// pkgPath, like the reflect-based registerAnonymousStruct.
+func (g *sourceGenerator) registerAnonymousStruct(v *types.Var, label, pkgPath string) {
+	t := types.Type(v.Type())
+	for {
+		switch u := derefType(t).(type) {
+		case *types.Slice:
+			t = u.Elem()
+			continue
+		case *types.Array:
+			t = u.Elem()
+			continue
+		case *types.Map:
+			t = u.Elem()
+			continue
+		}
+		break
+	}
+	st, ok := derefType(t).(*types.Struct)
+	if !ok || st.NumFields() == 0 {
+		return
+	}
+	if _, ok := g.anonymous[st]; ok {
+		return
+	}
+	if g.anonymous == nil {
+		g.anonymous = make(map[*types.Struct]syntheticType)
+	}
+	label += v.Name()
+	synthetic := syntheticType{
+		name:     g.packageDefinitionName(pkgPath, label),
+		javaType: label,
+		label:    label,
+		pkgPath:  pkgPath,
+	}
+	if pkgDesc, ok := g.packageByPath(pkgPath); ok {
+		synthetic.javaType = pkgDesc.JavaPackage + "." + label
+	}
+	g.anonymous[st] = synthetic
+}
+
+// derefType strips the pointers and aliases of t.
+// isRawJSON tells whether t is json.RawMessage, which is an alias of
+// jsontext.Value with the jsonv2 experiment.
+func isRawJSON(t types.Type) bool {
+	var obj *types.TypeName
+	switch t := t.(type) {
+	case *types.Alias:
+		obj = t.Obj()
+	case *types.Named:
+		obj = t.Obj()
+	}
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	switch obj.Pkg().Path() + "." + obj.Name() {
+	case "encoding/json.RawMessage", "encoding/json/jsontext.Value":
+		return true
+	}
+	return false
+}
+
+// isSourceTimeWrapper tells whether obj is a struct whose only field is an
+// embedded time.Time, like isTimeWrapper.
+func isSourceTimeWrapper(obj *types.TypeName) bool {
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok || st.NumFields() != 1 || !st.Field(0).Embedded() {
+		return false
+	}
+	named, ok := types.Unalias(st.Field(0).Type()).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time"
+}
+
+func derefType(t types.Type) types.Type {
+	for {
+		t = types.Unalias(t)
+		p, ok := t.(*types.Pointer)
+		if !ok {
+			return t
+		}
+		t = p.Elem()
+	}
+}
+
+// structOf returns the struct type of t, or of the type t points to.
+func structOf(t types.Type) (*types.Struct, bool) {
+	st, ok := derefType(t).Underlying().(*types.Struct)
+	return st, ok
+}
+
+func isByteType(t types.Type) bool {
+	b, ok := types.Unalias(t).(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
@@ -0,0 +1,43 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen/testdata/paritypkg"
+)
+
+const parityPkg = "github.com/csrwng/origin-schema-generator/pkg/schemagen/testdata/paritypkg"
+
+// TestSourceParity checks that the source front-end describes a package
+// like the reflect-based generator describes its compiled types.
+func TestSourceParity(t *testing.T) {
+	for _, opts := range []Options{
+		{},
+		{DocComments: true, EnumConstants: true, Required: true},
+		{PatternProperties: true, Draft: Draft2020},
+		{Packages: []PackageDescriptor{{GoPackage: parityPkg, JavaPackage: "io.example.parity", Prefix: "parity_"}}},
+	} {
+		compiled, err := GenerateSchemaWithOptions(reflect.TypeOf(paritypkg.Widget{}), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		source, err := GenerateSourceSchema(parityPkg, []string{"Widget"}, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := json.MarshalIndent(compiled, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.MarshalIndent(source, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			diffs, _ := CompareJSON(want, got)
+			t.Errorf("%+v: the source schema differs from the compiled one: %v", opts, diffs)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+const definitionsPrefix = schemamodel.DefinitionsPrefix
+
+// GenerateSplitSchema generates the schema for t and splits its definitions
+// into one document per API group/version, named "<group>.<version>.json"
+// after the Group and Version of the PackageDescriptor defining each type.
+// The returned map also holds, under indexName, an index schema with the
+// root properties and a definition for every type that refers to the
+// document holding it. Types from packages without a version are kept in
+// the index itself.
+func GenerateSplitSchema(t reflect.Type, opts Options, indexName string) (map[string]*JSONSchema, error) {
+	g := newSchemaGenerator(opts)
+	s, err := g.generate(t)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Audit {
+		if err := audit(t, opts, s); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make(map[string]string)
+	groups := make(map[string]PackageDescriptor)
+	for k := range g.types {
+		name := g.qualifiedName(k)
+		if renamed, ok := g.renamed[name]; ok {
+			name = renamed
+		}
+		// Nested definitions travel with their top level definition.
+		if _, ok := g.nested[name]; ok {
+			continue
+		}
+		file := g.groupFile(k)
+		// Identical definitions merged in ContentAddressed mode may come
+		// from several groups; the first file by name, the index being
+		// first, keeps them.
+		if other, ok := files[name]; ok && other <= file {
+			continue
+		}
+		files[name] = file
+		if len(file) > 0 {
+			groups[file], _ = g.packageDescriptor(k)
+		}
+	}
+
+	result := map[string]*JSONSchema{}
+	index := *s
+	index.Definitions = make(map[string]JSONPropertyDescriptor)
+	result[indexName] = &index
+	for name, def := range s.Definitions {
+		file := files[name]
+		if len(file) == 0 {
+			index.Definitions[name] = rewriteRefs(def, func(ref string) string {
+				return relocateRef(ref, "", files, indexName)
+			})
+			continue
+		}
+		doc, ok := result[file]
+		if !ok {
+			id, err := schemaID(opts, strings.TrimSuffix(file, ".json"), groups[file])
+			if err != nil {
+				return nil, err
+			}
+			doc = &JSONSchema{
+				ID:        id,
+				Schema:    s.Schema,
+				Draft:     s.Draft,
+				License:   s.License,
+				Generated: s.Generated,
+				JSONDescriptor: JSONDescriptor{
+					Type: "object",
+				},
+				Definitions: make(map[string]JSONPropertyDescriptor),
+			}
+			result[file] = doc
+		}
+		doc.Definitions[name] = def
+		index.Definitions[name] = JSONPropertyDescriptor{
+			JSONReferenceDescriptor: &JSONReferenceDescriptor{
+				Reference: file + definitionsPrefix + name,
+			},
+		}
+	}
+	for file, doc := range result {
+		if file == indexName {
+			continue
+		}
+		RewriteRefs(doc, func(ref string) string {
+			return relocateRef(ref, file, files, indexName)
+		})
+	}
+	return result, nil
+}
+
+// groupFile returns the name of the document holding the definition of t,
+// or an empty string if its package has no API version.
+func (g *schemaGenerator) groupFile(t reflect.Type) string {
+	pkgDesc, ok := g.packageDescriptor(t)
+	if !ok || len(pkgDesc.Version) == 0 {
+		return ""
+	}
+	group := pkgDesc.Group
+	if len(group) == 0 {
+		group = "core"
+	}
+	return group + "." + pkgDesc.Version + ".json"
+}
+
+// relocateRef rewrites a local definition reference found in document from
+// so that it points to the document holding the definition, which is the
+// one of the top level definition for nested definitions.
+func relocateRef(ref, from string, files map[string]string, indexName string) string {
+	if !strings.HasPrefix(ref, definitionsPrefix) {
+		return ref
+	}
+	name := strings.TrimPrefix(ref, definitionsPrefix)
+	if i := strings.Index(name, "/"); i >= 0 {
+		name = name[:i]
+	}
+	to := files[name]
+	if to == from {
+		return ref
+	}
+	if len(to) == 0 {
+		to = indexName
+	}
+	return to + ref
+}
@@ -0,0 +1,122 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemagen/testdata/paritypkg"
+)
+
+type splitRoot struct {
+	Widget paritypkg.Widget `json:"widget"`
+	Local  splitLocal       `json:"local"`
+}
+
+type splitLocal struct {
+	Name  string      `json:"name"`
+	Child *splitChild `json:"child,omitempty"`
+}
+
+type splitChild struct {
+	Value int64 `json:"value"`
+}
+
+// TestSplitSchemaRenamed checks that the definitions renamed by
+// ContentAddressed and moved by NestDefinitions are split into the
+// documents of their groups and that every reference resolves.
+func TestSplitSchemaRenamed(t *testing.T) {
+	packages := []PackageDescriptor{
+		{GoPackage: "github.com/csrwng/origin-schema-generator/pkg/schemagen", Group: "x.io", Version: "v1"},
+		{GoPackage: parityPkg, Group: "example.io", Version: "v1"},
+	}
+	for _, test := range []struct {
+		name string
+		opts Options
+	}{
+		{"plain", Options{Packages: packages}},
+		{"contentAddressed", Options{Packages: packages, ContentAddressed: true}},
+		{"nested", Options{Packages: packages, NestDefinitions: true}},
+		{"both", Options{Packages: packages, ContentAddressed: true, NestDefinitions: true}},
+	} {
+		schemas, err := GenerateSplitSchema(reflect.TypeOf(splitRoot{}), test.opts, "index.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range []string{"index.json", "x.io.v1.json", "example.io.v1.json"} {
+			if schemas[file] == nil || len(schemas[file].Definitions) == 0 {
+				t.Errorf("%s: no definitions in %s", test.name, file)
+			}
+		}
+		for name, def := range schemas["index.json"].Definitions {
+			if def.JSONReferenceDescriptor == nil || !strings.Contains(def.Reference, ".json#") {
+				t.Errorf("%s: the index defines %s instead of referring to it", test.name, name)
+			}
+		}
+
+		docs := make(map[string]interface{})
+		for file, s := range schemas {
+			b, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc interface{}
+			if err := json.Unmarshal(b, &doc); err != nil {
+				t.Fatal(err)
+			}
+			docs[file] = doc
+		}
+		for file, doc := range docs {
+			for _, ref := range collectRefs(doc) {
+				if !resolveSplitRef(docs, file, ref) {
+					t.Errorf("%s: %s refers to %s, which does not resolve", test.name, file, ref)
+				}
+			}
+		}
+	}
+}
+
+func collectRefs(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if ref, ok := e.(string); ok && k == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, collectRefs(e)...)
+		}
+	case []interface{}:
+		for _, e := range v {
+			refs = append(refs, collectRefs(e)...)
+		}
+	}
+	return refs
+}
+
+func resolveSplitRef(docs map[string]interface{}, from, ref string) bool {
+	i := strings.Index(ref, "#")
+	if i < 0 {
+		return false
+	}
+	file := ref[:i]
+	if len(file) == 0 {
+		file = from
+	}
+	v, ok := docs[file]
+	if !ok {
+		return false
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(ref[i+1:], "/"), "/") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if v, ok = m[token]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package schemagen
+
+import (
+	"os"
+	"runtime"
+	"time"
+)
+
+// BuildMode selects whether generated schemas carry build metadata.
+type BuildMode int
+
+const (
+	// Deterministic leaves out every timestamp and environment derived
+	// data, so that generating twice yields bit for bit identical
+	// artifacts, e.g. for release signing.
+	Deterministic BuildMode = iota
+	// Stamped records when, where and with which Go version the schema
+	// was generated in an x-generated extension, for development builds.
+	Stamped
+)
+
+func newGenerationStamp() *GenerationStamp {
+	host, _ := os.Hostname()
+	return &GenerationStamp{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		Host:      host,
+		GoVersion: runtime.Version(),
+	}
+}
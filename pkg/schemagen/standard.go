@@ -0,0 +1,21 @@
+package schemagen
+
+// stripExtensions removes the javaType hints and every vendor extension
+// from p, leaving only standard JSON Schema keywords.
+func stripExtensions(p JSONPropertyDescriptor) JSONPropertyDescriptor {
+	p.JavaTypeDescriptor = nil
+	p.JavaAnnotationsDescriptor = nil
+	p.JavaHintsDescriptor = nil
+	p.JavaNameDescriptor = nil
+	p.FormatHintDescriptor = nil
+	p.NullableDescriptor = nil
+	p.EmbeddedResourceDescriptor = nil
+	p.AbstractDescriptor = nil
+	p.GroupVersionKindDescriptor = nil
+	p.GoPackageDescriptor = nil
+	p.ProtobufDescriptor = nil
+	p.DeprecatedValuesDescriptor = nil
+	p.DebugDescriptor = nil
+	p.Extensions = nil
+	return p
+}
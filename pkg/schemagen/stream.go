@@ -0,0 +1,158 @@
+package schemagen
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+)
+
+// StreamingJSONEmitter writes the schema like JSONSchemaEmitter, but
+// serializes and writes one definition at a time instead of building the
+// whole serialized document in memory. The schema itself must already be
+// generated. Definitions are written in name order. Schemas of drafts other
+// than draft-04 and schemas with components are serialized at once, like
+// JSONSchemaEmitter does.
+type StreamingJSONEmitter struct {
+	Indent string
+}
+
+func (e StreamingJSONEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	return e.stream(schema, w, false)
+}
+
+// WriteSchema generates the schema of t and writes it to w like
+// StreamingJSONEmitter, indented by indent if it is not empty. Generation
+// is not incremental: every definition is built in memory first, as
+// overrides, policies and retention apply to all of them. Only the
+// serialization is; since the schema is not returned, each definition is
+// released once written, so that the descriptors and their serialized form
+// are not both held in memory for the whole document.
+func WriteSchema(w io.Writer, t reflect.Type, opts Options, indent string) error {
+	schema, err := GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return err
+	}
+	return StreamingJSONEmitter{Indent: indent}.stream(schema, w, true)
+}
+
+// stream writes schema, deleting its definitions once written if release
+// is set.
+func (e StreamingJSONEmitter) stream(schema *JSONSchema, w io.Writer, release bool) error {
+	if schema.Draft != Draft04 || schema.Components != nil {
+		// Later drafts are converted from the whole draft-04 document.
+		return JSONSchemaEmitter{Indent: e.Indent}.Emit(schema, w)
+	}
+	out := bufio.NewWriter(w)
+	nl, in1, in2 := "", "", ""
+	if len(e.Indent) > 0 {
+		nl, in1, in2 = "\n", e.Indent, e.Indent+e.Indent
+	}
+	marshal := func(v interface{}, prefix string) ([]byte, error) {
+		if len(e.Indent) > 0 {
+			return json.MarshalIndent(v, prefix, e.Indent)
+		}
+		return json.Marshal(v)
+	}
+	field := func(name string, v interface{}) error {
+		b, err := marshal(v, in1)
+		if err != nil {
+			return err
+		}
+		out.WriteString(in1)
+		out.Write(mustQuote(name))
+		out.WriteString(":")
+		if len(nl) > 0 {
+			out.WriteString(" ")
+		}
+		out.Write(b)
+		out.WriteString("," + nl)
+		return nil
+	}
+
+	out.WriteString("{" + nl)
+	if err := field("id", schema.ID); err != nil {
+		return err
+	}
+	if err := field("$schema", schema.Schema); err != nil {
+		return err
+	}
+	if len(schema.Description) > 0 {
+		if err := field("description", schema.Description); err != nil {
+			return err
+		}
+	}
+	if len(schema.License) > 0 {
+		if err := field("x-license", schema.License); err != nil {
+			return err
+		}
+	}
+	if schema.Generated != nil {
+		if err := field("x-generated", schema.Generated); err != nil {
+			return err
+		}
+	}
+	if len(schema.GroupVersionKinds) > 0 {
+		if err := field("x-kubernetes-group-version-kind", schema.GroupVersionKinds); err != nil {
+			return err
+		}
+	}
+
+	out.WriteString(in1 + `"definitions":`)
+	if len(nl) > 0 {
+		out.WriteString(" ")
+	}
+	if schema.Definitions == nil {
+		out.WriteString("null")
+	} else {
+		out.WriteString("{" + nl)
+		for i, name := range sortedDefinitionNames(schema.Definitions) {
+			if i > 0 {
+				out.WriteString("," + nl)
+			}
+			b, err := marshal(schema.Definitions[name], in2)
+			if err != nil {
+				return err
+			}
+			out.WriteString(in2)
+			out.Write(mustQuote(name))
+			out.WriteString(":")
+			if len(nl) > 0 {
+				out.WriteString(" ")
+			}
+			out.Write(b)
+			if release {
+				delete(schema.Definitions, name)
+			}
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+		out.WriteString(nl + in1 + "}")
+	}
+
+	// The remaining root keywords are written from a copy of the schema
+	// without the fields already written.
+	root := struct {
+		JSONDescriptor
+		*JSONObjectDescriptor
+	}{schema.JSONDescriptor, schema.JSONObjectDescriptor}
+	root.Description = ""
+	b, err := marshal(root, "")
+	if err != nil {
+		return err
+	}
+	if len(b) > 2 {
+		out.WriteString(",")
+		out.Write(b[1 : len(b)-1])
+	} else {
+		out.WriteString(nl)
+	}
+	out.WriteString("}" + nl)
+	return out.Flush()
+}
+
+func mustQuote(s string) []byte {
+	b, _ := json.Marshal(s)
+	return b
+}
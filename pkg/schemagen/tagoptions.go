@@ -0,0 +1,85 @@
+package schemagen
+
+import "reflect"
+
+// TagOption is the effect of an option of the json struct tag, such as
+// omitempty, on the property describing the field.
+type TagOption struct {
+	// Optional marks the field optional, like a pointer field.
+	Optional bool `yaml:"optional"`
+	// Inline flattens the properties of a struct field into the struct
+	// holding it, like an embedded struct.
+	Inline bool `yaml:"inline"`
+	// Extensions are added to the property as vendor extension keywords.
+	Extensions map[string]interface{} `yaml:"extensions"`
+}
+
+// DefaultTagOptions are the effects of the json tag options understood by
+// encoding/json and its common alternatives.
+var DefaultTagOptions = map[string]TagOption{
+	"omitempty": {Optional: true},
+	"omitzero":  {Optional: true},
+	"inline":    {Inline: true},
+	"flatten":   {Inline: true},
+	"squash":    {Inline: true},
+}
+
+// tagOptions returns the effects of the json tag options of f, looked up in
+// Options.TagOptions and then in DefaultTagOptions. Unknown options have no
+// effect.
+func (g *schemaGenerator) tagOptions(f reflect.StructField) []TagOption {
+	var effects []TagOption
+	for _, name := range g.jsonOptions(f) {
+		if effect, ok := g.opts.TagOptions[name]; ok {
+			effects = append(effects, effect)
+		} else if effect, ok := DefaultTagOptions[name]; ok {
+			effects = append(effects, effect)
+		}
+	}
+	return effects
+}
+
+// isOptional tells whether f may be left out of the JSON object holding it:
+// pointers and fields with an optional tag option, such as omitempty.
+func (g *schemaGenerator) isOptional(f reflect.StructField) bool {
+	if f.Type.Kind() == reflect.Ptr {
+		return true
+	}
+	for _, effect := range g.tagOptions(f) {
+		if effect.Optional {
+			return true
+		}
+	}
+	return false
+}
+
+// isInline tells whether the properties of the struct field f are
+// flattened into its parent.
+func (g *schemaGenerator) isInline(f reflect.StructField) bool {
+	if f.Anonymous {
+		return true
+	}
+	for _, effect := range g.tagOptions(f) {
+		if effect.Inline {
+			return true
+		}
+	}
+	return false
+}
+
+// applyTagOptions adds the extensions of the tag options of f to prop.
+func (g *schemaGenerator) applyTagOptions(prop *JSONPropertyDescriptor, f reflect.StructField) {
+	for _, effect := range g.tagOptions(f) {
+		if len(effect.Extensions) == 0 {
+			continue
+		}
+		extensions := make(map[string]interface{}, len(prop.Extensions)+len(effect.Extensions))
+		for k, v := range prop.Extensions {
+			extensions[k] = v
+		}
+		for k, v := range effect.Extensions {
+			extensions[k] = normalizeYAML(v)
+		}
+		prop.Extensions = extensions
+	}
+}
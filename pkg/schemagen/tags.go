@@ -0,0 +1,410 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// schemagenTag holds the options of a `schemagen:"..."` struct tag. Options
+// are comma separated and either bare flags (stored with an empty value) or
+// key=value pairs. Commas within brackets, braces or parentheses, or
+// escaped with a backslash, do not separate options, so that regular
+// expressions such as ^[a-z]{1,63}$ can be given as values.
+type schemagenTag map[string]string
+
+func getSchemagenTag(f reflect.StructField) schemagenTag {
+	tag := schemagenTag{}
+	value := f.Tag.Get("schemagen")
+	if len(value) == 0 {
+		return tag
+	}
+	for _, opt := range splitTagOptions(value) {
+		opt = strings.TrimSpace(opt)
+		if len(opt) == 0 {
+			continue
+		}
+		parts := strings.SplitN(opt, "=", 2)
+		if len(parts) == 2 {
+			tag[parts[0]] = parts[1]
+		} else {
+			tag[parts[0]] = ""
+		}
+	}
+	return tag
+}
+
+// splitTagOptions splits the value of a schemagen tag on the commas outside
+// brackets, braces and parentheses. Escaped characters are kept as written.
+func splitTagOptions(value string) []string {
+	var opts []string
+	start, depth, class := 0, 0, false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			i++
+		case class:
+			class = c != ']'
+		case c == '[':
+			class = true
+		case c == '(' || c == '{':
+			depth++
+		case (c == ')' || c == '}') && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			opts = append(opts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(opts, value[start:])
+}
+
+// Options of the schemagen tag whose values are checked by checkTagValues.
+var (
+	intTagOptions     = []string{"minLength", "maxLength", "minProperties", "maxProperties", "keyMaxLength"}
+	floatTagOptions   = []string{"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"}
+	patternTagOptions = []string{"pattern", "keyPattern", "stringForm"}
+)
+
+// checkTagValues reports the numeric options of the tag of field that are
+// not numbers and the patterns that are not regular expressions, which
+// are otherwise left out or emitted as is.
+func (g *schemaGenerator) checkTagValues(t reflect.Type, field reflect.StructField, tag schemagenTag) {
+	for _, name := range intTagOptions {
+		if value, ok := tag[name]; ok {
+			if _, err := strconv.Atoi(value); err != nil {
+				g.warnf("%s.%s is tagged with %s=%s, which is not an integer.", t.Name(), field.Name, name, value)
+			}
+		}
+	}
+	for _, name := range floatTagOptions {
+		if value, ok := tag[name]; ok {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				g.warnf("%s.%s is tagged with %s=%s, which is not a number.", t.Name(), field.Name, name, value)
+			}
+		}
+	}
+	for _, name := range patternTagOptions {
+		if value := tag[name]; len(value) > 0 {
+			if _, err := regexp.Compile(value); err != nil {
+				g.warnf("%s.%s is tagged with %s=%s, which is not a regular expression: %v", t.Name(), field.Name, name, value, err)
+			}
+		}
+	}
+}
+
+// valueTypes are the names accepted by the valueType tag option.
+var valueTypes = map[string]reflect.Type{
+	"string":  reflect.TypeOf(""),
+	"bool":    reflect.TypeOf(false),
+	"boolean": reflect.TypeOf(false),
+	"int":     reflect.TypeOf(int(0)),
+	"integer": reflect.TypeOf(int(0)),
+	"int64":   reflect.TypeOf(int64(0)),
+	"float":   reflect.TypeOf(float64(0)),
+	"number":  reflect.TypeOf(float64(0)),
+}
+
+// fieldType returns the type used to describe a field, applying the
+// valueType tag option to map[string]interface{} fields so homogeneous maps
+// such as labels can be emitted with a concrete value schema.
+func fieldType(f reflect.StructField, tag schemagenTag) reflect.Type {
+	t := f.Type
+	name, ok := tag["valueType"]
+	if !ok || t.Kind() != reflect.Map || t.Elem().Kind() != reflect.Interface {
+		return t
+	}
+	vt, ok := valueTypes[name]
+	if !ok {
+		return t
+	}
+	return reflect.MapOf(t.Key(), vt)
+}
+
+// intOption returns the integer value of the named option, if set.
+func (tag schemagenTag) intOption(name string) *int {
+	value, ok := tag[name]
+	if !ok {
+		return nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+// floatOption returns the numeric value of the named option, if set.
+func (tag schemagenTag) floatOption(name string) *float64 {
+	value, ok := tag[name]
+	if !ok {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+// applyValidationTag sets the constraints declared by the minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, minLength, maxLength, pattern, format
+// and enum options of a field, e.g. schemagen:"minimum=1,maximum=65535".
+// Enum values are separated by | and typed after the field, e.g.
+// schemagen:"enum=Always|OnFailure|Never". An empty format leaves the
+// format of the field unset.
+func applyValidationTag(prop *JSONPropertyDescriptor, f reflect.StructField, tag schemagenTag) {
+	minimum, maximum := tag.floatOption("minimum"), tag.floatOption("maximum")
+	exclusiveMinimum, exclusiveMaximum := false, false
+	if bound := tag.floatOption("exclusiveMinimum"); bound != nil {
+		minimum, exclusiveMinimum = bound, true
+	}
+	if bound := tag.floatOption("exclusiveMaximum"); bound != nil {
+		maximum, exclusiveMaximum = bound, true
+	}
+	minLength, maxLength := tag.intOption("minLength"), tag.intOption("maxLength")
+	pattern, hasPattern := tag["pattern"]
+	format, hasFormat := tag["format"]
+	enum, hasEnum := tag["enum"]
+	if minimum == nil && maximum == nil && minLength == nil && maxLength == nil && !hasPattern && !hasFormat && !hasEnum {
+		return
+	}
+	desc := JSONDescriptor{}
+	if prop.JSONDescriptor != nil {
+		desc = *prop.JSONDescriptor
+	}
+	if minimum != nil {
+		desc.Minimum, desc.ExclusiveMinimum = minimum, exclusiveMinimum
+	}
+	if maximum != nil {
+		desc.Maximum, desc.ExclusiveMaximum = maximum, exclusiveMaximum
+	}
+	if minLength != nil {
+		desc.MinLength = minLength
+	}
+	if maxLength != nil {
+		desc.MaxLength = maxLength
+	}
+	if hasPattern {
+		desc.Pattern = pattern
+	}
+	if hasFormat {
+		desc.Format = format
+	}
+	if hasEnum {
+		desc.Enum = nil
+		for _, value := range strings.Split(enum, "|") {
+			desc.Enum = append(desc.Enum, exampleValue(f.Type, value))
+		}
+	}
+	prop.JSONDescriptor = &desc
+}
+
+// keyPresets are the key rules named by the keys option of map fields.
+var keyPresets = map[string]JSONDescriptor{
+	// Kubernetes label and annotation keys: a name of at most 63
+	// characters, optionally prefixed by a DNS subdomain and a slash.
+	"qualifiedName": {
+		Pattern:   `^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`,
+		MaxLength: intPointer(253 + 1 + 63),
+	},
+}
+
+func intPointer(i int) *int {
+	return &i
+}
+
+// applyMapTag sets the size limits declared by the minProperties and
+// maxProperties options on a map property, and the limits of its keys
+// declared by the keyPattern, keyMaxLength and keys options, the latter
+// naming one of keyPresets. The closed option makes the map strict
+// regardless of the profile: its keys must be non-empty. It returns false
+// if a closed map does not limit its number of entries.
+func applyMapTag(prop *JSONPropertyDescriptor, tag schemagenTag) bool {
+	if prop.JSONMapDescriptor == nil {
+		return true
+	}
+	prop.MinProperties = tag.intOption("minProperties")
+	prop.MaxProperties = tag.intOption("maxProperties")
+	_, closed := tag["closed"]
+	pattern, hasPattern := tag["keyPattern"]
+	maxLength := tag.intOption("keyMaxLength")
+	preset, hasPreset := keyPresets[tag["keys"]]
+	if !closed && !hasPattern && maxLength == nil && !hasPreset {
+		return true
+	}
+	names := JSONPropertyDescriptor{}
+	if prop.PropertyNames != nil {
+		names = *prop.PropertyNames
+	}
+	desc := JSONDescriptor{}
+	if names.JSONDescriptor != nil {
+		desc = *names.JSONDescriptor
+	}
+	if hasPreset {
+		desc.Pattern, desc.MaxLength = preset.Pattern, preset.MaxLength
+	}
+	if closed {
+		desc.MinLength = intPointer(1)
+	}
+	if hasPattern {
+		desc.Pattern = pattern
+	}
+	if maxLength != nil {
+		desc.MaxLength = maxLength
+	}
+	names.JSONDescriptor = &desc
+	prop.PropertyNames = &names
+	return !closed || prop.MaxProperties != nil
+}
+
+// stringFormPattern matches the string form of a map, comma separated
+// key=value pairs such as "app=web,tier=frontend", as accepted by the APIs
+// taking label selectors either way.
+const stringFormPattern = `^([^,=]+=[^,]*(,[^,=]+=[^,]*)*)?$`
+
+// applyStringFormTag lets a map property tagged stringForm also be given in
+// its string form: it becomes the oneOf of the map and of a string matching
+// the value of the option, stringFormPattern if empty, e.g.
+// schemagen:"stringForm" or schemagen:"stringForm=^[a-z]+:[a-z]+$". The
+// Java type of the map is kept on the property. It returns false if the
+// property is not a map.
+func applyStringFormTag(prop *JSONPropertyDescriptor, tag schemagenTag) bool {
+	pattern, ok := tag["stringForm"]
+	if !ok {
+		return true
+	}
+	if prop.JSONMapDescriptor == nil {
+		return false
+	}
+	if len(pattern) == 0 {
+		pattern = stringFormPattern
+	}
+	structured := *prop
+	structured.JavaTypeDescriptor = nil
+	*prop = JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf: []JSONPropertyDescriptor{
+				structured,
+				{
+					JSONDescriptor: &JSONDescriptor{
+						Type:    "string",
+						Pattern: pattern,
+					},
+				},
+			},
+		},
+		JavaTypeDescriptor: prop.JavaTypeDescriptor,
+	}
+	return true
+}
+
+// anyDescriptor describes a field tagged schemagen:"any" regardless of its
+// Go type: as an untyped schema, or as a free-form object for "any=object".
+func anyDescriptor(value string) JSONPropertyDescriptor {
+	if value == "object" {
+		return prunedDescriptor()
+	}
+	return JSONPropertyDescriptor{}
+}
+
+// applyExampleTag adds the value of an `example:"..."` struct tag to the
+// examples of a property, typed after the field.
+func applyExampleTag(prop *JSONPropertyDescriptor, f reflect.StructField) {
+	value := f.Tag.Get("example")
+	if len(value) == 0 {
+		return
+	}
+	desc := JSONDescriptor{}
+	if prop.JSONDescriptor != nil {
+		desc = *prop.JSONDescriptor
+	}
+	desc.Examples = []interface{}{exampleValue(f.Type, value)}
+	prop.JSONDescriptor = &desc
+}
+
+// applyDocTags sets the title, description and default of a property from
+// the `title:"..."`, `description:"..."` and `default:"..."` struct tags.
+// Defaults are typed after the field like examples.
+func applyDocTags(prop *JSONPropertyDescriptor, f reflect.StructField) {
+	title, description, def := f.Tag.Get("title"), f.Tag.Get("description"), f.Tag.Get("default")
+	if len(title) == 0 && len(description) == 0 && len(def) == 0 {
+		return
+	}
+	desc := JSONDescriptor{}
+	if prop.JSONDescriptor != nil {
+		desc = *prop.JSONDescriptor
+	}
+	if len(title) > 0 {
+		desc.Title = title
+	}
+	if len(description) > 0 {
+		desc.Description = description
+	}
+	if len(def) > 0 {
+		desc.Default = exampleValue(f.Type, def)
+	}
+	prop.JSONDescriptor = &desc
+}
+
+// applyProtobufTag records the field number and name declared by the
+// `protobuf:"bytes,2,opt,name=spec"` struct tag of a field, if any, so that
+// gRPC and JSON transcoding tools can align with the schema. Tags without a
+// valid field number are ignored.
+func applyProtobufTag(prop *JSONPropertyDescriptor, f reflect.StructField) {
+	value := f.Tag.Get("protobuf")
+	if len(value) == 0 {
+		return
+	}
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 {
+		return
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return
+	}
+	proto := &ProtobufDescriptor{FieldNumber: number}
+	for _, part := range parts[2:] {
+		if strings.HasPrefix(part, "name=") {
+			proto.Name = strings.TrimPrefix(part, "name=")
+		}
+	}
+	prop.ProtobufDescriptor = proto
+}
+
+// exampleValue parses an example given in a struct tag according to the
+// kind of the field. Values that cannot be parsed are kept as strings.
+func exampleValue(t reflect.Type, value string) interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return value
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	default:
+		var v interface{}
+		if err := json.Unmarshal([]byte(value), &v); err == nil {
+			return v
+		}
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitTagOptions(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		opts  []string
+	}{
+		{"minimum=1,maximum=65535", []string{"minimum=1", "maximum=65535"}},
+		{"pattern=^[a-z]{1,63}$", []string{"pattern=^[a-z]{1,63}$"}},
+		{"pattern=^[,;]+$,maxLength=8", []string{"pattern=^[,;]+$", "maxLength=8"}},
+		{"pattern=^(a,b|c)$,nullable", []string{"pattern=^(a,b|c)$", "nullable"}},
+		{`pattern=^a\,b$,format=`, []string{`pattern=^a\,b$`, "format="}},
+		{`pattern=^[\]{]+$,prune`, []string{`pattern=^[\]{]+$`, "prune"}},
+	} {
+		if opts := splitTagOptions(test.value); !reflect.DeepEqual(opts, test.opts) {
+			t.Errorf("%s: got %q, expected %q", test.value, opts, test.opts)
+		}
+	}
+}
+
+type tagRoot struct {
+	Name   string            `json:"name" schemagen:"pattern=^[a-z]{1,63}$,maxLength=63"`
+	Labels map[string]string `json:"labels" schemagen:"keyPattern=^[a-z]{1,8}(\\.[a-z]{1,8})*$,maxProperties=4"`
+}
+
+func TestTagPatterns(t *testing.T) {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(tagRoot{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := schema.Properties["name"]
+	if name.Pattern != "^[a-z]{1,63}$" || name.MaxLength == nil || *name.MaxLength != 63 {
+		t.Errorf("unexpected name: %#v", name.JSONDescriptor)
+	}
+	labels := schema.Properties["labels"]
+	if labels.PropertyNames == nil || labels.PropertyNames.Pattern != `^[a-z]{1,8}(\.[a-z]{1,8})*$` {
+		t.Errorf("unexpected label keys: %#v", labels.PropertyNames)
+	}
+	if labels.MaxProperties == nil || *labels.MaxProperties != 4 {
+		t.Errorf("unexpected maxProperties: %v", labels.MaxProperties)
+	}
+	if len(schema.Warnings) > 0 {
+		t.Errorf("unexpected warnings: %v", schema.Warnings)
+	}
+}
+
+type tagInvalidRoot struct {
+	Size  int               `json:"size" schemagen:"minimum=abc,maxLength=x"`
+	Name  string            `json:"name" schemagen:"pattern=^[a-z$"`
+	Items map[string]string `json:"items" schemagen:"keyMaxLength=1.5"`
+}
+
+func TestTagInvalidValues(t *testing.T) {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(tagInvalidRoot{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	warnings := strings.Join(schema.Warnings, "\n")
+	for _, option := range []string{"minimum=abc", "maxLength=x", "pattern=^[a-z$", "keyMaxLength=1.5"} {
+		if !strings.Contains(warnings, option) {
+			t.Errorf("no warning about %s in %v", option, schema.Warnings)
+		}
+	}
+	if _, err := GenerateSchemaWithOptions(reflect.TypeOf(tagInvalidRoot{}), Options{Strict: true}); err == nil {
+		t.Error("Expected invalid tag values to fail strict generation")
+	}
+}
+
+type tagValueTypeRoot struct {
+	Known   map[string]interface{} `json:"known" schemagen:"valueType=int"`
+	Unknown map[string]interface{} `json:"unknown" schemagen:"valueType=decimal"`
+}
+
+func TestTagUnknownValueType(t *testing.T) {
+	schema, err := GenerateSchemaWithOptions(reflect.TypeOf(tagValueTypeRoot{}), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Warnings) != 1 || !strings.Contains(schema.Warnings[0], "unknown valueType decimal") {
+		t.Errorf("expected a warning about the unknown valueType only, got %v", schema.Warnings)
+	}
+}
@@ -0,0 +1,53 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"text/template"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// TemplateEmitter renders a schema with a text/template, so that custom
+// artifacts such as DSLs, SQL DDL or documentation can be produced without
+// writing Go code. The template is executed with the *JSONSchema and can
+// use the functions of TemplateFuncs.
+type TemplateEmitter struct {
+	Template *template.Template
+}
+
+// TemplateFuncs are the functions available to the templates of
+// TemplateEmitter:
+//
+//	definitions  the sorted names of a map of definitions
+//	properties   the sorted names of a map of properties
+//	refName      the definition name a reference points to, if local
+//	json         the JSON encoding of a value
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"definitions": sortedDefinitionNames,
+		"properties":  sortedPropertyNames,
+		"refName": func(ref string) string {
+			name, _ := schemamodel.LocalDefinition(ref)
+			return name
+		},
+		"json": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+}
+
+// LoadTemplateEmitter parses the template file at path into an emitter.
+func LoadTemplateEmitter(path string) (TemplateEmitter, error) {
+	t, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).ParseFiles(path)
+	if err != nil {
+		return TemplateEmitter{}, err
+	}
+	return TemplateEmitter{Template: t}, nil
+}
+
+func (e TemplateEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	return e.Template.Execute(w, schema)
+}
@@ -0,0 +1,19 @@
+// Package loaderpkg is described by the tests of GeneratePackageSchema.
+package loaderpkg
+
+// Widget is resolved by the tests.
+type Widget struct {
+	Name string `json:"name"`
+}
+
+// Gadget stands for a struct added upstream since the generator was built.
+type Gadget struct {
+	Size int `json:"size"`
+}
+
+type hidden struct {
+	Secret string
+}
+
+// Name is not a struct.
+type Name string
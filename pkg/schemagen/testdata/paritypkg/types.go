@@ -0,0 +1,54 @@
+// Package paritypkg is described both from its compiled types and from its
+// sources by the tests of the source front-end.
+package paritypkg
+
+import "time"
+
+// Phase is the phase of a Widget.
+type Phase string
+
+const (
+	// PhasePending is the phase of new widgets.
+	PhasePending Phase = "Pending"
+	// PhaseRunning is the phase of started widgets.
+	PhaseRunning Phase = "Running"
+)
+
+// Port is a network port.
+type Port int32
+
+// Widget is the root of the fixture.
+type Widget struct {
+	Meta `json:",inline"`
+
+	// Name identifies the widget.
+	Name string `json:"name"`
+	// Phase is optional.
+	Phase    Phase              `json:"phase,omitempty"`
+	Ports    []Port             `json:"ports,omitempty"`
+	ByPort   map[int]Part       `json:"byPort,omitempty"`
+	ByPhase  map[Phase]int64    `json:"byPhase,omitempty"`
+	Counts   map[uint16]float64 `json:"counts,omitempty"`
+	Labels   map[string]string  `json:"labels,omitempty"`
+	Parts    []*Part            `json:"parts"`
+	Fixed    [2]bool            `json:"fixed"`
+	Data     []byte             `json:"data,omitempty"`
+	Created  time.Time          `json:"created"`
+	Parent   *Widget            `json:"parent,omitempty"`
+	Settings struct {
+		Verbose bool `json:"verbose"`
+	} `json:"settings"`
+	Ignored string `json:"-"`
+	hidden  string
+}
+
+// Meta is embedded in Widget.
+type Meta struct {
+	Revision uint64 `json:"revision"`
+}
+
+// Part is a part of a Widget.
+type Part struct {
+	Kind   string  `json:"kind"`
+	Weight float32 `json:"weight,omitempty"`
+}
@@ -0,0 +1,78 @@
+package schemagen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TitleStyle selects the titles derived from the names of Go fields and
+// types, for the UI form generators labelling inputs with them.
+type TitleStyle int
+
+const (
+	// NoTitles only emits the titles of title struct tags.
+	NoTitles TitleStyle = iota
+	// AsIsTitles titles after the Go names, e.g. PodIP.
+	AsIsTitles
+	// SentenceTitles titles in sentence case, e.g. "Pod IP" for PodIP and
+	// "Restart policy" for RestartPolicy.
+	SentenceTitles
+	// WordTitles capitalizes every word, e.g. "Restart Policy".
+	WordTitles
+)
+
+// ParseTitleStyle parses the name of a title style: "asIs", "sentence",
+// "words" or an empty string for none.
+func ParseTitleStyle(s string) (TitleStyle, error) {
+	switch s {
+	case "", "none":
+		return NoTitles, nil
+	case "asIs":
+		return AsIsTitles, nil
+	case "sentence":
+		return SentenceTitles, nil
+	case "words":
+		return WordTitles, nil
+	}
+	return 0, fmt.Errorf("Unknown title style %q.", s)
+}
+
+// title returns the title of the Go name under Options.Titles.
+func (g *schemaGenerator) title(name string) string {
+	switch g.opts.Titles {
+	case AsIsTitles:
+		return name
+	case SentenceTitles, WordTitles:
+		words := camelWords(name)
+		for i, word := range words {
+			if i > 0 && g.opts.Titles == SentenceTitles && !isAcronym(word) {
+				words[i] = strings.ToLower(word)
+			} else {
+				words[i] = strings.ToUpper(word[:1]) + word[1:]
+			}
+		}
+		return strings.Join(words, " ")
+	}
+	return ""
+}
+
+// applyTitle titles prop after the Go name unless it has a title already.
+func (g *schemaGenerator) applyTitle(prop *JSONPropertyDescriptor, name string) {
+	title := g.title(name)
+	if len(title) == 0 || prop.JSONDescriptor != nil && len(prop.Title) > 0 {
+		return
+	}
+	desc := JSONDescriptor{}
+	if prop.JSONDescriptor != nil {
+		desc = *prop.JSONDescriptor
+	}
+	desc.Title = title
+	prop.JSONDescriptor = &desc
+}
+
+// isAcronym tells whether word is a run of capitals, e.g. IP.
+func isAcronym(word string) bool {
+	runes := []rune(word)
+	return len(runes) > 1 && unicode.IsUpper(runes[0]) && unicode.IsUpper(runes[1])
+}
@@ -0,0 +1,207 @@
+package schemagen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// TypeScriptEmitter renders the definitions of a schema as TypeScript
+// declarations, so that web clients share the model of the schema: an
+// interface for each object definition, whose optional properties are the
+// ones it does not require, and a type alias for the others. Enums become
+// unions of literal types, oneOf and anyOf unions and allOf intersections.
+type TypeScriptEmitter struct {
+	// Root names the interface of the root object, which is left out if
+	// it is empty.
+	Root string
+}
+
+// GenerateTypeScript walks t like GenerateSchemaWithOptions and returns the
+// TypeScript declarations of its definitions, along with an interface named
+// after t for the root.
+func GenerateTypeScript(t reflect.Type, opts Options) (string, error) {
+	s, err := GenerateSchemaWithOptions(t, opts)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := (TypeScriptEmitter{Root: tsIdentifier(t.Name())}).Emit(s, &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// tsIdentifier returns name with the characters TypeScript identifiers
+// cannot hold replaced by underscores.
+func tsIdentifier(name string) string {
+	id := []rune(name)
+	for i, r := range id {
+		if !(r == '_' || r == '$' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9') {
+			id[i] = '_'
+		}
+	}
+	return string(id)
+}
+
+// tsPropertyName returns name as a property name, quoted unless it is an
+// identifier.
+func tsPropertyName(name string) string {
+	if len(name) > 0 && tsIdentifier(name) == name {
+		return name
+	}
+	b, _ := json.Marshal(name)
+	return string(b)
+}
+
+func (e TypeScriptEmitter) tsType(p JSONPropertyDescriptor, indent string) string {
+	if p.JSONReferenceDescriptor != nil {
+		if name, ok := schemamodel.LocalDefinition(p.Reference); ok {
+			return tsIdentifier(name)
+		}
+		return "unknown"
+	}
+	if p.JSONCombinatorDescriptor != nil {
+		if len(p.AllOf) > 0 {
+			parts := make([]string, 0, len(p.AllOf)+1)
+			for _, part := range p.AllOf {
+				parts = append(parts, e.tsType(part, indent))
+			}
+			if p.JSONObjectDescriptor != nil && len(p.Properties) > 0 {
+				own := p
+				own.JSONCombinatorDescriptor = nil
+				parts = append(parts, e.tsType(own, indent))
+			}
+			return strings.Join(parts, " & ")
+		}
+		for _, alts := range [][]JSONPropertyDescriptor{p.OneOf, p.AnyOf} {
+			if len(alts) > 0 {
+				types := make([]string, len(alts))
+				for i, alt := range alts {
+					types[i] = e.tsType(alt, indent)
+				}
+				return "(" + strings.Join(types, " | ") + ")"
+			}
+		}
+	}
+	if p.JSONDescriptor == nil {
+		return "unknown"
+	}
+	t := e.tsDescribedType(p, indent)
+	if p.AllowNull {
+		t += " | null"
+	}
+	return t
+}
+
+// tsDescribedType returns the type of the values described by the
+// JSONDescriptor of p.
+func (e TypeScriptEmitter) tsDescribedType(p JSONPropertyDescriptor, indent string) string {
+	if len(p.Enum) > 0 {
+		literals := make([]string, len(p.Enum))
+		for i, v := range p.Enum {
+			b, _ := json.Marshal(v)
+			literals[i] = string(b)
+		}
+		return strings.Join(literals, " | ")
+	}
+	switch p.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "null":
+		return "null"
+	case "array":
+		if p.JSONArrayDescriptor != nil {
+			return "Array<" + e.tsType(p.Items, indent) + ">"
+		}
+		return "Array<unknown>"
+	case "object":
+		if p.JSONMapDescriptor != nil {
+			return "{ [key: string]: " + e.tsType(p.MapValueType, indent) + " }"
+		}
+		if p.JSONObjectDescriptor != nil && len(p.Properties) > 0 {
+			var b bytes.Buffer
+			b.WriteString("{\n")
+			e.writeProperties(&b, p.JSONObjectDescriptor, indent+"  ")
+			b.WriteString(indent + "}")
+			return b.String()
+		}
+		return "{ [key: string]: unknown }"
+	}
+	return "unknown"
+}
+
+// writeProperties writes the properties of obj, one per line.
+func (e TypeScriptEmitter) writeProperties(w io.Writer, obj *JSONObjectDescriptor, indent string) {
+	required := make(map[string]bool, len(obj.Required))
+	for _, name := range obj.Required {
+		required[name] = true
+	}
+	for _, name := range sortedPropertyNames(obj.Properties) {
+		prop := obj.Properties[name]
+		if prop.JSONDescriptor != nil {
+			writeTSDoc(w, prop.Description, indent)
+		}
+		optional := "?"
+		if required[name] {
+			optional = ""
+		}
+		fmt.Fprintf(w, "%s%s%s: %s;\n", indent, tsPropertyName(name), optional, e.tsType(prop, indent))
+	}
+}
+
+// writeTSDoc writes description as a doc comment, if it is not empty.
+func writeTSDoc(w io.Writer, description, indent string) {
+	if len(description) == 0 {
+		return
+	}
+	description = strings.Replace(description, "*/", "*\\/", -1)
+	lines := strings.Split(strings.TrimRight(description, "\n"), "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(w, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	fmt.Fprintf(w, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(w, "%s * %s\n", indent, line)
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+}
+
+func (e TypeScriptEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "// Code generated by schemagen. DO NOT EDIT.")
+	if len(e.Root) > 0 && schema.JSONObjectDescriptor != nil {
+		fmt.Fprintln(out)
+		writeTSDoc(out, schema.Description, "")
+		fmt.Fprintf(out, "export interface %s {\n", e.Root)
+		e.writeProperties(out, schema.JSONObjectDescriptor, "  ")
+		fmt.Fprintln(out, "}")
+	}
+	for _, name := range sortedDefinitionNames(schema.Definitions) {
+		def := schema.Definitions[name]
+		fmt.Fprintln(out)
+		if def.JSONDescriptor != nil {
+			writeTSDoc(out, def.Description, "")
+		}
+		id := tsIdentifier(name)
+		if def.JSONObjectDescriptor != nil && def.JSONCombinatorDescriptor == nil && len(def.Properties) > 0 {
+			fmt.Fprintf(out, "export interface %s {\n", id)
+			e.writeProperties(out, def.JSONObjectDescriptor, "  ")
+			fmt.Fprintln(out, "}")
+			continue
+		}
+		fmt.Fprintf(out, "export type %s = %s;\n", id, e.tsType(def, ""))
+	}
+	return out.Flush()
+}
@@ -0,0 +1,136 @@
+package schemagen
+
+import (
+	"reflect"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// UIElement is an element of a JSON Forms UI schema: a layout or a group of
+// elements, or a control editing the property its scope points to.
+type UIElement struct {
+	Type     string                 `json:"type"`
+	Label    string                 `json:"label,omitempty"`
+	Scope    string                 `json:"scope,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+	Elements []UIElement            `json:"elements,omitempty"`
+}
+
+// uiRadioLimit is the largest number of enum values rendered as radio
+// buttons rather than as a drop-down.
+const uiRadioLimit = 4
+
+// GenerateUISchemas generates the schema of t along with a companion JSON
+// Forms UI schema for each object definition, keyed by definition name, and
+// for the root, keyed by RootDefinition, so that form builders can render
+// editing UIs of the resources. Controls follow the order of the Go fields,
+// the fields of embedded structs are grouped under the name of their type,
+// and formats and enums become widget hints.
+func GenerateUISchemas(t reflect.Type, opts Options) (*JSONSchema, map[string]UIElement, error) {
+	g := newSchemaGenerator(opts)
+	s, err := g.generate(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	ui := map[string]UIElement{
+		RootDefinition: {
+			Type:     "VerticalLayout",
+			Elements: g.uiElements(t, s.JSONObjectDescriptor, nil),
+		},
+	}
+	for k := range g.types {
+		if k.Kind() != reflect.Struct {
+			continue
+		}
+		name := g.qualifiedName(k)
+		def, ok := s.Definitions[name]
+		if !ok || def.JSONObjectDescriptor == nil {
+			continue
+		}
+		ui[name] = UIElement{
+			Type:     "VerticalLayout",
+			Elements: g.uiElements(k, def.JSONObjectDescriptor, nil),
+		}
+	}
+	return s, ui, nil
+}
+
+// uiElements returns the controls of the fields of t found among the
+// properties of obj, in the order of the fields. Fields named in shadowed
+// are declared by a struct embedding t, which takes precedence.
+func (g *schemaGenerator) uiElements(t reflect.Type, obj *JSONObjectDescriptor, shadowed map[string]bool) []UIElement {
+	var elements []UIElement
+	if obj == nil {
+		return elements
+	}
+	var fields []reflect.StructField
+	direct := map[string]bool{}
+	for name := range shadowed {
+		direct[name] = true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if len(field.PkgPath) > 0 || g.isIgnored(field) || g.isCatchAll(field) {
+			continue
+		}
+		if _, ok := getSchemagenTag(field)["prune"]; ok {
+			continue
+		}
+		if g.fieldRule(t, g.getFieldName(field)).Exclude {
+			continue
+		}
+		fields = append(fields, field)
+		if !g.isInline(field) || field.Type.Kind() != reflect.Struct {
+			direct[g.uiFieldName(t, field)] = true
+		}
+	}
+	for _, field := range fields {
+		if g.isInline(field) && field.Type.Kind() == reflect.Struct {
+			if group := g.uiElements(field.Type, obj, direct); len(group) > 0 {
+				elements = append(elements, UIElement{
+					Type:     "Group",
+					Label:    field.Type.Name(),
+					Elements: group,
+				})
+			}
+			continue
+		}
+		name := g.uiFieldName(t, field)
+		prop, ok := obj.Properties[name]
+		if !ok || shadowed[name] {
+			continue
+		}
+		elements = append(elements, UIElement{
+			Type:    "Control",
+			Scope:   "#/properties/" + schemamodel.EscapePointer(name),
+			Options: uiOptions(prop),
+		})
+	}
+	return elements
+}
+
+// uiOptions returns the widget hints of the control of a property.
+func uiOptions(p JSONPropertyDescriptor) map[string]interface{} {
+	if p.JSONDescriptor == nil {
+		return nil
+	}
+	switch {
+	case len(p.Format) > 0:
+		return map[string]interface{}{"format": p.Format}
+	case len(p.Enum) > 0 && len(p.Enum) <= uiRadioLimit:
+		return map[string]interface{}{"format": "radio"}
+	case p.Type == "boolean":
+		return map[string]interface{}{"toggle": true}
+	}
+	return nil
+}
+
+// uiFieldName returns the name of the property of the field of t, renamed
+// by Options.FieldRules.
+func (g *schemaGenerator) uiFieldName(t reflect.Type, field reflect.StructField) string {
+	name := g.getFieldName(field)
+	if rule := g.fieldRule(t, name); len(rule.Name) > 0 {
+		return rule.Name
+	}
+	return name
+}
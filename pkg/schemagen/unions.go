@@ -0,0 +1,152 @@
+package schemagen
+
+import (
+	"reflect"
+	"strings"
+)
+
+// RegisterImplementations describes the values of the interface type iface
+// as one of the types impls: a oneOf of references to their definitions
+// with a discriminator, the property named by Options.Discriminator whose
+// value is the Go name of the implementation, and the Java type of iface,
+// which the Java model is expected to provide. Values of interfaces
+// without implementations are described by empty schemas accepting
+// anything. It panics if iface is not an interface type or if an
+// implementation, or a pointer to it, does not implement it.
+func (gen *Generator) RegisterImplementations(iface reflect.Type, impls ...reflect.Type) {
+	if iface.Kind() != reflect.Interface {
+		panic("schemagen: RegisterImplementations called with non-interface type " + iface.String())
+	}
+	for _, impl := range impls {
+		if !impl.Implements(iface) && !reflect.PtrTo(impl).Implements(iface) {
+			panic("schemagen: " + impl.String() + " does not implement " + iface.String())
+		}
+	}
+	gen.g.implementations[iface] = append(gen.g.implementations[iface], impls...)
+}
+
+// unionDescriptor describes the values of the interface type t as one of
+// its implementations.
+func (g *schemaGenerator) unionDescriptor(t reflect.Type, impls []reflect.Type) JSONPropertyDescriptor {
+	discriminator := &Discriminator{
+		PropertyName: g.opts.Discriminator,
+		Mapping:      make(map[string]string),
+	}
+	if len(discriminator.PropertyName) == 0 {
+		discriminator.PropertyName = "kind"
+	}
+	alts := make([]JSONPropertyDescriptor, 0, len(impls))
+	for _, impl := range impls {
+		alt := g.getPropertyDescriptor(impl)
+		if alt.JSONReferenceDescriptor != nil {
+			for impl.Kind() == reflect.Ptr {
+				impl = impl.Elem()
+			}
+			discriminator.Mapping[impl.Name()] = alt.Reference
+		}
+		alts = append(alts, alt)
+	}
+	return JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf:         alts,
+			Discriminator: discriminator,
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: g.javaType(t),
+		},
+	}
+}
+
+// remapDiscriminator returns a copy of d whose mapping follows the
+// references of the alternatives of a oneOf, from before to after they
+// were mapped, e.g. when definitions are renamed or relocated.
+func remapDiscriminator(d *Discriminator, before, after []JSONPropertyDescriptor) *Discriminator {
+	remapped := &Discriminator{
+		PropertyName: d.PropertyName,
+		Mapping:      make(map[string]string, len(d.Mapping)),
+	}
+	for value, ref := range d.Mapping {
+		remapped.Mapping[value] = ref
+		for i := range before {
+			if i < len(after) && alternativeRef(before[i]) == ref {
+				if r := alternativeRef(after[i]); len(r) > 0 {
+					remapped.Mapping[value] = r
+				}
+				break
+			}
+		}
+	}
+	return remapped
+}
+
+// alternativeRef returns the reference of an alternative of a oneOf,
+// possibly wrapped in an allOf by wrapRef.
+func alternativeRef(p JSONPropertyDescriptor) string {
+	if p.JSONReferenceDescriptor != nil {
+		return p.Reference
+	}
+	if p.JSONCombinatorDescriptor != nil && len(p.AllOf) > 0 && p.AllOf[0].JSONReferenceDescriptor != nil {
+		return p.AllOf[0].Reference
+	}
+	return ""
+}
+
+// tagUnionTypes resolves the types named by the oneOf tag option of a field
+// of parent, e.g. schemagen:"oneOf=BuildSourceGit;BuildSourceBinary". Names
+// are qualified by import path, as in configurations, or refer to the
+// implementations registered for the type of the field, to types of the
+// package of parent found by Options.Resolver or to registered types, in
+// this order. Unresolved names are reported through warn, if set.
+func (g *schemaGenerator) tagUnionTypes(parent reflect.Type, field reflect.StructField, value string, warn bool) []reflect.Type {
+	var types []reflect.Type
+	for _, name := range strings.Split(value, ";") {
+		if t, ok := g.resolveUnionType(parent, field, strings.TrimSpace(name)); ok {
+			types = append(types, t)
+		} else if warn {
+			g.warnf("Unable to resolve type %s of the oneOf of %s.%s.", name, parent.Name(), field.Name)
+		}
+	}
+	return types
+}
+
+func (g *schemaGenerator) resolveUnionType(parent reflect.Type, field reflect.StructField, name string) (reflect.Type, bool) {
+	if pkgPath, _ := SplitTypeName(name); len(pkgPath) > 0 {
+		t, err := resolveTypeName(g.opts.Resolver, name)
+		return t, err == nil
+	}
+	for _, impl := range g.implementations[field.Type] {
+		if impl.Name() == name {
+			return impl, true
+		}
+	}
+	if g.opts.Resolver != nil {
+		if t, ok := g.opts.Resolver.Resolve(parent.PkgPath(), name); ok {
+			return t, true
+		}
+	}
+	return Lookup(name)
+}
+
+// tagUnionDescriptor describes a field as one of the given types, or null
+// if nullable is set.
+func (g *schemaGenerator) tagUnionDescriptor(field reflect.StructField, types []reflect.Type, nullable bool) JSONPropertyDescriptor {
+	alts := make([]JSONPropertyDescriptor, 0, len(types)+1)
+	for _, t := range types {
+		alts = append(alts, g.getPropertyDescriptor(t))
+	}
+	if nullable {
+		alts = append(alts, JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: "null",
+			},
+		})
+	}
+	return JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf: alts,
+		},
+		JavaTypeDescriptor: &JavaTypeDescriptor{
+			JavaType: g.javaType(field.Type),
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package schemagen
+
+import "reflect"
+
+// UnionType describes a wrapper type marshalled as one of several JSON
+// types, such as the Kubernetes IntOrString.
+type UnionType struct {
+	// Types are the JSON types of the values, e.g. "integer" and "string",
+	// each emitted as an alternative of a oneOf.
+	Types []string
+	// JavaType is the Java type used for the values.
+	JavaType string
+	// Extensions optionally holds vendor extensions emitted alongside the
+	// oneOf, e.g. x-kubernetes-int-or-string.
+	Extensions map[string]interface{}
+}
+
+// IntOrStringUnion is the union of the Kubernetes IntOrString types.
+var IntOrStringUnion = UnionType{
+	Types:    []string{"integer", "string"},
+	JavaType: "io.fabric8.kubernetes.api.model.IntOrString",
+	Extensions: map[string]interface{}{
+		"x-kubernetes-int-or-string": true,
+	},
+}
+
+// DefaultUnionTypes maps the Kubernetes IntOrString types to their union.
+var DefaultUnionTypes = map[string]UnionType{
+	"github.com/GoogleCloudPlatform/kubernetes/pkg/util.IntOrString": IntOrStringUnion,
+	"k8s.io/apimachinery/pkg/util/intstr.IntOrString":                IntOrStringUnion,
+}
+
+// unionTypeDescriptor describes t as a oneOf of its JSON types if it is one
+// of the configured or default union types.
+func (g *schemaGenerator) unionTypeDescriptor(t reflect.Type) (JSONPropertyDescriptor, bool) {
+	key := typeKey(t)
+	union, ok := g.opts.UnionTypes[key]
+	if !ok {
+		union, ok = DefaultUnionTypes[key]
+	}
+	if !ok || len(union.Types) == 0 {
+		return JSONPropertyDescriptor{}, false
+	}
+	alts := make([]JSONPropertyDescriptor, len(union.Types))
+	for i, typ := range union.Types {
+		alts[i] = JSONPropertyDescriptor{
+			JSONDescriptor: &JSONDescriptor{
+				Type: typ,
+			},
+		}
+	}
+	desc := JSONPropertyDescriptor{
+		JSONCombinatorDescriptor: &JSONCombinatorDescriptor{
+			OneOf: alts,
+		},
+	}
+	if len(union.JavaType) > 0 {
+		desc.JavaTypeDescriptor = &JavaTypeDescriptor{
+			JavaType: union.JavaType,
+		}
+	}
+	if len(union.Extensions) > 0 {
+		desc.Extensions = make(map[string]interface{}, len(union.Extensions))
+		for k, v := range union.Extensions {
+			desc.Extensions[k] = v
+		}
+	}
+	return desc, true
+}
@@ -0,0 +1,110 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/csrwng/origin-schema-generator/pkg/schemamodel"
+)
+
+// Usages returns a reverse index of the schema mapping the name of every
+// referenced definition to the sorted JSON pointers of the descriptors
+// referring to it, for impact analysis and "used by" documentation.
+// References to the definitions of other documents, such as the ones of a
+// split schema, are indexed by definition name too.
+func Usages(s *JSONSchema) map[string][]string {
+	usages := make(map[string][]string)
+	walkPointers(s, func(pointer string, p JSONPropertyDescriptor) {
+		if p.JSONReferenceDescriptor == nil {
+			return
+		}
+		ref := p.Reference
+		if i := strings.Index(ref, "#"); i > 0 {
+			ref = ref[i:]
+		}
+		if name, ok := schemamodel.LocalDefinition(ref); ok {
+			usages[name] = append(usages[name], pointer)
+		}
+	})
+	for _, pointers := range usages {
+		sort.Strings(pointers)
+	}
+	return usages
+}
+
+// walkPointers calls fn with every descriptor of the schema, nested ones
+// included, and its JSON pointer.
+func walkPointers(s *JSONSchema, fn func(pointer string, p JSONPropertyDescriptor)) {
+	var walk func(pointer string, p JSONPropertyDescriptor)
+	walkObject := func(pointer string, o *JSONObjectDescriptor) {
+		if o == nil {
+			return
+		}
+		for _, name := range sortedPropertyNames(o.Properties) {
+			walk(pointer+"/properties/"+schemamodel.EscapePointer(name), o.Properties[name])
+		}
+		if additional, ok := o.AdditionalProperties.(JSONPropertyDescriptor); ok {
+			walk(pointer+"/additionalProperties", additional)
+		}
+	}
+	walk = func(pointer string, p JSONPropertyDescriptor) {
+		fn(pointer, p)
+		walkObject(pointer, p.JSONObjectDescriptor)
+		if p.JSONArrayDescriptor != nil {
+			walk(pointer+"/items", p.Items)
+		}
+		if p.JSONMapDescriptor != nil {
+			walk(pointer+"/additionalProperties", p.MapValueType)
+			if p.PropertyNames != nil {
+				walk(pointer+"/propertyNames", *p.PropertyNames)
+			}
+			for _, pattern := range sortedPropertyNames(p.PatternProperties) {
+				walk(pointer+"/patternProperties/"+schemamodel.EscapePointer(pattern), p.PatternProperties[pattern])
+			}
+		}
+		if p.JSONCombinatorDescriptor != nil {
+			for i, alt := range p.AllOf {
+				walk(fmt.Sprintf("%s/allOf/%d", pointer, i), alt)
+			}
+			for i, alt := range p.AnyOf {
+				walk(fmt.Sprintf("%s/anyOf/%d", pointer, i), alt)
+			}
+			for i, alt := range p.OneOf {
+				walk(fmt.Sprintf("%s/oneOf/%d", pointer, i), alt)
+			}
+		}
+		if p.JSONDefinitionsDescriptor != nil {
+			for _, name := range sortedDefinitionNames(p.Definitions) {
+				walk(pointer+"/definitions/"+schemamodel.EscapePointer(name), p.Definitions[name])
+			}
+		}
+	}
+	walkObject("", s.JSONObjectDescriptor)
+	for _, name := range sortedDefinitionNames(s.Definitions) {
+		walk("/definitions/"+schemamodel.EscapePointer(name), s.Definitions[name])
+	}
+}
+
+// UsageEmitter writes the Usages of the schema as a JSON object, as a
+// sidecar of the schema.
+type UsageEmitter struct {
+	Indent string
+}
+
+func (e UsageEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	var b []byte
+	var err error
+	if len(e.Indent) > 0 {
+		b, err = json.MarshalIndent(Usages(schema), "", e.Indent)
+	} else {
+		b, err = json.Marshal(Usages(schema))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,421 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// verifyLimit is the largest number of mismatches listed by a failed
+// verification.
+const verifyLimit = 10
+
+// Verify marshals sample with encoding/json and validates the document
+// against schema, as emitted in its draft, so that test suites catch
+// generation bugs, such as descriptors accepting nothing or rejecting valid
+// values, by round-tripping sample values of the described types. The
+// validator is built in and
+// covers the keywords the generator emits: type, enum, const, $ref to the
+// schema itself, properties, patternProperties, required,
+// additionalProperties, unevaluatedProperties, propertyNames, items, the
+// length, size and range bounds, pattern, allOf, anyOf, oneOf, not,
+// nullable and x-nullable.
+// Formats are not checked, and references to other documents accept any
+// value.
+func Verify(schema *JSONSchema, sample interface{}) error {
+	var emitted bytes.Buffer
+	if err := (DraftEmitter{Draft: schema.Draft}).Emit(schema, &emitted); err != nil {
+		return err
+	}
+	var root interface{}
+	if err := json.Unmarshal(emitted.Bytes(), &root); err != nil {
+		return err
+	}
+	b, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	v := verifier{root: root, patterns: make(map[string]*regexp.Regexp)}
+	v.check(root, doc, "")
+	if len(v.mismatches) == 0 {
+		return nil
+	}
+	mismatches := v.mismatches
+	if len(mismatches) > verifyLimit {
+		mismatches = append(mismatches[:verifyLimit], fmt.Sprintf("and %d more", len(mismatches)-verifyLimit))
+	}
+	return fmt.Errorf("The sample does not match the schema: %s.", strings.Join(mismatches, ", "))
+}
+
+// verifier validates documents against the generic JSON form of a schema,
+// recording the mismatches located by the JSON pointers of the values.
+type verifier struct {
+	root       interface{}
+	patterns   map[string]*regexp.Regexp
+	mismatches []string
+}
+
+func (v *verifier) mismatch(pointer, format string, args ...interface{}) {
+	if len(pointer) == 0 {
+		pointer = "/"
+	}
+	v.mismatches = append(v.mismatches, pointer+": "+fmt.Sprintf(format, args...))
+}
+
+// matches reports whether value matches schema, without recording the
+// mismatches, for the alternatives of anyOf, oneOf and not.
+func (v *verifier) matches(schema, value interface{}) bool {
+	alt := verifier{root: v.root, patterns: v.patterns}
+	alt.check(schema, value, "")
+	return len(alt.mismatches) == 0
+}
+
+// resolve returns the schema referred to by ref, a JSON pointer into the
+// root such as "#/definitions/os_route_Route", false for references to
+// other documents.
+func (v *verifier) resolve(ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, false
+	}
+	schema := v.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		obj, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if schema, ok = obj[token]; !ok {
+			return nil, false
+		}
+	}
+	return schema, true
+}
+
+func (v *verifier) check(schema, value interface{}, pointer string) {
+	if accept, ok := schema.(bool); ok {
+		if !accept {
+			v.mismatch(pointer, "no value is allowed")
+		}
+		return
+	}
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		if target, ok := v.resolve(ref); ok {
+			v.check(target, value, pointer)
+		} else if strings.HasPrefix(ref, "#") {
+			v.mismatch(pointer, "unresolved reference %s", ref)
+		}
+	}
+	if value == nil && (s["nullable"] == true || s["x-nullable"] == true) {
+		return
+	}
+	if types := schemaTypes(s["type"]); len(types) > 0 && !hasJSONType(types, value) {
+		v.mismatch(pointer, "%s is not of type %s", jsonTypeOf(value), strings.Join(types, " or "))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && !containsValue(enum, value) {
+		v.mismatch(pointer, "%s is not one of the enum", compactJSON(value))
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, value) {
+		v.mismatch(pointer, "%s is not %s", compactJSON(value), compactJSON(c))
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		v.checkObject(s, value, pointer)
+	case []interface{}:
+		v.checkArray(s, value, pointer)
+	case string:
+		v.checkString(s, value, pointer)
+	case float64:
+		v.checkNumber(s, value, pointer)
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, alt := range all {
+			v.check(alt, value, pointer)
+		}
+	}
+	if alts, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, alt := range alts {
+			if v.matches(alt, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.mismatch(pointer, "%s matches none of anyOf", compactJSON(value))
+		}
+	}
+	if one, ok := s["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, alt := range one {
+			if v.matches(alt, value) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.mismatch(pointer, "%s matches %d of oneOf instead of one", compactJSON(value), matched)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value) {
+		v.mismatch(pointer, "%s matches not", compactJSON(value))
+	}
+}
+
+func (v *verifier) checkObject(s map[string]interface{}, value map[string]interface{}, pointer string) {
+	properties, _ := s["properties"].(map[string]interface{})
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		at := pointer + "/" + strings.Replace(strings.Replace(name, "~", "~0", -1), "/", "~1", -1)
+		if names, ok := s["propertyNames"]; ok && !v.matches(names, name) {
+			v.mismatch(at, "the property name does not match propertyNames")
+		}
+		declared := false
+		if prop, ok := properties[name]; ok {
+			v.check(prop, value[name], at)
+			declared = true
+		}
+		for _, sub := range v.patternSchemas(s, name) {
+			v.check(sub, value[name], at)
+			declared = true
+		}
+		if declared {
+			continue
+		}
+		if additional, ok := s["additionalProperties"]; ok {
+			if additional == false {
+				v.mismatch(at, "the property is not declared")
+			} else {
+				v.check(additional, value[name], at)
+			}
+			continue
+		}
+		if s["unevaluatedProperties"] == false && !v.evaluated(s, name) {
+			v.mismatch(at, "the property is not declared")
+		}
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := value[name]; !ok {
+					v.mismatch(pointer, "the required property %s is missing", name)
+				}
+			}
+		}
+	}
+	if min, ok := s["minProperties"].(float64); ok && float64(len(value)) < min {
+		v.mismatch(pointer, "%d properties are fewer than minProperties %v", len(value), min)
+	}
+	if max, ok := s["maxProperties"].(float64); ok && float64(len(value)) > max {
+		v.mismatch(pointer, "%d properties are more than maxProperties %v", len(value), max)
+	}
+}
+
+// evaluated reports whether the property name is declared by s or by the
+// subschemas it combines, as unevaluatedProperties sees it.
+func (v *verifier) evaluated(s map[string]interface{}, name string) bool {
+	if properties, ok := s["properties"].(map[string]interface{}); ok {
+		if _, ok := properties[name]; ok {
+			return true
+		}
+	}
+	if len(v.patternSchemas(s, name)) > 0 {
+		return true
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		if target, ok := v.resolve(ref); ok {
+			if target, ok := target.(map[string]interface{}); ok && v.evaluated(target, name) {
+				return true
+			}
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		alts, _ := s[keyword].([]interface{})
+		for _, alt := range alts {
+			if alt, ok := alt.(map[string]interface{}); ok && v.evaluated(alt, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (v *verifier) checkArray(s map[string]interface{}, value []interface{}, pointer string) {
+	switch items := s["items"].(type) {
+	case []interface{}:
+		for i, item := range value {
+			if i < len(items) {
+				v.check(items[i], item, fmt.Sprintf("%s/%d", pointer, i))
+			}
+		}
+	case nil:
+	default:
+		for i, item := range value {
+			v.check(items, item, fmt.Sprintf("%s/%d", pointer, i))
+		}
+	}
+	if min, ok := s["minItems"].(float64); ok && float64(len(value)) < min {
+		v.mismatch(pointer, "%d items are fewer than minItems %v", len(value), min)
+	}
+	if max, ok := s["maxItems"].(float64); ok && float64(len(value)) > max {
+		v.mismatch(pointer, "%d items are more than maxItems %v", len(value), max)
+	}
+}
+
+func (v *verifier) checkString(s map[string]interface{}, value string, pointer string) {
+	length := float64(len([]rune(value)))
+	if min, ok := s["minLength"].(float64); ok && length < min {
+		v.mismatch(pointer, "%q is shorter than minLength %v", value, min)
+	}
+	if max, ok := s["maxLength"].(float64); ok && length > max {
+		v.mismatch(pointer, "%q is longer than maxLength %v", value, max)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re := v.regexp(pattern)
+		if re == nil {
+			v.mismatch(pointer, "invalid pattern %q", pattern)
+		} else if !re.MatchString(value) {
+			v.mismatch(pointer, "%q does not match the pattern %q", value, pattern)
+		}
+	}
+}
+
+// regexp returns the compiled pattern, nil if it is invalid.
+func (v *verifier) regexp(pattern string) *regexp.Regexp {
+	re, ok := v.patterns[pattern]
+	if !ok {
+		re, _ = regexp.Compile(pattern)
+		v.patterns[pattern] = re
+	}
+	return re
+}
+
+// patternSchemas returns the patternProperties schemas of s whose pattern
+// the property name matches, in pattern order.
+func (v *verifier) patternSchemas(s map[string]interface{}, name string) []interface{} {
+	patterns, ok := s["patternProperties"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(patterns))
+	for pattern := range patterns {
+		keys = append(keys, pattern)
+	}
+	sort.Strings(keys)
+	var schemas []interface{}
+	for _, pattern := range keys {
+		if re := v.regexp(pattern); re != nil && re.MatchString(name) {
+			schemas = append(schemas, patterns[pattern])
+		}
+	}
+	return schemas
+}
+
+func (v *verifier) checkNumber(s map[string]interface{}, value float64, pointer string) {
+	if min, ok := s["minimum"].(float64); ok {
+		if value < min || value == min && s["exclusiveMinimum"] == true {
+			v.mismatch(pointer, "%v is below the minimum %v", value, min)
+		}
+	}
+	if max, ok := s["maximum"].(float64); ok {
+		if value > max || value == max && s["exclusiveMaximum"] == true {
+			v.mismatch(pointer, "%v is above the maximum %v", value, max)
+		}
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && value <= min {
+		v.mismatch(pointer, "%v is not above the exclusive minimum %v", value, min)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && value >= max {
+		v.mismatch(pointer, "%v is not below the exclusive maximum %v", value, max)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		if q := value / m; q != math.Trunc(q) {
+			v.mismatch(pointer, "%v is not a multiple of %v", value, m)
+		}
+	}
+}
+
+// schemaTypes returns the types of a type keyword, a name or an array of
+// names.
+func schemaTypes(keyword interface{}) []string {
+	switch keyword := keyword.(type) {
+	case string:
+		return []string{keyword}
+	case []interface{}:
+		var types []string
+		for _, t := range keyword {
+			if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasJSONType(types []string, value interface{}) bool {
+	actual := jsonTypeOf(value)
+	for _, t := range types {
+		if t == actual || t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeOf returns the JSON type of a decoded value, integer for numbers
+// without a fractional part.
+func jsonTypeOf(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if value == math.Trunc(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compactJSON(value interface{}) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(b) > 40 {
+		return string(b[:37]) + "..."
+	}
+	return string(b)
+}
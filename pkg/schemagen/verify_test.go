@@ -0,0 +1,44 @@
+package schemagen
+
+import (
+	"reflect"
+	"testing"
+)
+
+type verifyMaps struct {
+	M map[string]int `json:"m"`
+}
+
+type verifyBounds struct {
+	N uint8 `json:"n"`
+}
+
+func TestVerifyMapValues(t *testing.T) {
+	for _, draft := range []Draft{Draft04, Draft07, Draft2020} {
+		schema, err := GenerateSchemaWithOptions(reflect.TypeOf(verifyMaps{}), Options{Draft: draft})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(schema, map[string]interface{}{"m": map[string]interface{}{"a": 1}}); err != nil {
+			t.Errorf("draft %d: a valid map is rejected: %v", draft, err)
+		}
+		if err := Verify(schema, map[string]interface{}{"m": map[string]interface{}{"a": "notanint"}}); err == nil {
+			t.Errorf("draft %d: a map of strings is accepted as a map of integers", draft)
+		}
+	}
+}
+
+func TestVerifyDrafts(t *testing.T) {
+	for _, draft := range []Draft{Draft04, Draft07, Draft2020} {
+		schema, err := GenerateSchemaWithOptions(reflect.TypeOf(verifyBounds{}), Options{Draft: draft})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Verify(schema, verifyBounds{N: 255}); err != nil {
+			t.Errorf("draft %d: a valid sample is rejected: %v", draft, err)
+		}
+		if err := Verify(schema, map[string]interface{}{"n": -1}); err == nil {
+			t.Errorf("draft %d: a negative uint8 is accepted", draft)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// OutputFormat is the serialization of written schemas.
+type OutputFormat int
+
+const (
+	// JSONFormat writes schemas as JSON documents.
+	JSONFormat OutputFormat = iota
+	// YAMLFormat writes schemas as YAML documents, for CRD manifests and
+	// GitOps pipelines.
+	YAMLFormat
+)
+
+// ParseOutputFormat parses the name of an output format: "json" or "yaml".
+// An empty name is JSONFormat.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch s {
+	case "", "json":
+		return JSONFormat, nil
+	case "yaml", "yml":
+		return YAMLFormat, nil
+	}
+	return 0, fmt.Errorf("Unknown output format %q.", s)
+}
+
+// YAMLEmitter writes the schema as a YAML document, keeping the order of
+// the keys of its JSON serialization.
+type YAMLEmitter struct{}
+
+func (e YAMLEmitter) Emit(schema *JSONSchema, w io.Writer) error {
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	if b, err = JSONToYAML(b); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// JSONToYAML converts a JSON document to block-style YAML. Unlike
+// unmarshalling into maps, it keeps the order of the keys of objects, so
+// properties are listed in the order of the fields of their Go type.
+func JSONToYAML(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	writeYAML(&b, v, 0, false)
+	return b.Bytes(), nil
+}
+
+// yamlMap is a JSON object whose keys keep their order.
+type yamlMap []yamlEntry
+
+type yamlEntry struct {
+	key   string
+	value interface{}
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := yamlMap{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, yamlEntry{key: key.(string), value: value})
+		}
+		_, err = dec.Token()
+		return m, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	}
+	return tok, nil
+}
+
+// writeYAML writes v indented by indent spaces. Inline is set when the
+// first line of a collection follows the "- " of a sequence entry.
+func writeYAML(b *bytes.Buffer, v interface{}, indent int, inline bool) {
+	pad := strings.Repeat(" ", indent)
+	switch v := v.(type) {
+	case yamlMap:
+		for i, e := range v {
+			if i > 0 || !inline {
+				b.WriteString(pad)
+			}
+			b.WriteString(yamlScalar(e.key) + ":")
+			writeYAMLValue(b, e.value, indent+2)
+		}
+	case []interface{}:
+		for i, e := range v {
+			if i > 0 || !inline {
+				b.WriteString(pad)
+			}
+			b.WriteString("-")
+			if isYAMLCollection(e) {
+				b.WriteString(" ")
+				writeYAML(b, e, indent+2, true)
+			} else {
+				b.WriteString(" " + yamlScalar(e) + "\n")
+			}
+		}
+	default:
+		b.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a key, on the same line if it is a
+// scalar or an empty collection.
+func writeYAMLValue(b *bytes.Buffer, v interface{}, indent int) {
+	if isYAMLCollection(v) {
+		b.WriteString("\n")
+		writeYAML(b, v, indent, false)
+		return
+	}
+	b.WriteString(" " + yamlScalar(v) + "\n")
+}
+
+// isYAMLCollection tells whether v is written as a block collection.
+func isYAMLCollection(v interface{}) bool {
+	switch v := v.(type) {
+	case yamlMap:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+	return false
+}
+
+// yamlPlain matches the strings written without quotes, unless they are
+// one of yamlReserved.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$./-]*$`)
+
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true,
+	"off": true, "y": true, "n": true, "null": true,
+}
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprint(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlPlain.MatchString(v) && !yamlReserved[strings.ToLower(v)] {
+			return v
+		}
+		// JSON strings are valid double-quoted YAML scalars.
+		var b bytes.Buffer
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.Encode(v)
+		return strings.TrimSuffix(b.String(), "\n")
+	case yamlMap:
+		return "{}"
+	case []interface{}:
+		return "[]"
+	}
+	return fmt.Sprint(v)
+}
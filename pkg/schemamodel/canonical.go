@@ -0,0 +1,95 @@
+package schemamodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Canonicalize re-encodes a JSON document with sorted keys and a fixed
+// indentation, so that documents describing the same schema compare equal
+// byte for byte.
+func Canonicalize(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// CompareJSON compares two JSON documents and returns a readable list of
+// their differences, one per JSON pointer that was added, removed or
+// changed. The list is empty if the documents are equivalent.
+func CompareJSON(expected, actual []byte) ([]string, error) {
+	var a, b interface{}
+	if err := json.Unmarshal(expected, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(actual, &b); err != nil {
+		return nil, err
+	}
+	return DiffValues(a, b), nil
+}
+
+// DiffValues compares two decoded JSON values, as unmarshalled into
+// interface{}, like CompareJSON. Pointers are relative to the values.
+func DiffValues(a, b interface{}) []string {
+	var diffs []string
+	diffJSON("", a, b, &diffs)
+	return diffs
+}
+
+func diffJSON(path string, a, b interface{}, diffs *[]string) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	ma, aok := a.(map[string]interface{})
+	mb, bok := b.(map[string]interface{})
+	if aok && bok {
+		keys := map[string]bool{}
+		for k := range ma {
+			keys[k] = true
+		}
+		for k := range mb {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			p := path + "/" + EscapePointer(k)
+			va, ina := ma[k]
+			vb, inb := mb[k]
+			switch {
+			case !inb:
+				*diffs = append(*diffs, fmt.Sprintf("- %s: %s", p, compactJSON(va)))
+			case !ina:
+				*diffs = append(*diffs, fmt.Sprintf("+ %s: %s", p, compactJSON(vb)))
+			default:
+				diffJSON(p, va, vb, diffs)
+			}
+		}
+		return
+	}
+	la, aok := a.([]interface{})
+	lb, bok := b.([]interface{})
+	if aok && bok && len(la) == len(lb) {
+		for i := range la {
+			diffJSON(fmt.Sprintf("%s/%d", path, i), la[i], lb[i], diffs)
+		}
+		return
+	}
+	*diffs = append(*diffs, fmt.Sprintf("~ %s: %s -> %s", path, compactJSON(a), compactJSON(b)))
+}
+
+func compactJSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,11 @@
+// Package schemamodel is the model of the JSON Schema documents written by
+// package schemagen: the schema and descriptor types, their serialization
+// in the supported drafts, JSON pointer resolution and the canonical form
+// used to compare documents. It depends on the standard library only, so
+// that tools consuming generated schemas, such as validators or
+// documentation generators, can use it without the reflection generator.
+//
+// The package follows semantic versioning: exported identifiers are
+// neither removed nor changed incompatibly within a major version, and new
+// keywords are added as new descriptors or optional fields.
+package schemamodel
@@ -0,0 +1,160 @@
+package schemamodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Draft is a version of JSON Schema a schema can be serialized in.
+type Draft int
+
+const (
+	// Draft04 is the draft schemas are modelled in, expected by
+	// jsonschema2pojo.
+	Draft04 Draft = iota
+	// Draft07 is draft-07, expected by Helm and most validators.
+	Draft07
+	// Draft2020 is draft 2020-12, expected by modern validators.
+	Draft2020
+)
+
+const (
+	draft07URI   = "http://json-schema.org/draft-07/schema#"
+	draft2020URI = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// ParseDraft parses the name of a draft: "draft-04", "draft-07" or
+// "2020-12". An empty name is Draft04.
+func ParseDraft(s string) (Draft, error) {
+	switch s {
+	case "", "draft-04", "04", "4":
+		return Draft04, nil
+	case "draft-07", "07", "7":
+		return Draft07, nil
+	case "2020-12":
+		return Draft2020, nil
+	}
+	return 0, fmt.Errorf("Unknown draft %q.", s)
+}
+
+// URI returns the $schema of the documents of the draft.
+func (d Draft) URI() string {
+	switch d {
+	case Draft07:
+		return draft07URI
+	case Draft2020:
+		return draft2020URI
+	}
+	return DefaultSchemaURI
+}
+
+// convertDraft converts a serialized draft-04 schema to draft.
+func convertDraft(b []byte, draft Draft) ([]byte, error) {
+	// Numbers are kept as written, e.g. large integer defaults.
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	doc = toDraft(doc, draft)
+	if id, ok := doc["id"]; ok {
+		delete(doc, "id")
+		doc["$id"] = id
+	}
+	return json.Marshal(doc)
+}
+
+// toDraft converts a serialized draft-04 schema to draft-07 or 2020-12:
+// exclusiveMinimum and exclusiveMaximum carry the bound instead of
+// qualifying minimum and maximum and, in 2020-12, definitions become $defs
+// and references, including the ones of discriminator mappings, are
+// adjusted accordingly. Only
+// keywords are renamed, never property names or values.
+func toDraft(schema map[string]interface{}, draft Draft) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		switch k {
+		case "definitions", "$defs", "properties", "patternProperties":
+			names, _ := v.(map[string]interface{})
+			converted := make(map[string]interface{}, len(names))
+			for name, s := range names {
+				converted[name] = draftValue(s, draft)
+			}
+			if k == "definitions" && draft == Draft2020 {
+				k = "$defs"
+			}
+			result[k] = converted
+		case "components":
+			components, _ := v.(map[string]interface{})
+			schemas, _ := components["schemas"].(map[string]interface{})
+			converted := make(map[string]interface{}, len(schemas))
+			for name, s := range schemas {
+				converted[name] = draftValue(s, draft)
+			}
+			result[k] = map[string]interface{}{"schemas": converted}
+		case "items", "additionalProperties", "unevaluatedProperties", "propertyNames", "not":
+			result[k] = draftValue(v, draft)
+		case "allOf", "anyOf", "oneOf":
+			schemas, _ := v.([]interface{})
+			converted := make([]interface{}, len(schemas))
+			for i, s := range schemas {
+				converted[i] = draftValue(s, draft)
+			}
+			result[k] = converted
+		case "$ref":
+			ref, _ := v.(string)
+			result[k] = draftRef(ref, draft)
+		case "discriminator":
+			discriminator, _ := v.(map[string]interface{})
+			mapping, ok := discriminator["mapping"].(map[string]interface{})
+			if !ok {
+				result[k] = v
+				continue
+			}
+			converted := make(map[string]interface{}, len(discriminator))
+			for dk, dv := range discriminator {
+				converted[dk] = dv
+			}
+			refs := make(map[string]interface{}, len(mapping))
+			for value, ref := range mapping {
+				s, _ := ref.(string)
+				refs[value] = draftRef(s, draft)
+			}
+			converted["mapping"] = refs
+			result[k] = converted
+		case "exclusiveMinimum", "exclusiveMaximum":
+			// Converted along their bound below.
+		default:
+			result[k] = v
+		}
+	}
+	for bound, exclusive := range map[string]string{"minimum": "exclusiveMinimum", "maximum": "exclusiveMaximum"} {
+		if b, _ := schema[exclusive].(bool); b {
+			if v, ok := result[bound]; ok {
+				delete(result, bound)
+				result[exclusive] = v
+			}
+		}
+	}
+	return result
+}
+
+// draftRef returns ref adjusted to the definitions of draft.
+func draftRef(ref string, draft Draft) string {
+	if i := strings.Index(ref, "#/definitions/"); i >= 0 && draft == Draft2020 {
+		ref = ref[:i] + "#/$defs/" + ref[i+len("#/definitions/"):]
+	}
+	return ref
+}
+
+// draftValue converts v if it is a schema, leaving boolean schemas
+// untouched.
+func draftValue(v interface{}, draft Draft) interface{} {
+	if s, ok := v.(map[string]interface{}); ok {
+		return toDraft(s, draft)
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package schemamodel
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefinitionsPrefix is the prefix of the references to the definitions of
+// the same document.
+const DefinitionsPrefix = "#/definitions/"
+
+// EscapePointer escapes a property or definition name as a JSON pointer
+// token, see RFC 6901.
+func EscapePointer(s string) string {
+	s = strings.Replace(s, "~", "~0", -1)
+	return strings.Replace(s, "/", "~1", -1)
+}
+
+// UnescapePointer returns the name escaped as the JSON pointer token s.
+func UnescapePointer(s string) string {
+	s = strings.Replace(s, "~1", "/", -1)
+	return strings.Replace(s, "~0", "~", -1)
+}
+
+// LocalDefinition returns the name of the definition of the same document
+// ref refers to, e.g. os_build_Build for #/definitions/os_build_Build.
+func LocalDefinition(ref string) (string, bool) {
+	if !strings.HasPrefix(ref, DefinitionsPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(ref, DefinitionsPrefix), true
+}
+
+// Resolve returns the descriptor addressed by the JSON pointer, with or
+// without a leading #, e.g. #/definitions/os_build_Build/properties/spec.
+// Pointers go through properties, items, additionalProperties, the
+// subschemas of allOf, anyOf and oneOf and nested definitions, in the
+// keywords they are serialized with. References met on the way are not
+// followed.
+func (s *JSONSchema) Resolve(pointer string) (JSONPropertyDescriptor, bool) {
+	pointer = strings.TrimPrefix(pointer, "#")
+	if len(pointer) == 0 {
+		return s.root(), true
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return JSONPropertyDescriptor{}, false
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = UnescapePointer(t)
+	}
+	if len(tokens) >= 2 && tokens[0] == "definitions" {
+		def, ok := s.Definitions[tokens[1]]
+		if !ok {
+			return JSONPropertyDescriptor{}, false
+		}
+		return resolve(def, tokens[2:])
+	}
+	return resolve(s.root(), tokens)
+}
+
+// root describes the root object of s.
+func (s *JSONSchema) root() JSONPropertyDescriptor {
+	desc := s.JSONDescriptor
+	return JSONPropertyDescriptor{
+		JSONDescriptor:       &desc,
+		JSONObjectDescriptor: s.JSONObjectDescriptor,
+	}
+}
+
+// resolve returns the descriptor addressed by the pointer tokens, relative
+// to p.
+func resolve(p JSONPropertyDescriptor, tokens []string) (JSONPropertyDescriptor, bool) {
+	for len(tokens) > 0 {
+		var ok bool
+		switch tokens[0] {
+		case "properties", "definitions":
+			if len(tokens) < 2 {
+				return JSONPropertyDescriptor{}, false
+			}
+			var names map[string]JSONPropertyDescriptor
+			if tokens[0] == "properties" && p.JSONObjectDescriptor != nil {
+				names = p.Properties
+			} else if tokens[0] == "definitions" && p.JSONDefinitionsDescriptor != nil {
+				names = p.JSONDefinitionsDescriptor.Definitions
+			}
+			p, ok = names[tokens[1]]
+			tokens = tokens[2:]
+		case "items":
+			if p.JSONArrayDescriptor != nil {
+				p, ok = p.Items, true
+			}
+			tokens = tokens[1:]
+		case "additionalProperties", "additionalProperty":
+			if p.JSONMapDescriptor != nil {
+				p, ok = p.MapValueType, true
+			} else if p.JSONObjectDescriptor != nil {
+				p, ok = p.AdditionalProperties.(JSONPropertyDescriptor)
+			}
+			tokens = tokens[1:]
+		case "allOf", "anyOf", "oneOf":
+			if len(tokens) < 2 || p.JSONCombinatorDescriptor == nil {
+				return JSONPropertyDescriptor{}, false
+			}
+			alts := map[string][]JSONPropertyDescriptor{"allOf": p.AllOf, "anyOf": p.AnyOf, "oneOf": p.OneOf}[tokens[0]]
+			i, err := strconv.Atoi(tokens[1])
+			if ok = err == nil && i >= 0 && i < len(alts); ok {
+				p = alts[i]
+			}
+			tokens = tokens[2:]
+		}
+		if !ok {
+			return JSONPropertyDescriptor{}, false
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,340 @@
+package schemamodel
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// DefaultSchemaURI is the $schema of draft-04 schemas.
+const DefaultSchemaURI = "http://json-schema.org/schema#"
+
+// JSONSchema is a schema document: the root object and the definitions it
+// refers to.
+type JSONSchema struct {
+	ID          string           `json:"id,omitempty"`
+	Schema      string           `json:"$schema"`
+	Description string           `json:"description,omitempty"`
+	License     string           `json:"x-license,omitempty"`
+	Generated   *GenerationStamp `json:"x-generated,omitempty"`
+	// GroupVersionKinds lists the resource types of the root.
+	GroupVersionKinds []GroupVersionKind                `json:"x-kubernetes-group-version-kind,omitempty"`
+	Definitions       map[string]JSONPropertyDescriptor `json:"definitions"`
+	Components        *OpenAPIComponents                `json:"components,omitempty"`
+	JSONDescriptor
+	*JSONObjectDescriptor
+
+	// Draft is the version of JSON Schema the schema is serialized in.
+	Draft Draft `json:"-"`
+	// Warnings lists the problems found while generating the schema.
+	Warnings []string `json:"-"`
+}
+
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	type plain JSONSchema
+	b, err := json.Marshal(plain(s))
+	if err != nil || s.Draft == Draft04 {
+		return b, err
+	}
+	return convertDraft(b, s.Draft)
+}
+
+type JSONDescriptor struct {
+	Type        string      `json:"type,omitempty"`
+	Title       string      `json:"title,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Format      string      `json:"format,omitempty"`
+	Pattern     string      `json:"pattern,omitempty"`
+	MinLength   *int        `json:"minLength,omitempty"`
+	MaxLength   *int        `json:"maxLength,omitempty"`
+	Minimum     *float64    `json:"minimum,omitempty"`
+	Maximum     *float64    `json:"maximum,omitempty"`
+	// ExclusiveMinimum and ExclusiveMaximum exclude Minimum and Maximum
+	// from the valid values, as in draft-04. Later drafts carry the bound
+	// in exclusiveMinimum and exclusiveMaximum instead.
+	ExclusiveMinimum bool          `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum bool          `json:"exclusiveMaximum,omitempty"`
+	Enum             []interface{} `json:"enum,omitempty"`
+	Examples         []interface{} `json:"examples,omitempty"`
+	Deprecated       bool          `json:"deprecated,omitempty"`
+	// AllowNull adds "null" to Type, as in "type": ["object", "null"].
+	AllowNull bool `json:"-"`
+}
+
+type JSONObjectDescriptor struct {
+	Properties map[string]JSONPropertyDescriptor `json:"properties,omitempty"`
+	Required   []string                          `json:"required,omitempty"`
+	// AdditionalProperties is either a bool or the JSONPropertyDescriptor
+	// of the values of the properties not listed in Properties.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+	// UnevaluatedProperties is false to reject the properties evaluated
+	// neither by the object nor by its subschemas.
+	UnevaluatedProperties interface{} `json:"unevaluatedProperties,omitempty"`
+}
+
+type JSONArrayDescriptor struct {
+	Items    JSONPropertyDescriptor `json:"items"`
+	MinItems *int                   `json:"minItems,omitempty"`
+	MaxItems *int                   `json:"maxItems,omitempty"`
+}
+
+type JSONReferenceDescriptor struct {
+	Reference string `json:"$ref"`
+}
+
+type JavaTypeDescriptor struct {
+	JavaType string `json:"javaType"`
+
+	// JavaInterfaces, GenerateBuilders and JavaSerializable are
+	// jsonschema2pojo hints of the class generated for a definition: the
+	// interfaces it implements, whether builders are generated for it and
+	// whether it is serializable.
+	JavaInterfaces   []string `json:"javaInterfaces,omitempty"`
+	GenerateBuilders bool     `json:"generateBuilders,omitempty"`
+	JavaSerializable bool     `json:"javaSerializable,omitempty"`
+}
+
+type JavaAnnotationsDescriptor struct {
+	CustomAnnotations []string `json:"customAnnotations"`
+}
+
+type JavaHintsDescriptor struct {
+	ExistingJavaType string   `json:"existingJavaType,omitempty"`
+	JavaEnumNames    []string `json:"javaEnumNames,omitempty"`
+}
+
+type JavaNameDescriptor struct {
+	JavaName string `json:"javaName,omitempty"`
+}
+
+type JSONPropertyDescriptor struct {
+	*JSONDescriptor
+	*JSONReferenceDescriptor
+	*JSONObjectDescriptor
+	*JSONArrayDescriptor
+	*JSONMapDescriptor
+	*JSONCombinatorDescriptor
+	*JSONDefinitionsDescriptor
+	*JavaTypeDescriptor
+	*JavaAnnotationsDescriptor
+	*JavaHintsDescriptor
+	*JavaNameDescriptor
+	*FormatHintDescriptor
+	*NullableDescriptor
+	*EmbeddedResourceDescriptor
+	*AbstractDescriptor
+	*GroupVersionKindDescriptor
+	*GoPackageDescriptor
+	*ProtobufDescriptor
+	*DeprecatedValuesDescriptor
+	*DebugDescriptor
+
+	// Extensions holds additional vendor extension keywords (x-*), which
+	// are serialized inline after the other keywords.
+	Extensions map[string]interface{} `json:"-"`
+
+	// Boolean, if set, makes the descriptor the boolean schema true, which
+	// accepts any value, or false, which accepts none. The other keywords
+	// are then left out. Boolean schemas need draft-06 or later.
+	Boolean *bool `json:"-"`
+}
+
+func (p JSONPropertyDescriptor) MarshalJSON() ([]byte, error) {
+	if p.Boolean != nil {
+		return json.Marshal(*p.Boolean)
+	}
+	type plain JSONPropertyDescriptor
+	var types []byte
+	if p.JSONDescriptor != nil && p.AllowNull && len(p.Type) > 0 {
+		types, _ = json.Marshal([]string{p.Type, "null"})
+		desc := *p.JSONDescriptor
+		desc.Type = ""
+		p.JSONDescriptor = &desc
+	}
+	if p.JSONMapDescriptor != nil {
+		// The values of maps are the additional properties of an object,
+		// none if all keys must match a pattern.
+		obj := JSONObjectDescriptor{}
+		if p.JSONObjectDescriptor != nil {
+			obj = *p.JSONObjectDescriptor
+		}
+		obj.AdditionalProperties = p.MapValueType
+		if len(p.PatternProperties) > 0 {
+			obj.AdditionalProperties = false
+		}
+		p.JSONObjectDescriptor = &obj
+	}
+	b, err := json.Marshal(plain(p))
+	if err != nil {
+		return nil, err
+	}
+	if types != nil {
+		// The type keyword comes first, as when it is a single name.
+		head := append([]byte(`{"type":`), types...)
+		if len(b) > 2 {
+			head = append(head, ',')
+		}
+		b = append(head, b[1:]...)
+	}
+	if len(p.Extensions) == 0 {
+		return b, nil
+	}
+	ext, err := json.Marshal(p.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 2 {
+		return ext, nil
+	}
+	return append(append(b[:len(b)-1], ','), ext[1:]...), nil
+}
+
+// UnmarshalJSON reads a descriptor serialized by MarshalJSON: objects
+// without properties whose additionalProperties is a schema are maps, of
+// values described by it, and boolean schemas set Boolean.
+func (p *JSONPropertyDescriptor) UnmarshalJSON(b []byte) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && (trimmed[0] == 't' || trimmed[0] == 'f') {
+		var boolean bool
+		if err := json.Unmarshal(trimmed, &boolean); err != nil {
+			return err
+		}
+		*p = JSONPropertyDescriptor{Boolean: &boolean}
+		return nil
+	}
+	type plain JSONPropertyDescriptor
+	var q plain
+	if err := json.Unmarshal(b, &q); err != nil {
+		return err
+	}
+	*p = JSONPropertyDescriptor(q)
+	obj := p.JSONObjectDescriptor
+	if obj == nil || len(obj.Properties) > 0 {
+		return nil
+	}
+	values, isSchema := obj.AdditionalProperties.(map[string]interface{})
+	if !isSchema && !(obj.AdditionalProperties == false && p.JSONMapDescriptor != nil) {
+		return nil
+	}
+	m := JSONMapDescriptor{}
+	if p.JSONMapDescriptor != nil {
+		m = *p.JSONMapDescriptor
+	}
+	if isSchema {
+		raw, _ := json.Marshal(values)
+		if err := json.Unmarshal(raw, &m.MapValueType); err != nil {
+			return err
+		}
+	}
+	p.JSONMapDescriptor = &m
+	rest := *obj
+	rest.AdditionalProperties = nil
+	p.JSONObjectDescriptor = &rest
+	if reflect.DeepEqual(rest, JSONObjectDescriptor{}) {
+		p.JSONObjectDescriptor = nil
+	}
+	return nil
+}
+
+type JSONMapDescriptor struct {
+	// MapValueType describes the values of the map, serialized as the
+	// additionalProperties of the object.
+	MapValueType  JSONPropertyDescriptor  `json:"-"`
+	PropertyNames *JSONPropertyDescriptor `json:"propertyNames,omitempty"`
+	MinProperties *int                    `json:"minProperties,omitempty"`
+	MaxProperties *int                    `json:"maxProperties,omitempty"`
+	// PatternProperties describes the values of the keys matching each
+	// pattern, for validators without propertyNames such as draft-04
+	// ones. Keys matching none of them are rejected: the descriptor is
+	// serialized with additionalProperties: false.
+	PatternProperties map[string]JSONPropertyDescriptor `json:"patternProperties,omitempty"`
+}
+
+type JSONCombinatorDescriptor struct {
+	AllOf         []JSONPropertyDescriptor `json:"allOf,omitempty"`
+	AnyOf         []JSONPropertyDescriptor `json:"anyOf,omitempty"`
+	OneOf         []JSONPropertyDescriptor `json:"oneOf,omitempty"`
+	Discriminator *Discriminator           `json:"discriminator,omitempty"`
+}
+
+// Discriminator names the property telling which alternative of a oneOf a
+// value is, mapping its values to the references of the alternatives, as
+// in OpenAPI 3.
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
+type JSONDefinitionsDescriptor struct {
+	Definitions map[string]JSONPropertyDescriptor `json:"definitions,omitempty"`
+}
+
+type FormatHintDescriptor struct {
+	XFormat string `json:"x-format"`
+}
+
+type NullableDescriptor struct {
+	XNullable bool `json:"x-nullable,omitempty"`
+	Nullable  bool `json:"nullable,omitempty"`
+}
+
+type EmbeddedResourceDescriptor struct {
+	EmbeddedResource bool `json:"x-kubernetes-embedded-resource"`
+}
+
+// AbstractDescriptor marks the definition of a base type that is never
+// instantiated directly.
+type AbstractDescriptor struct {
+	Abstract bool `json:"x-abstract"`
+}
+
+// GoPackageDescriptor records the import path of the Go package declaring
+// the type of a definition.
+type GoPackageDescriptor struct {
+	GoPackage string `json:"x-go-package"`
+}
+
+// ProtobufDescriptor records the protobuf field number and name of a
+// property.
+type ProtobufDescriptor struct {
+	FieldNumber int    `json:"x-proto-field-number"`
+	Name        string `json:"x-proto-name,omitempty"`
+}
+
+// DeprecatedValuesDescriptor lists the values of an enum that are still
+// accepted, so that clients parse old data, but deprecated.
+type DeprecatedValuesDescriptor struct {
+	DeprecatedValues []string `json:"x-deprecated-values"`
+}
+
+// GroupVersionKind identifies a Kubernetes resource type, for the tools
+// looking schemas up by resource, such as kubectl explain.
+type GroupVersionKind struct {
+	Group   string `json:"group" yaml:"group"`
+	Version string `json:"version" yaml:"version"`
+	Kind    string `json:"kind" yaml:"kind"`
+}
+
+// GroupVersionKindDescriptor lists the resource types a definition
+// describes.
+type GroupVersionKindDescriptor struct {
+	GroupVersionKinds []GroupVersionKind `json:"x-kubernetes-group-version-kind"`
+}
+
+// GenerationStamp records when, where and with which Go version a schema
+// was generated.
+type GenerationStamp struct {
+	Time      string `json:"time"`
+	Host      string `json:"host,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// OpenAPIComponents holds the definitions of a schema under the OpenAPI 3
+// components keyword.
+type OpenAPIComponents struct {
+	Schemas map[string]JSONPropertyDescriptor `json:"schemas"`
+}
+
+type DebugDescriptor struct {
+	Debug []string `json:"x-debug"`
+}
@@ -0,0 +1,52 @@
+package schemamodel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMapValuesAreAdditionalProperties(t *testing.T) {
+	pattern := "^[a-z]+$"
+	tests := []struct {
+		name string
+		desc JSONPropertyDescriptor
+		want string
+	}{
+		{
+			name: "map",
+			desc: JSONPropertyDescriptor{
+				JSONDescriptor:    &JSONDescriptor{Type: "object"},
+				JSONMapDescriptor: &JSONMapDescriptor{MapValueType: JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: "integer"}}},
+			},
+			want: `{"type":"object","additionalProperties":{"type":"integer"}}`,
+		},
+		{
+			name: "pattern keys",
+			desc: JSONPropertyDescriptor{
+				JSONDescriptor: &JSONDescriptor{Type: "object"},
+				JSONMapDescriptor: &JSONMapDescriptor{
+					MapValueType:      JSONPropertyDescriptor{JSONDescriptor: &JSONDescriptor{Type: "string"}},
+					PatternProperties: map[string]JSONPropertyDescriptor{pattern: {JSONDescriptor: &JSONDescriptor{Type: "string"}}},
+				},
+			},
+			want: `{"type":"object","additionalProperties":false,"patternProperties":{"^[a-z]+$":{"type":"string"}}}`,
+		},
+	}
+	for _, test := range tests {
+		b, err := json.Marshal(test.desc)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if diffs, err := CompareJSON([]byte(test.want), b); err != nil || len(diffs) > 0 {
+			t.Errorf("%s: got %s, want %s", test.name, b, test.want)
+		}
+		var read JSONPropertyDescriptor
+		if err := json.Unmarshal(b, &read); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if test.name == "map" && !reflect.DeepEqual(read, test.desc) {
+			t.Errorf("%s: read %#v back, want %#v", test.name, read, test.desc)
+		}
+	}
+}